@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/DalyChouikh/internal/batch"
+	"github.com/DalyChouikh/internal/config"
+)
+
+// handleBatch generates one QR code per row of a CSV or JSON Lines manifest.
+func handleBatch(args []string) error {
+	cfg, settings, err := config.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	outputDir := settings.OutputDir
+	if outputDir == "" {
+		outputDir = "."
+	}
+
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	pattern := fs.String("o", batch.DefaultPattern, "output filename pattern: {row}, {date}, {type}, {slug}, {hash}, {n} or any {column}")
+	dir := fs.String("dir", outputDir, "output directory")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of codes generated in parallel")
+	failFast := fs.Bool("fail-fast", false, "stop after the first failed row")
+	report := fs.String("report", "", "write a JSON report of per-row results, including generated secrets, to this file (mode 0600)")
+	showSecrets := fs.Bool("show-secrets", false, "print generated authenticator secrets to stdout")
+	fs.String("format", string(cfg.Format), "default output format: png, jpg, gif, svg, txt, html, datauri or md")
+	fs.String("embed", string(cfg.EmbedFormat()), "image format inside html, datauri and md output: png, jpg, gif or svg")
+	fs.Int("size", cfg.Size, "default size in pixels (64-4096)")
+	fs.Int("dpi", cfg.DPI, "print resolution stored in PNG/JPEG metadata (72-2400)")
+	fs.String("fg", config.ColorToHex(cfg.Foreground), "default foreground color (hex)")
+	fs.String("bg", config.ColorToHex(cfg.Background), "default background color (hex)")
+	fs.String("ec", string(cfg.Level), "default error correction level: L, M, Q or H")
+	// Nobody can answer a prompt mid-batch, so a prompt setting means increment.
+	collisionDefault := cfg.OnCollision
+	if collisionDefault == config.CollisionPrompt {
+		collisionDefault = config.CollisionIncrement
+	}
+	onCollision := fs.String("on-collision", string(collisionDefault),
+		"if an output file exists: overwrite, increment or fail (prompt fails in batch mode)")
+	preset := fs.String("preset", "", "apply a saved style preset as the default (flags override it)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: qrgen batch [flags] <manifest.csv|manifest.jsonl>")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Each row becomes one QR code. Columns: content, type (url, text, wifi,")
+		fmt.Fprintln(os.Stderr, "vcard, email, sms, event, geo, sepa, swissqr, merchant, crypto, otp), template fields (ssid, password, summary, ...),")
+		fmt.Fprintln(os.Stderr, "and per-row overrides (format, size, dpi, alt, fg, bg, ec, renderer, invert, output).")
+		fmt.Fprintln(os.Stderr, "")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one manifest file")
+	}
+
+	if *preset != "" {
+		if err := applyPreset(cfg, *preset); err != nil {
+			return err
+		}
+	}
+	if err := applyStyleFlags(fs, cfg); err != nil {
+		return err
+	}
+
+	if cfg.OnCollision, err = config.ParseCollisionPolicy(*onCollision); err != nil {
+		return err
+	}
+
+	rows, err := batch.ReadManifest(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	results, err := batch.Run(context.Background(), rows, batch.Options{
+		Defaults:  *cfg,
+		Pattern:   *pattern,
+		OutputDir: config.ExpandHome(*dir),
+		Workers:   *workers,
+		FailFast:  *failFast,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		switch r.Status {
+		case batch.StatusOK:
+			fmt.Printf("✓ row %-4d %s\n", r.Row, r.Output)
+			switch {
+			case r.Secret == "":
+			case *showSecrets:
+				fmt.Printf("           secret: %s\n", r.Secret)
+			case *report != "":
+				fmt.Printf("           secret: written to %s\n", *report)
+			default:
+				fmt.Println("           secret: hidden (pass -show-secrets or -report to keep it)")
+			}
+		case batch.StatusFailed:
+			fmt.Printf("✗ row %-4d %s\n", r.Row, r.Error)
+		case batch.StatusSkipped:
+			fmt.Printf("- row %-4d skipped\n", r.Row)
+		}
+	}
+
+	ok, failed, skipped := batch.Summary(results)
+	fmt.Printf("\n%d generated, %d failed, %d skipped\n", ok, failed, skipped)
+
+	if *report != "" {
+		if err := writeReport(*report, results); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", failed, len(results))
+	}
+	return nil
+}
+
+// writeReport writes the per-row results as JSON. The report can hold
+// generated secrets, so it is only readable by the current user, even when
+// it replaces an existing file.
+func writeReport(path string, results []batch.Result) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to set report permissions: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DalyChouikh/internal/config"
+)
+
+// handleConfig implements `qrgen config get|set|list|path`.
+func handleConfig(args []string) error {
+	if len(args) == 0 {
+		printConfigUsage()
+		return fmt.Errorf("missing config subcommand")
+	}
+
+	path, err := config.UserConfigPath()
+	if err != nil {
+		return fmt.Errorf("failed to determine config path: %w", err)
+	}
+
+	switch args[0] {
+	case "path":
+		fmt.Println(path)
+		return nil
+
+	case "list":
+		settings, err := config.ReadUserSettings(path)
+		if err != nil {
+			return err
+		}
+		effective := *settings
+		if err := effective.ApplyEnv(); err != nil {
+			return err
+		}
+		for _, key := range config.SettingKeys() {
+			value, _ := effective.Get(key)
+			saved, _ := settings.Get(key)
+			note := ""
+			if value != saved {
+				note = "  (from QRGEN_" + strings.ToUpper(key) + ")"
+			}
+			if value == "" {
+				value = "(default)"
+			}
+			fmt.Printf("%-18s %s%s\n", key, value, note)
+		}
+		return nil
+
+	case "get":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: qrgen config get <key>")
+		}
+		settings, err := config.ReadUserSettings(path)
+		if err != nil {
+			return err
+		}
+		if err := settings.ApplyEnv(); err != nil {
+			return err
+		}
+		value, err := settings.Get(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+
+	case "set":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: qrgen config set <key> <value>")
+		}
+		settings, err := config.ReadUserSettings(path)
+		if err != nil {
+			return err
+		}
+		if err := settings.Set(args[1], args[2]); err != nil {
+			return err
+		}
+		if err := settings.Save(path); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		fmt.Printf("✓ %s saved to %s\n", args[1], path)
+		return nil
+
+	case "-h", "--help", "help":
+		printConfigUsage()
+		return nil
+	}
+
+	printConfigUsage()
+	return fmt.Errorf("unknown config subcommand: %s", args[0])
+}
+
+func printConfigUsage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  qrgen config list               Show all settings
+  qrgen config get <key>          Print a single setting
+  qrgen config set <key> <value>  Save a setting (empty value clears it)
+  qrgen config path               Print the config file location
+
+Keys: %s
+Environment variables QRGEN_<KEY> (e.g. QRGEN_SIZE) override the file.
+`, strings.Join(config.SettingKeys(), ", "))
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DalyChouikh/internal/config"
+	"github.com/DalyChouikh/internal/generator"
+	"github.com/DalyChouikh/internal/halftone"
+	"github.com/DalyChouikh/internal/history"
+	"github.com/DalyChouikh/internal/presets"
+	"github.com/DalyChouikh/internal/templates"
+)
+
+// handleGenerate creates a QR code non-interactively from command-line flags.
+func handleGenerate(args []string) error {
+	cfg, settings, err := config.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	content := fs.String("content", "", "URL or text to encode")
+	contentFile := fs.String("content-file", "", "read content from a file ('-' for stdin)")
+	typeName := fs.String("type", "", "build the content from a template: wifi, vcard, email, sms, event, geo, sepa, swissqr, merchant, crypto or otp")
+	var fields stringList
+	fs.Var(&fields, "field", "template field as name=value, e.g. iban=DE89370400440532013000 (repeatable)")
+	output := fs.String("o", defaultOutputBase(settings),
+		"output file path or pattern with {date}, {type}, {slug}, {hash}, {n} ('-' for stdout)")
+	onCollision := fs.String("on-collision", string(cfg.OnCollision),
+		"if the output file exists: overwrite, increment, prompt or fail")
+	fs.String("format", string(cfg.Format), "output format: png, jpg, gif, svg, txt, html, datauri or md, or a list such as png,svg")
+	fs.String("embed", string(cfg.EmbedFormat()), "image format inside html, datauri and md output: png, jpg, gif or svg")
+	fs.String("alt", cfg.AltText(), "alt text for html and md output")
+	fs.Int("size", cfg.Size, "size in pixels (64-4096)")
+	fs.String("sizes", "", "several PNG sizes in one run, e.g. 256,1024 (overrides -size)")
+	fs.Int("dpi", cfg.DPI, "print resolution stored in PNG/JPEG metadata (72-2400)")
+	printSize := fs.String("print-size", "", "physical width such as 30mm or 1.5in; sets -size from -dpi (default 300)")
+	scanDistance := fs.String("scan-distance", "",
+		"report the minimum print size for scanning from this distance, e.g. 30cm")
+	fs.String("fg", config.ColorToHex(cfg.Foreground), "foreground color (hex)")
+	fs.String("bg", config.ColorToHex(cfg.Background), "background color (hex)")
+	fs.String("ec", string(cfg.Level), "error correction level: L, M, Q or H")
+	fs.String("renderer", string(cfg.Renderer), "text renderer for txt output: "+joinRenderers())
+	fs.Bool("invert", false, "invert text output for dark terminals")
+	fs.Bool("swiss-cross", false, "overlay the Swiss QR-bill cross (automatic for swissqr content)")
+	fs.String("gradient", "", "end color (hex) of a diagonal gradient starting at -fg")
+	fs.String("module-style", "", "module shape for image output: square, dots or rounded")
+	fs.String("logo", "", "draw a PNG/JPEG/GIF logo in the center of image output (defaults -ec to H)")
+	fs.Int("frame", 0, "width in modules of a border around the quiet zone (0-8)")
+	halftoneImage := fs.String("halftone", "", "blend a background image into PNG/JPEG/GIF output (artistic halftone mode)")
+	noVerify := fs.Bool("no-verify", false, "skip checking that halftone output still decodes")
+	animate := fs.String("animate", "", "animate GIF output: color-cycle, reveal or payloads (implies -format gif)")
+	frames := fs.Int("frames", 0, "frames per animation loop (default 12)")
+	delay := fs.Int("delay", 0, "milliseconds per frame (default 100, or 2000 for payloads)")
+	loop := fs.Int("loop", 0, "times to play the animation (0 loops forever)")
+	colors := fs.String("colors", "", "comma-separated hex colors to cycle through (default: rotate the -fg hue)")
+	var payloads stringList
+	fs.Var(&payloads, "payload", "extra content for -animate payloads (repeatable)")
+	preset := fs.String("preset", "", "apply a saved style preset (flags override it)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: qrgen generate -content <text> [flags]")
+		fmt.Fprintln(os.Stderr, "       echo <text> | qrgen generate [flags]")
+		fmt.Fprintln(os.Stderr, "       qrgen generate -type sepa -field name=<beneficiary> -field iban=<iban> [-field amount=12.50] [flags]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *typeName != "" {
+		cfg.Content, err = templateContent(*typeName, fields, *content)
+		// A generated authenticator secret is only shown here: history
+		// stores the code with the secret redacted.
+		if secret := templates.OTPSecret(cfg.Content); err == nil && secret != "" && !hasField(fields, "secret") {
+			fmt.Fprintf(os.Stderr, "Generated secret: %s\n", secret)
+		}
+	} else if len(fields) > 0 {
+		err = fmt.Errorf("-field requires -type")
+	} else {
+		cfg.Content, err = readContent(*content, *contentFile)
+	}
+	if err != nil {
+		return err
+	}
+	if *preset != "" {
+		if err := applyPreset(cfg, *preset); err != nil {
+			return err
+		}
+	}
+	// Flag defaults mirror cfg, so only explicitly set flags are applied;
+	// this lets them override the preset.
+	if err := applyStyleFlags(fs, cfg); err != nil {
+		return err
+	}
+	// A logo hides the center modules, so default to the highest error
+	// correction unless chosen explicitly.
+	if flagWasSet(fs, "logo") && !flagWasSet(fs, "ec") {
+		cfg.Level = config.ECHigh
+	}
+	if *halftoneImage != "" {
+		cfg.Halftone = config.ExpandHome(*halftoneImage)
+		// The image hides part of every module, so default to the
+		// highest error correction unless chosen explicitly.
+		if !flagWasSet(fs, "ec") {
+			cfg.Level = config.ECHigh
+		}
+	}
+	ct := templates.DetectType(cfg.Content)
+	required := templates.RequiredErrorCorrection(ct)
+	if err := cfg.RequireLevel(config.ErrorCorrection(required), flagWasSet(fs, "ec")); err != nil {
+		return err
+	}
+	cfg.SwissCross = cfg.SwissCross || templates.RequiresSwissCross(ct)
+	if *animate != "" {
+		anim, err := parseAnimation(*animate, *colors, payloads)
+		if err != nil {
+			return err
+		}
+		anim.Frames, anim.Delay, anim.Loop = *frames, *delay, *loop
+		cfg.Animation = anim
+		if !flagWasSet(fs, "format") {
+			cfg.SetFormats([]config.OutputFormat{config.FormatGIF})
+		}
+	}
+	swissSized := false
+	if *printSize != "" {
+		mm, err := config.ParseLength(*printSize)
+		if err != nil {
+			return err
+		}
+		if cfg.DPI == 0 {
+			cfg.DPI = defaultPrintDPI
+		}
+		cfg.SetSizes([]int{config.PixelsForLength(mm, cfg.DPI)})
+	} else if swissSized = templates.RequiresSwissCross(ct) && !flagWasSet(fs, "size") && !flagWasSet(fs, "sizes"); swissSized {
+		// QR-bills are printed with a 46 mm symbol.
+		if cfg.DPI == 0 {
+			cfg.DPI = defaultPrintDPI
+		}
+		bitmap, err := generator.New(cfg).Bitmap()
+		if err != nil {
+			return err
+		}
+		cfg.SetSizes([]int{config.SwissQRSize(len(bitmap), cfg.DPI)})
+	}
+	distance := config.DefaultScanDistance
+	if *scanDistance != "" {
+		if distance, err = config.ParseLength(*scanDistance); err != nil {
+			return err
+		}
+	}
+	if cfg.OnCollision, err = config.ParseCollisionPolicy(*onCollision); err != nil {
+		return err
+	}
+	unattendedCollisions(fs, cfg)
+	cfg.SetOutputPattern(*output, config.FilenameVars{
+		Content: templates.RedactSecrets(cfg.Content), // Keep secrets out of {slug} filenames
+		Type:    templates.TypeName(templates.DetectType(cfg.Content)),
+		Time:    time.Now(),
+	})
+	if err := confirmOverwrite(cfg); err != nil {
+		return err
+	}
+
+	gen := generator.New(cfg)
+	if err := gen.Generate(); err != nil {
+		return fmt.Errorf("generation failed: %w", err)
+	}
+
+	// Streams are part of a pipeline: keep stdout clean and skip history,
+	// since there is no file to re-generate.
+	if cfg.OutputPath == config.StdoutPath {
+		return nil
+	}
+
+	if store, err := history.NewStore(); err == nil {
+		_ = store.Add(history.NewEntry(cfg, gen.Paths()))
+	}
+
+	for _, path := range gen.Paths() {
+		fmt.Printf("✓ Generated QR code: %s\n", path)
+	}
+	if cfg.Halftone != "" && !*noVerify {
+		if err := verifyHalftone(cfg, gen); err != nil {
+			return err
+		}
+	}
+	if swissSized && *scanDistance == "" {
+		fmt.Printf("  Sized for a %g mm QR-bill symbol at %d DPI\n", config.SwissQRSymbolMM, cfg.DPI)
+	} else if cfg.DPI != 0 || *scanDistance != "" {
+		if bitmap, err := gen.Bitmap(); err == nil {
+			printSizeReport(cfg, len(bitmap), distance)
+		}
+	}
+	return nil
+}
+
+// verifyHalftone reads back every raster output and checks that it still
+// decodes to the original content.
+func verifyHalftone(cfg *config.QRConfig, gen *generator.Generator) error {
+	bitmap, err := gen.Bitmap()
+	if err != nil {
+		return err
+	}
+
+	for _, o := range cfg.Outputs() {
+		if !o.Format.IsRaster() {
+			continue
+		}
+		img, err := halftone.LoadImage(o.Path)
+		if err != nil {
+			return err
+		}
+		report, err := halftone.Verify(img, bitmap, cfg.Content, cfg.Foreground, cfg.Background)
+		if err != nil {
+			return fmt.Errorf("halftone verification failed for %s: %w (try -ec H, a larger -size or a higher-contrast image)",
+				o.Path, err)
+		}
+		fmt.Printf("  Verified %s: decodes correctly (%d of %d modules misread, %d codewords corrected)\n",
+			o.Path, report.Flipped, report.Modules, report.Corrected)
+	}
+	return nil
+}
+
+// parseAnimation builds animation options from the -animate, -colors and
+// -payload flags.
+func parseAnimation(mode, colors string, payloads []string) (config.Animation, error) {
+	var anim config.Animation
+	var err error
+	if anim.Mode, err = config.ParseAnimationMode(mode); err != nil {
+		return anim, err
+	}
+	anim.Payloads = payloads
+	for _, hex := range strings.Split(colors, ",") {
+		if hex = strings.TrimSpace(hex); hex == "" {
+			continue
+		}
+		c, err := config.ParseHexColor(hex)
+		if err != nil {
+			return anim, fmt.Errorf("invalid animation color: %w", err)
+		}
+		anim.Colors = append(anim.Colors, c)
+	}
+	return anim, nil
+}
+
+// stringList is a flag that can be given several times.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// flagWasSet reports whether the named flag was given on the command line.
+func flagWasSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// defaultPrintDPI is the resolution assumed for -print-size without -dpi.
+const defaultPrintDPI = 300
+
+// printSizeReport prints the physical size of each raster output and the
+// minimum recommended size for scanning from distance mm away.
+func printSizeReport(cfg *config.QRConfig, modules int, distance float64) {
+	minSize := config.MinPrintSize(modules, distance)
+	fmt.Printf("  Minimum print size at %g cm: %s (%d modules)\n", distance/10, config.FormatLength(minSize), modules)
+	if cfg.DPI == 0 {
+		return
+	}
+
+	sizes := cfg.Sizes
+	if len(sizes) == 0 {
+		sizes = []int{cfg.Size}
+	}
+	for _, size := range sizes {
+		mm := config.LengthForPixels(size, cfg.DPI)
+		fmt.Printf("  %dpx prints at %s at %d DPI\n", size, config.FormatLength(mm), cfg.DPI)
+		if mm < minSize {
+			fmt.Printf("  ⚠ Too small to scan reliably from %g cm; use -print-size %.0fmm or larger\n",
+				distance/10, math.Ceil(minSize))
+		}
+	}
+}
+
+// confirmOverwrite implements the prompt collision policy: on a terminal
+// the user is asked before an existing file is replaced; otherwise the
+// collision is reported as an error.
+func confirmOverwrite(cfg *config.QRConfig) error {
+	if cfg.OnCollision != config.CollisionPrompt {
+		return nil
+	}
+	existing := cfg.ExistingOutput()
+	if existing == "" {
+		return nil
+	}
+	if err := askOverwrite(existing); err != nil {
+		return err
+	}
+	cfg.OnCollision = config.CollisionOverwrite
+	return nil
+}
+
+// unattendedCollisions keeps piped runs working as they did before the
+// prompt policy existed: with stdin not a terminal there is no one to ask,
+// so a prompt policy that comes from the settings overwrites instead. An
+// explicit -on-collision prompt still fails on a collision.
+func unattendedCollisions(fs *flag.FlagSet, cfg *config.QRConfig) {
+	if cfg.OnCollision == config.CollisionPrompt && stdinIsPiped() && !flagWasSet(fs, "on-collision") {
+		cfg.OnCollision = config.CollisionOverwrite
+	}
+}
+
+// askOverwrite asks on the terminal whether existing may be replaced and
+// returns an error unless the user agrees.
+func askOverwrite(existing string) error {
+	if stdinIsPiped() {
+		return fmt.Errorf("%w: %s (use -on-collision overwrite or increment)",
+			config.ErrOutputExists, existing)
+	}
+
+	fmt.Fprintf(os.Stderr, "%s already exists. Overwrite? [y/N] ", existing)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("not overwriting %s", existing)
+}
+
+// applyPreset loads the named preset and applies it to cfg.
+func applyPreset(cfg *config.QRConfig, name string) error {
+	store, err := presets.NewStore()
+	if err != nil {
+		return err
+	}
+	p, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+	p.Apply(cfg)
+	return nil
+}
+
+// applyStyleFlags copies the style flags that were set on the command line
+// onto cfg. Flags that are not style options are ignored.
+func applyStyleFlags(fs *flag.FlagSet, cfg *config.QRConfig) error {
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if err == nil {
+			err = applyStyleFlag(cfg, f.Name, f.Value.String())
+		}
+	})
+	return err
+}
+
+// applyStyleFlag sets a single style option on cfg from its flag value.
+func applyStyleFlag(cfg *config.QRConfig, name, value string) error {
+	var err error
+	switch name {
+	case "format":
+		cfg.SetFormats(config.ParseFormats(value))
+	case "dpi":
+		dpi, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid DPI %q", value)
+		}
+		cfg.DPI = dpi
+	case "size":
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid size %q", value)
+		}
+		cfg.SetSizes([]int{size})
+	case "sizes":
+		sizes, err := config.ParseSizes(value)
+		if err != nil {
+			return err
+		}
+		cfg.SetSizes(sizes)
+	case "fg":
+		if cfg.Foreground, err = config.ParseHexColor(value); err != nil {
+			return fmt.Errorf("invalid foreground color: %w", err)
+		}
+	case "bg":
+		if cfg.Background, err = config.ParseHexColor(value); err != nil {
+			return fmt.Errorf("invalid background color: %w", err)
+		}
+	case "ec":
+		if cfg.Level, err = config.ParseErrorCorrection(value); err != nil {
+			return err
+		}
+	case "embed":
+		cfg.Embed = config.ParseFormat(value)
+	case "alt":
+		cfg.Alt = value
+	case "renderer":
+		cfg.Renderer = config.TextRenderer(strings.ToLower(value))
+	case "invert":
+		cfg.Invert = value == "true"
+	case "swiss-cross":
+		cfg.SwissCross = value == "true"
+	case "gradient":
+		if value == "" {
+			cfg.Gradient = color.RGBA{}
+		} else if cfg.Gradient, err = config.ParseHexColor(value); err != nil {
+			return fmt.Errorf("invalid gradient color: %w", err)
+		}
+	case "module-style":
+		if cfg.ModuleStyle, err = config.ParseModuleStyle(value); err != nil {
+			return err
+		}
+	case "logo":
+		cfg.Logo = config.ExpandHome(value)
+	case "frame":
+		if cfg.Frame, err = strconv.Atoi(value); err != nil {
+			return fmt.Errorf("invalid frame width %q", value)
+		}
+	}
+	return nil
+}
+
+// readContent resolves the content to encode from the -content flag, a
+// content file, or piped stdin, in that order of precedence.
+func readContent(content, contentFile string) (string, error) {
+	if content != "" {
+		return content, nil
+	}
+
+	var data []byte
+	var err error
+	switch {
+	case contentFile == "-":
+		data, err = io.ReadAll(os.Stdin)
+	case contentFile != "":
+		data, err = os.ReadFile(contentFile)
+	case stdinIsPiped():
+		data, err = io.ReadAll(os.Stdin)
+	default:
+		return "", fmt.Errorf("no content given: use -content, -content-file, or pipe data to stdin")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read content: %w", err)
+	}
+
+	// Drop the single trailing newline most tools (echo, editors) append.
+	text := strings.TrimSuffix(string(data), "\n")
+	text = strings.TrimSuffix(text, "\r")
+	return text, nil
+}
+
+// templateContent builds content for the -type template from name=value
+// -field flags. The -content value fills the "content" field used by the
+// url and text types.
+func templateContent(name string, pairs []string, content string) (string, error) {
+	ct, err := templates.ParseContentType(name)
+	if err != nil {
+		return "", err
+	}
+	fields := map[string]string{"content": content}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return "", fmt.Errorf("invalid -field %q (expected name=value)", pair)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "photo" && strings.HasPrefix(value, "@") {
+			// -field photo=@avatar.jpg embeds the file
+			data, err := os.ReadFile(config.ExpandHome(value[1:]))
+			if err != nil {
+				return "", fmt.Errorf("failed to read photo: %w", err)
+			}
+			value = templates.PhotoDataURI(data)
+		}
+		fields[key] = value
+	}
+	return templates.FromFields(ct, fields)
+}
+
+// hasField reports whether a -field name=value list sets name.
+func hasField(pairs []string, name string) bool {
+	for _, pair := range pairs {
+		if key, _, _ := strings.Cut(pair, "="); strings.EqualFold(strings.TrimSpace(key), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// stdinIsPiped reports whether stdin is a pipe or file rather than a terminal.
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// defaultOutputBase returns the configured output pattern without extension.
+func defaultOutputBase(settings *config.UserSettings) string {
+	return filepath.Join(config.ExpandHome(settings.OutputDir), settings.DefaultFilename())
+}
+
+// joinRenderers returns the available text renderer names for help output.
+func joinRenderers() string {
+	names := make([]string, 0, len(config.TextRenderers()))
+	for _, r := range config.TextRenderers() {
+		names = append(names, string(r))
+	}
+	return strings.Join(names, ", ")
+}
@@ -1,7 +1,7 @@
 // QR Code Generator - A terminal-based QR code generation tool.
 //
 // This application provides an interactive terminal UI for generating QR codes
-// with customizable colors, formats (PNG/SVG), and dimensions.
+// with customizable colors, formats (PNG/SVG/text), and dimensions.
 package main
 
 import (
@@ -12,6 +12,7 @@ import (
 	"github.com/DalyChouikh/internal/config"
 	"github.com/DalyChouikh/internal/generator"
 	"github.com/DalyChouikh/internal/history"
+	"github.com/DalyChouikh/internal/templates"
 	"github.com/DalyChouikh/internal/ui"
 	"github.com/DalyChouikh/internal/updater"
 	tea "github.com/charmbracelet/bubbletea"
@@ -59,6 +60,48 @@ func main() {
 			printHelp()
 			os.Exit(0)
 
+		case "generate":
+			if err := handleGenerate(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+
+		case "batch":
+			if err := handleBatch(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+
+		case "sheet":
+			if err := handleSheet(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+
+		case "serve":
+			if err := handleServe(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+
+		case "config":
+			if err := handleConfig(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+
+		case "preset":
+			if err := handlePreset(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+
 		case "history":
 			handleHistory()
 			os.Exit(0)
@@ -92,6 +135,12 @@ func printHelp() {
 
 Usage:
   qrgen                 Launch interactive QR code generator
+  qrgen generate        Generate a QR code from flags (see 'qrgen generate -h')
+  qrgen batch <file>    Generate one QR code per row of a CSV/JSONL manifest
+  qrgen sheet <file>    Lay out codes on printable label sheets (PDF/SVG)
+  qrgen serve           Serve QR codes over HTTP (see 'qrgen serve -h')
+  qrgen config          View or change default settings (see 'qrgen config help')
+  qrgen preset          Manage named style presets (see 'qrgen preset help')
   qrgen history         Show generation history
   qrgen regen <id>      Re-generate a QR code from history
   qrgen update          Update qrgen to the latest version
@@ -130,18 +179,65 @@ func handleRegen(idStr string) {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		return
 	}
+	if entry.Redacted {
+		fmt.Fprintf(os.Stderr, "Entry #%d contains a secret that was not saved; generate it again from the source\n", id)
+		return
+	}
 
 	// Reconstruct config from history entry
 	fgColor, _ := config.ParseHexColor(entry.FgColor)
 	bgColor, _ := config.ParseHexColor(entry.BgColor)
 	cfg := &config.QRConfig{
-		Content:    entry.Content,
-		Format:     config.OutputFormat(entry.Format),
-		Size:       entry.Size,
-		Foreground: fgColor,
-		Background: bgColor,
-		OutputPath: entry.OutputPath,
+		Content:     entry.Content,
+		Format:      config.OutputFormat(entry.Format),
+		Size:        entry.Size,
+		Foreground:  fgColor,
+		Background:  bgColor,
+		OutputPath:  entry.OutputPath,
+		Level:       config.ErrorCorrection(entry.Level),
+		Renderer:    config.TextRenderer(entry.Renderer),
+		Invert:      entry.Invert,
+		Sizes:       entry.Sizes,
+		DPI:         entry.DPI,
+		Halftone:    entry.Halftone,
+		ModuleStyle: config.ModuleStyle(entry.ModuleStyle),
+		Logo:        entry.Logo,
+		Frame:       entry.Frame,
+		Embed:       config.OutputFormat(entry.Embed),
+		Alt:         entry.Alt,
+	}
+	for _, f := range entry.Formats {
+		cfg.Formats = append(cfg.Formats, config.OutputFormat(f))
 	}
+	cfg.Animation = config.Animation{
+		Mode:     config.AnimationMode(entry.Animation),
+		Frames:   entry.Frames,
+		Delay:    entry.Delay,
+		Loop:     entry.Loop,
+		Payloads: entry.Payloads,
+	}
+	for _, hex := range entry.Colors {
+		c, err := config.ParseHexColor(hex)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid animation color in entry #%d: %v\n", id, err)
+			return
+		}
+		cfg.Animation.Colors = append(cfg.Animation.Colors, c)
+	}
+	if entry.Gradient != "" {
+		if cfg.Gradient, err = config.ParseHexColor(entry.Gradient); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid gradient color in entry #%d: %v\n", id, err)
+			return
+		}
+	}
+	// Swiss QR-bills always need the cross and level M, so derive them from
+	// the content as generate does; older entries did not record the cross.
+	ct := templates.DetectType(cfg.Content)
+	if err := cfg.RequireLevel(config.ErrorCorrection(templates.RequiredErrorCorrection(ct)), false); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return
+	}
+	cfg.SwissCross = entry.SwissCross || templates.RequiresSwissCross(ct)
 
 	gen := generator.New(cfg)
 	if err := gen.Generate(); err != nil {
@@ -149,5 +245,7 @@ func handleRegen(idStr string) {
 		return
 	}
 
-	fmt.Printf("✓ Re-generated QR code: %s\n", entry.OutputPath)
+	for _, path := range gen.Paths() {
+		fmt.Printf("✓ Re-generated QR code: %s\n", path)
+	}
 }
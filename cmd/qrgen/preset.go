@@ -0,0 +1,195 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DalyChouikh/internal/presets"
+)
+
+// handlePreset implements `qrgen preset save|list|delete|export|import`.
+func handlePreset(args []string) error {
+	if len(args) == 0 {
+		printPresetUsage()
+		return fmt.Errorf("missing preset subcommand")
+	}
+
+	store, err := presets.NewStore()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		list := store.List()
+		if len(list) == 0 {
+			fmt.Println("No presets yet. Save one with 'qrgen preset save <name> -fg ... -bg ...'")
+			return nil
+		}
+		for _, p := range list {
+			fmt.Printf("%-20s %s\n", p.Name, p.Summary())
+		}
+		return nil
+
+	case "save":
+		return savePreset(store, args[1:])
+
+	case "delete", "rm":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: qrgen preset delete <name>")
+		}
+		if err := store.Delete(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Deleted preset %s\n", args[1])
+		return nil
+
+	case "export":
+		return exportPresets(store, args[1:])
+
+	case "import":
+		return importPresets(store, args[1:])
+
+	case "-h", "--help", "help":
+		printPresetUsage()
+		return nil
+	}
+
+	printPresetUsage()
+	return fmt.Errorf("unknown preset subcommand: %s", args[0])
+}
+
+// savePreset creates or replaces a preset from the style flags that are set.
+func savePreset(store *presets.Store, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: qrgen preset save <name> [-format f] [-size n] [-fg hex] [-bg hex] [-ec level] [-gradient hex] [-module-style s] [-logo file] [-frame n]")
+	}
+	p := presets.Preset{Name: args[0]}
+
+	fs := flag.NewFlagSet("preset save", flag.ContinueOnError)
+	fs.StringVar(&p.Format, "format", "", "output format: png, jpg, gif, svg, txt, html, datauri or md")
+	fs.IntVar(&p.Size, "size", 0, "size in pixels (64-4096)")
+	fs.StringVar(&p.Foreground, "fg", "", "foreground color (hex)")
+	fs.StringVar(&p.Background, "bg", "", "background color (hex)")
+	fs.StringVar(&p.ErrorCorrection, "ec", "", "error correction level: L, M, Q or H")
+	fs.StringVar(&p.Gradient, "gradient", "", "end color (hex) of a diagonal gradient starting at -fg")
+	fs.StringVar(&p.ModuleStyle, "module-style", "", "module shape: square, dots or rounded")
+	fs.StringVar(&p.Logo, "logo", "", "image drawn in the center of the code")
+	fs.IntVar(&p.Frame, "frame", 0, "width in modules of a border around the quiet zone (0-8)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	// Presets are used from any directory, so store the logo's full path.
+	if p.Logo != "" && !strings.HasPrefix(p.Logo, "~") {
+		abs, err := filepath.Abs(p.Logo)
+		if err != nil {
+			return fmt.Errorf("invalid logo path: %w", err)
+		}
+		p.Logo = abs
+	}
+	if fs.NFlag() == 0 {
+		return fmt.Errorf("preset %q sets no options: pass at least one of -format, -size, -fg, -bg, -ec, -gradient, -module-style, -logo, -frame", p.Name)
+	}
+
+	_, err := store.Get(p.Name)
+	existed := err == nil
+	if err := store.Save(p); err != nil {
+		return err
+	}
+
+	if existed {
+		fmt.Printf("✓ Updated preset %s\n", p.Name)
+	} else {
+		fmt.Printf("✓ Saved preset %s\n", p.Name)
+	}
+	return nil
+}
+
+// exportPresets writes the named presets (or all of them) as JSON.
+func exportPresets(store *presets.Store, args []string) error {
+	fs := flag.NewFlagSet("preset export", flag.ContinueOnError)
+	output := fs.String("o", "-", "output file ('-' for stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	list := store.List()
+	if fs.NArg() > 0 {
+		list = make([]presets.Preset, 0, fs.NArg())
+		for _, name := range fs.Args() {
+			p, err := store.Get(name)
+			if err != nil {
+				return err
+			}
+			list = append(list, *p)
+		}
+	}
+
+	data, err := presets.Encode(list)
+	if err != nil {
+		return err
+	}
+	if *output == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write presets: %w", err)
+	}
+	fmt.Printf("✓ Exported %d preset(s) to %s\n", len(list), *output)
+	return nil
+}
+
+// importPresets reads presets exported with `qrgen preset export`. Presets
+// that already exist are kept unless -force is given.
+func importPresets(store *presets.Store, args []string) error {
+	fs := flag.NewFlagSet("preset import", flag.ContinueOnError)
+	force := fs.Bool("force", false, "replace presets that already exist")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: qrgen preset import [-force] <file>")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to read presets: %w", err)
+	}
+	list, err := presets.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	imported := 0
+	for _, p := range list {
+		if _, err := store.Get(p.Name); err == nil && !*force {
+			fmt.Printf("- Skipped %s (already exists, use -force to replace)\n", p.Name)
+			continue
+		}
+		if err := store.Save(p); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Imported %s\n", p.Name)
+		imported++
+	}
+	fmt.Printf("\n%d of %d preset(s) imported\n", imported, len(list))
+	return nil
+}
+
+func printPresetUsage() {
+	fmt.Fprint(os.Stderr, `Usage:
+  qrgen preset list                        Show saved presets
+  qrgen preset save <name> [flags]         Save a preset (-format, -size, -fg, -bg, -ec,
+                                           -gradient, -module-style, -logo, -frame)
+  qrgen preset delete <name>               Delete a preset
+  qrgen preset export [-o file] [name...]  Export presets as JSON (all by default)
+  qrgen preset import [-force] <file>      Import presets from an exported file
+
+Use a preset with 'qrgen generate -preset <name>', 'qrgen batch -preset <name>',
+or pick it at the start of the interactive wizard.
+`)
+}
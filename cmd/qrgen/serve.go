@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/DalyChouikh/internal/config"
+	"github.com/DalyChouikh/internal/server"
+)
+
+// handleServe runs the HTTP API until interrupted.
+func handleServe(args []string) error {
+	opts := server.DefaultOptions()
+	cfg, _, err := config.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	opts.Defaults = *cfg
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "address to listen on")
+	fs.IntVar(&opts.MaxContentLength, "max-content", opts.MaxContentLength, "maximum content length in bytes")
+	fs.IntVar(&opts.MaxSize, "max-size", opts.MaxSize, "maximum image size in pixels")
+	fs.Int64Var(&opts.MaxBodyBytes, "max-body", opts.MaxBodyBytes, "maximum POST body size in bytes")
+	fs.IntVar(&opts.CacheEntries, "cache", opts.CacheEntries, "number of rendered codes to cache (0 disables)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: qrgen serve [flags]")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Endpoints:")
+		fmt.Fprintln(os.Stderr, "  GET  /qr?content=...&format=svg&size=512")
+		fmt.Fprintln(os.Stderr, "  POST /qr  {\"type\":\"wifi\",\"fields\":{\"ssid\":\"Office\"},\"format\":\"png\"}")
+		fmt.Fprintln(os.Stderr, "  GET  /healthz")
+		fmt.Fprintln(os.Stderr, "")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("qrgen serving on http://%s (Ctrl+C to stop)\n", *addr)
+	if err := server.New(opts).ListenAndServe(ctx, *addr); err != nil {
+		return fmt.Errorf("server error: %w", err)
+	}
+	fmt.Println("Server stopped.")
+	return nil
+}
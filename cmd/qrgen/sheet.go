@@ -0,0 +1,294 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DalyChouikh/internal/batch"
+	"github.com/DalyChouikh/internal/config"
+	"github.com/DalyChouikh/internal/fsutil"
+	"github.com/DalyChouikh/internal/generator"
+	"github.com/DalyChouikh/internal/history"
+	"github.com/DalyChouikh/internal/layout"
+	"github.com/DalyChouikh/internal/templates"
+)
+
+// handleSheet lays out many QR codes on printable label sheets.
+func handleSheet(args []string) error {
+	cfg, _, err := config.LoadUserConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	fs := flag.NewFlagSet("sheet", flag.ContinueOnError)
+	templateName := fs.String("template", "avery-l7160", "built-in label template (see -list)")
+	list := fs.Bool("list", false, "list the built-in label templates and exit")
+	grid := fs.String("grid", "", "custom grid instead of a template, e.g. 4x6")
+	pageName := fs.String("page", "a4", "page size for -grid: a4 or letter")
+	margin := fs.Float64("margin", 10, "page margin in mm for -grid")
+	gutter := fs.Float64("gutter", 3, "gap between labels in mm for -grid")
+	cropMarks := fs.Bool("crop-marks", false, "draw crop marks in the page margins")
+	captions := fs.Bool("captions", false, "print a caption under every code")
+	skip := fs.Int("skip", 0, "number of labels already used on the first sheet")
+	ids := fs.String("history", "", "lay out history entries instead of a manifest, e.g. 1,3,5-8")
+	format := fs.String("format", "pdf", "output format: pdf (one multi-page file) or svg (one file per page)")
+	output := fs.String("o", "sheet", "output file path without extension")
+	onCollision := fs.String("on-collision", string(cfg.OnCollision),
+		"if an output file exists: overwrite, increment, prompt or fail")
+	fs.String("fg", config.ColorToHex(cfg.Foreground), "foreground color (hex)")
+	fs.String("bg", config.ColorToHex(cfg.Background), "background color (hex)")
+	fs.String("ec", string(cfg.Level), "error correction level: L, M, Q or H")
+	preset := fs.String("preset", "", "apply a saved style preset (flags override it)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: qrgen sheet [flags] <manifest.csv|manifest.jsonl>")
+		fmt.Fprintln(os.Stderr, "       qrgen sheet [flags] -history <ids>")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Manifest rows use the same columns as qrgen batch; an optional")
+		fmt.Fprintln(os.Stderr, "\"caption\" column sets the caption (default: the encoded content,")
+		fmt.Fprintln(os.Stderr, "with authenticator secrets replaced by REDACTED).")
+		fmt.Fprintln(os.Stderr, "")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *list {
+		printSheetTemplates()
+		return nil
+	}
+
+	if *preset != "" {
+		if err := applyPreset(cfg, *preset); err != nil {
+			return err
+		}
+	}
+	if err := applyStyleFlags(fs, cfg); err != nil {
+		return err
+	}
+	if cfg.OnCollision, err = config.ParseCollisionPolicy(*onCollision); err != nil {
+		return err
+	}
+	unattendedCollisions(fs, cfg)
+
+	var tmpl layout.Template
+	if *grid != "" {
+		page, err := layout.ParsePage(*pageName)
+		if err != nil {
+			return err
+		}
+		columns, rows, err := layout.ParseGrid(*grid)
+		if err != nil {
+			return err
+		}
+		if tmpl, err = layout.GridTemplate(page, columns, rows, *margin, *gutter); err != nil {
+			return err
+		}
+	} else if tmpl, err = layout.LookupTemplate(*templateName); err != nil {
+		return err
+	}
+
+	var items []layout.Item
+	switch {
+	case *ids != "" && fs.NArg() == 0:
+		items, err = historyItems(cfg, *ids)
+	case *ids == "" && fs.NArg() == 1:
+		items, err = manifestItems(cfg, fs.Arg(0))
+	default:
+		fs.Usage()
+		return fmt.Errorf("expected either one manifest file or -history")
+	}
+	if err != nil {
+		return err
+	}
+
+	opts := layout.Options{
+		Template:   tmpl,
+		Foreground: cfg.Foreground,
+		Background: cfg.Background,
+		CropMarks:  *cropMarks,
+		Captions:   *captions,
+		Skip:       *skip,
+	}
+
+	base := config.ExpandHome(*output)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+
+	var paths []string
+	var pages [][]byte
+	switch strings.ToLower(*format) {
+	case "pdf":
+		paths = []string{base + ".pdf"}
+	case "svg":
+		if pages, err = layout.SVGPages(items, opts); err != nil {
+			return err
+		}
+		if len(pages) == 1 {
+			paths = []string{base + ".svg"}
+		} else {
+			for i := range pages {
+				paths = append(paths, fmt.Sprintf("%s-%d.svg", base, i+1))
+			}
+		}
+	default:
+		return fmt.Errorf("invalid sheet format %q: use pdf or svg", *format)
+	}
+
+	if cfg.OnCollision == config.CollisionPrompt {
+		for _, path := range paths {
+			if _, err := os.Stat(path); err == nil {
+				if err := askOverwrite(path); err != nil {
+					return err
+				}
+				cfg.OnCollision = config.CollisionOverwrite
+				break
+			}
+		}
+	}
+	if paths, err = config.ResolveOutputPaths(paths, cfg.OnCollision); err != nil {
+		return err
+	}
+
+	for i, path := range paths {
+		err := fsutil.WriteFileAtomic(path, func(w io.Writer) error {
+			if pages != nil {
+				_, err := w.Write(pages[i])
+				return err
+			}
+			return layout.WritePDF(w, items, opts)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	fmt.Printf("✓ Laid out %d codes on %d page(s) (%s)\n",
+		len(items), layout.Pages(len(items), opts), tmpl.Description)
+	for _, path := range paths {
+		fmt.Printf("  %s\n", path)
+	}
+	return nil
+}
+
+// manifestItems encodes every row of a batch manifest.
+func manifestItems(cfg *config.QRConfig, path string) ([]layout.Item, error) {
+	rows, err := batch.ReadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]layout.Item, 0, len(rows))
+	for _, row := range rows {
+		content, err := batch.Content(row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row.Index, err)
+		}
+		// A generated secret would only exist on paper, with no way to
+		// store it server-side, so such rows need an explicit secret.
+		if generate, _ := strconv.ParseBool(row.Fields["generate_secret"]); generate {
+			return nil, fmt.Errorf("row %d: generate_secret is not supported in sheets; generate the secret first and put it in the 'secret' column", row.Index)
+		}
+		caption := row.Fields["caption"]
+		if caption == "" {
+			caption = templates.RedactSecrets(content)
+		}
+		item, err := sheetItem(cfg, content, caption)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row.Index, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// historyItems encodes the content of the given history entries.
+func historyItems(cfg *config.QRConfig, ids string) ([]layout.Item, error) {
+	list, err := parseIDList(ids)
+	if err != nil {
+		return nil, err
+	}
+	store, err := history.NewStore()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]layout.Item, 0, len(list))
+	for _, id := range list {
+		entry, err := store.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Redacted {
+			return nil, fmt.Errorf("history entry %d contains a secret that was not saved", id)
+		}
+		// Entries written before secrets were redacted may still hold one.
+		item, err := sheetItem(cfg, entry.Content, templates.RedactSecrets(entry.Content))
+		if err != nil {
+			return nil, fmt.Errorf("history entry %d: %w", id, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// sheetItem encodes content with the style options in cfg, applying the
+// error correction level and Swiss cross its content type requires.
+func sheetItem(cfg *config.QRConfig, content, caption string) (layout.Item, error) {
+	c := *cfg
+	c.Content = content
+	ct := templates.DetectType(content)
+	if err := c.RequireLevel(config.ErrorCorrection(templates.RequiredErrorCorrection(ct)), false); err != nil {
+		return layout.Item{}, err
+	}
+	bitmap, err := generator.New(&c).Bitmap()
+	if err != nil {
+		return layout.Item{}, err
+	}
+	return layout.Item{
+		Bitmap:     bitmap,
+		Caption:    caption,
+		SwissCross: c.SwissCross || templates.RequiresSwissCross(ct),
+	}, nil
+}
+
+// parseIDList parses a list of IDs and ranges such as "1,3,5-8".
+func parseIDList(s string) ([]int, error) {
+	var ids []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(first)
+		to := from
+		if err == nil && isRange {
+			to, err = strconv.Atoi(last)
+		}
+		if err != nil || from < 1 || to < from {
+			return nil, fmt.Errorf("invalid history ID or range %q", part)
+		}
+		for id := from; id <= to; id++ {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no history IDs given")
+	}
+	return ids, nil
+}
+
+// printSheetTemplates lists the built-in label templates.
+func printSheetTemplates() {
+	fmt.Println("Label templates:")
+	for _, t := range layout.Templates() {
+		fmt.Printf("  %-14s %s\n", t.Name, t.Description)
+	}
+	fmt.Println("\nUse -grid COLUMNSxROWS with -page, -margin and -gutter for a custom grid.")
+}
@@ -0,0 +1,334 @@
+// Package batch generates many QR codes from a CSV or JSON Lines manifest.
+//
+// Each manifest row maps to one QR code. Columns named after QRConfig
+// options (format, size, fg, bg, ec, renderer, invert) override the defaults for
+// that row, a "type" column selects a content template (wifi, vcard, email,
+// sms, url, text), and every other column is available as template data and
+// as a {column} placeholder in the output filename pattern.
+//
+// Rows are generated in parallel by a bounded pool of workers. Batch runs do
+// not write to the generation history, which only keeps the last 50 entries.
+package batch
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DalyChouikh/internal/config"
+	"github.com/DalyChouikh/internal/generator"
+	"github.com/DalyChouikh/internal/templates"
+)
+
+// Status describes the outcome of a single row.
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped" // Not attempted because of fail-fast
+)
+
+// DefaultPattern names output files after the row number.
+const DefaultPattern = "qrcode-{row}"
+
+// Options controls a batch run.
+type Options struct {
+	Defaults  config.QRConfig // Base configuration applied to every row
+	Pattern   string          // Output filename pattern, e.g. "{id}-{name}.png"; see config.ExpandFilename
+	OutputDir string          // Directory that relative output paths are placed in
+	Workers   int             // Maximum number of rows generated in parallel
+	FailFast  bool            // Stop scheduling new rows after the first failure
+}
+
+// Result is the per-row outcome of a batch run.
+type Result struct {
+	Row    int    `json:"row"`
+	Status Status `json:"status"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	// Secret is set when the row generated an authenticator secret
+	// (generate_secret), which is otherwise only inside the code. Callers
+	// must not print or store it without the user's consent.
+	Secret string `json:"secret,omitempty"`
+}
+
+// Run generates a QR code for every row and returns one Result per row, in
+// manifest order. It returns an error only if the options themselves are
+// invalid; per-row failures are reported in the results.
+func Run(ctx context.Context, rows []Row, opts Options) ([]Result, error) {
+	if opts.Pattern == "" {
+		opts.Pattern = DefaultPattern
+	}
+	if err := checkPattern(opts.Pattern); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]Result, len(rows))
+	for i, row := range rows {
+		results[i] = Result{Row: row.Index, Status: StatusSkipped}
+	}
+
+	// Resolve every row up front so duplicate output paths are caught before
+	// any file is written.
+	configs := make([]*config.QRConfig, len(rows))
+	seen := make(map[string]int, len(rows))
+	for i, row := range rows {
+		cfg, err := rowConfig(row, opts, now, seen)
+		if err != nil {
+			results[i].Status = StatusFailed
+			results[i].Error = err.Error()
+			continue
+		}
+		if first, dup := firstDuplicate(seen, cfg.OutputPaths()); dup {
+			results[i].Status = StatusFailed
+			results[i].Output = cfg.OutputPath
+			results[i].Error = fmt.Sprintf("output path already used by row %d", first)
+			continue
+		}
+		for _, path := range cfg.OutputPaths() {
+			seen[path] = row.Index
+		}
+		configs[i] = cfg
+		results[i].Output = cfg.OutputPath
+		if row.Fields["secret"] == "" {
+			results[i].Secret = templates.OTPSecret(cfg.Content)
+		}
+	}
+
+	if opts.FailFast && hasFailure(results) {
+		return results, nil
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				gen := generator.New(configs[i])
+				if err := gen.Generate(); err != nil {
+					results[i].Status = StatusFailed
+					results[i].Error = err.Error()
+					if opts.FailFast {
+						cancel()
+					}
+					continue
+				}
+				results[i].Status = StatusOK
+				if paths := gen.Paths(); len(paths) == 1 {
+					results[i].Output = paths[0] // The collision-free path actually written
+				}
+			}
+		}()
+	}
+
+schedule:
+	for i, cfg := range configs {
+		if cfg == nil {
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break schedule
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
+
+// Summary counts results by status.
+func Summary(results []Result) (ok, failed, skipped int) {
+	for _, r := range results {
+		switch r.Status {
+		case StatusOK:
+			ok++
+		case StatusFailed:
+			failed++
+		case StatusSkipped:
+			skipped++
+		}
+	}
+	return ok, failed, skipped
+}
+
+// Content returns the encoded content for a row, applying the template
+// named in its "type" column (url by default).
+func Content(row Row) (string, error) {
+	ct := templates.ContentURL
+	if name := row.Fields["type"]; name != "" {
+		var err error
+		if ct, err = templates.ParseContentType(name); err != nil {
+			return "", err
+		}
+	}
+	return templates.FromFields(ct, row.Fields)
+}
+
+// rowConfig builds the QR configuration for a single row. seen holds the
+// output paths claimed by earlier rows, which {n} skips.
+func rowConfig(row Row, opts Options, now time.Time, seen map[string]int) (*config.QRConfig, error) {
+	cfg := opts.Defaults
+	fields := row.Fields
+
+	content, err := Content(row)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Content = content
+
+	if v := fields["format"]; v != "" {
+		cfg.SetFormats(config.ParseFormats(v))
+	}
+	if v := fields["size"]; v != "" {
+		sizes, err := config.ParseSizes(v)
+		if err != nil {
+			return nil, err
+		}
+		cfg.SetSizes(sizes)
+	}
+	if v := fields["dpi"]; v != "" {
+		if cfg.DPI, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid DPI %q", v)
+		}
+	}
+	if v := fields["alt"]; v != "" {
+		cfg.Alt = v
+	}
+	if v := fields["fg"]; v != "" {
+		if cfg.Foreground, err = config.ParseHexColor(v); err != nil {
+			return nil, fmt.Errorf("invalid foreground color: %w", err)
+		}
+	}
+	if v := fields["bg"]; v != "" {
+		if cfg.Background, err = config.ParseHexColor(v); err != nil {
+			return nil, fmt.Errorf("invalid background color: %w", err)
+		}
+	}
+	if v := fields["ec"]; v != "" {
+		if cfg.Level, err = config.ParseErrorCorrection(v); err != nil {
+			return nil, err
+		}
+	}
+	ct := templates.DetectType(content)
+	required := templates.RequiredErrorCorrection(ct)
+	if err := cfg.RequireLevel(config.ErrorCorrection(required), fields["ec"] != ""); err != nil {
+		return nil, err
+	}
+	cfg.SwissCross = cfg.SwissCross || templates.RequiresSwissCross(ct)
+	if v := fields["renderer"]; v != "" {
+		cfg.Renderer = config.TextRenderer(strings.ToLower(v))
+	}
+	if v := fields["invert"]; v != "" {
+		if cfg.Invert, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid invert value %q", v)
+		}
+	}
+
+	output := fields["output"]
+	if output == "" {
+		output = opts.Pattern
+	} else if err := checkPattern(output); err != nil {
+		return nil, err
+	}
+	vars := filenameVars(row, content, ct, now)
+	vars.Claimed = func(path string) bool {
+		_, ok := seen[path]
+		return ok
+	}
+	if p := config.UnknownPlaceholder(output, vars); p != "" {
+		return nil, fmt.Errorf("filename pattern: no column for %s", p)
+	}
+	if strings.TrimSpace(config.ExpandFilename(output, vars)) == "" {
+		return nil, fmt.Errorf("filename pattern produced an empty name")
+	}
+	if !filepath.IsAbs(output) && opts.OutputDir != "" {
+		output = filepath.Join(opts.OutputDir, output)
+	}
+	cfg.SetOutputPattern(output, vars)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// checkPattern rejects the {{.column}} syntax that batch patterns used to
+// accept; it would otherwise end up literally in every file name.
+func checkPattern(pattern string) error {
+	if strings.Contains(pattern, "{{") {
+		return fmt.Errorf("filename pattern %q: {{.column}} is no longer supported, use {column} instead", pattern)
+	}
+	return nil
+}
+
+// firstDuplicate returns the row that already claimed one of paths.
+func firstDuplicate(seen map[string]int, paths []string) (int, bool) {
+	for _, path := range paths {
+		if row, ok := seen[path]; ok {
+			return row, true
+		}
+	}
+	return 0, false
+}
+
+// filenameVars returns the placeholders for a row's filename pattern: the
+// built-in ones, {row} and one per column. Column values are sanitized so
+// that a field cannot introduce path separators.
+func filenameVars(row Row, content string, ct templates.ContentType, now time.Time) config.FilenameVars {
+	fields := make(map[string]string, len(row.Fields)+1)
+	for k, v := range row.Fields {
+		fields[k] = sanitizeFilename(v)
+	}
+	if _, ok := fields["row"]; !ok {
+		fields["row"] = strconv.Itoa(row.Index)
+	}
+	return config.FilenameVars{
+		Content: templates.RedactSecrets(content), // Keep secrets out of {slug} filenames
+		Type:    templates.TypeName(ct),
+		Time:    now,
+		Fields:  fields,
+	}
+}
+
+// sanitizeFilename replaces characters that are unsafe in file names.
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 0x20 {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+}
+
+func hasFailure(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
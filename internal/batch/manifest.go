@@ -0,0 +1,125 @@
+package batch
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Row is a single manifest entry: a flat map of lower-case field names to
+// values, along with its 1-based position in the manifest.
+type Row struct {
+	Index  int
+	Fields map[string]string
+}
+
+// ReadManifest reads a CSV or JSON Lines manifest. The format is chosen by
+// file extension: .csv for CSV, .jsonl/.ndjson for JSON Lines.
+func ReadManifest(path string) ([]Row, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ParseCSV(f)
+	case ".jsonl", ".ndjson":
+		return ParseJSONL(f)
+	default:
+		return nil, fmt.Errorf("unsupported manifest type %q (expected .csv, .jsonl or .ndjson)", filepath.Ext(path))
+	}
+}
+
+// ParseCSV parses a CSV manifest. The first record is the header naming
+// each column; every following record becomes a Row.
+func ParseCSV(r io.Reader) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("manifest is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i, name := range header {
+		header[i] = normalizeKey(name)
+	}
+
+	var rows []Row
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		fields := make(map[string]string, len(header))
+		for i, value := range record {
+			fields[header[i]] = value
+		}
+		rows = append(rows, Row{Index: len(rows) + 1, Fields: fields})
+	}
+
+	return rows, nil
+}
+
+// ParseJSONL parses a JSON Lines manifest. Each non-blank line must be a JSON
+// object; strings, numbers and booleans are converted to their text form.
+func ParseJSONL(r io.Reader) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []Row
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(text), &obj); err != nil {
+			return nil, fmt.Errorf("line %d: invalid JSON: %w", line, err)
+		}
+
+		fields := make(map[string]string, len(obj))
+		for key, value := range obj {
+			switch v := value.(type) {
+			case nil:
+				continue
+			case string:
+				fields[normalizeKey(key)] = v
+			case float64, bool:
+				fields[normalizeKey(key)] = fmt.Sprint(v)
+			default:
+				return nil, fmt.Errorf("line %d: field %q must be a string, number or boolean", line, key)
+			}
+		}
+		rows = append(rows, Row{Index: len(rows) + 1, Fields: fields})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	return rows, nil
+}
+
+// normalizeKey lower-cases a column name and replaces spaces and dashes with
+// underscores, so "First Name" and "first-name" both become "first_name".
+func normalizeKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	key = strings.TrimPrefix(key, "\ufeff") // UTF-8 BOM from spreadsheet exports
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// AnimationMode selects how an animated GIF changes from frame to frame.
+// Every mode keeps each frame a complete, scannable code.
+type AnimationMode string
+
+const (
+	AnimationNone       AnimationMode = ""            // A single still frame
+	AnimationColorCycle AnimationMode = "color-cycle" // The foreground cycles through colors
+	AnimationReveal     AnimationMode = "reveal"      // The foreground sweeps across a muted code
+	AnimationPayloads   AnimationMode = "payloads"    // Each frame encodes the next payload
+)
+
+// AnimationModes returns all animation modes.
+func AnimationModes() []AnimationMode {
+	return []AnimationMode{AnimationColorCycle, AnimationReveal, AnimationPayloads}
+}
+
+// ParseAnimationMode parses a mode name. "none" and "" disable animation.
+func ParseAnimationMode(s string) (AnimationMode, error) {
+	switch m := AnimationMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "none", AnimationNone:
+		return AnimationNone, nil
+	case AnimationColorCycle, AnimationReveal, AnimationPayloads:
+		return m, nil
+	case "cycle", "colors":
+		return AnimationColorCycle, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidAnimation, s)
+}
+
+// Animation defaults and limits.
+const (
+	DefaultAnimationFrames = 12
+	MaxAnimationFrames     = 120
+
+	// MinAnimationContrast is the WCAG contrast ratio every frame's
+	// foreground must keep against the background. At 4.5:1 a dark color
+	// on white stays below mid-gray, where scanners threshold.
+	MinAnimationContrast = 4.5
+
+	// revealMix is how far the muted reveal color is blended towards the
+	// background.
+	revealMix = 0.25
+)
+
+// Animation errors returned by QRConfig.Validate.
+var (
+	ErrInvalidAnimation  = errors.New("animation must be color-cycle, reveal or payloads")
+	ErrAnimationFormat   = errors.New("animation requires gif output")
+	ErrAnimationFrames   = errors.New("animation frames must be between 2 and 120")
+	ErrAnimationDelay    = errors.New("frame delay must be between 20 and 60000 milliseconds")
+	ErrAnimationLoop     = errors.New("loop count cannot be negative")
+	ErrAnimationPayloads = errors.New("payload animation needs at least one extra payload")
+	ErrAnimationContrast = errors.New("animation color lacks contrast with the background")
+	ErrAnimationHalftone = errors.New("halftone mode cannot be animated")
+)
+
+// Animation configures animated GIF output.
+type Animation struct {
+	Mode AnimationMode
+
+	Frames int // Frames per loop for color-cycle and reveal (0 means 12)
+	Delay  int // Milliseconds each frame is shown (0 picks a per-mode default)
+	Loop   int // Times to play the animation (0 loops forever)
+
+	// Payloads are shown after Content in payloads mode, one per frame.
+	Payloads []string
+
+	// Colors are cycled through in color-cycle mode. When empty the
+	// foreground's hue is rotated around the color wheel.
+	Colors []color.RGBA
+}
+
+// FrameCount returns the number of frames per loop. Payload animations
+// show one frame per content: Content, then each of Payloads.
+func (a Animation) FrameCount() int {
+	switch a.Mode {
+	case AnimationNone:
+		return 1
+	case AnimationPayloads:
+		return 1 + len(a.Payloads)
+	}
+	if a.Frames == 0 {
+		return DefaultAnimationFrames
+	}
+	return a.Frames
+}
+
+// FrameDelay returns the delay between frames in milliseconds. Payloads
+// stay up long enough for a phone to lock on; color changes run faster.
+func (a Animation) FrameDelay() int {
+	if a.Delay != 0 {
+		return a.Delay
+	}
+	if a.Mode == AnimationPayloads {
+		return 2000
+	}
+	return 100
+}
+
+// validate checks the animation against the rest of the configuration.
+func (a Animation) validate(c *QRConfig) error {
+	if a.Mode == AnimationNone {
+		return nil
+	}
+	if _, err := ParseAnimationMode(string(a.Mode)); err != nil {
+		return err
+	}
+	if !c.hasFormat(FormatGIF) {
+		return ErrAnimationFormat
+	}
+	if c.Halftone != "" {
+		return ErrAnimationHalftone
+	}
+	if a.Frames != 0 && (a.Frames < 2 || a.Frames > MaxAnimationFrames) {
+		return ErrAnimationFrames
+	}
+	if a.Delay != 0 && (a.Delay < 20 || a.Delay > 60000) {
+		return ErrAnimationDelay
+	}
+	if a.Loop < 0 {
+		return ErrAnimationLoop
+	}
+	if a.Mode == AnimationPayloads {
+		if len(a.Payloads) == 0 {
+			return ErrAnimationPayloads
+		}
+		for i, p := range a.Payloads {
+			if p == "" {
+				return fmt.Errorf("%w: payload %d", ErrEmptyContent, i+2)
+			}
+		}
+	}
+	var colors []color.RGBA
+	switch a.Mode {
+	case AnimationColorCycle:
+		colors = a.CycleColors(c.Foreground)
+	case AnimationReveal:
+		colors = []color.RGBA{c.Foreground, RevealColor(c.Foreground, c.Background)}
+	default:
+		colors = []color.RGBA{c.Foreground}
+	}
+	for _, fg := range colors {
+		if ratio := ContrastRatio(fg, c.Background); ratio < MinAnimationContrast {
+			return fmt.Errorf("%w: %s is %.1f:1, need %.1f:1",
+				ErrAnimationContrast, ColorToHex(fg), ratio, MinAnimationContrast)
+		}
+	}
+	return nil
+}
+
+// CycleColors returns the foreground of each color-cycle frame. Frames
+// blend evenly through Colors and back to the first, or rotate the hue of
+// fg once around the color wheel when no colors are set.
+func (a Animation) CycleColors(fg color.RGBA) []color.RGBA {
+	n := a.FrameCount()
+	colors := make([]color.RGBA, n)
+	for i := range colors {
+		t := float64(i) / float64(n)
+		if len(a.Colors) == 0 {
+			colors[i] = ShiftHue(fg, 360*t)
+			continue
+		}
+		pos := t * float64(len(a.Colors))
+		j := int(pos)
+		colors[i] = MixColors(a.Colors[j], a.Colors[(j+1)%len(a.Colors)], pos-float64(j))
+	}
+	return colors
+}
+
+// RevealColor is the muted foreground a reveal animation starts from.
+func RevealColor(fg, bg color.RGBA) color.RGBA {
+	return MixColors(fg, bg, revealMix)
+}
+
+// MixColors blends a towards b; t = 0 returns a and t = 1 returns b.
+func MixColors(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
+
+// ShiftHue rotates c around the color wheel by degrees, keeping its
+// lightness. Grays have no hue, so they are given a saturated hue of
+// similar lightness first.
+func ShiftHue(c color.RGBA, degrees float64) color.RGBA {
+	h, s, l := rgbToHSL(c)
+	if s < 0.1 {
+		s = 0.75
+		l = math.Max(0.2, math.Min(l, 0.8))
+	}
+	return hslToRGB(math.Mod(h+degrees+360, 360), s, l)
+}
+
+func rgbToHSL(c color.RGBA) (h, s, l float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	hi, lo := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l = (hi + lo) / 2
+	if hi == lo {
+		return 0, 0, l
+	}
+	d := hi - lo
+	if l > 0.5 {
+		s = d / (2 - hi - lo)
+	} else {
+		s = d / (hi + lo)
+	}
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/d+6, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h * 60, s, l
+}
+
+func hslToRGB(h, s, l float64) color.RGBA {
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g = chroma, x
+	case h < 120:
+		r, g = x, chroma
+	case h < 180:
+		g, b = chroma, x
+	case h < 240:
+		g, b = x, chroma
+	case h < 300:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+	m := l - chroma/2
+	to := func(v float64) uint8 { return uint8(math.Round((v + m) * 255)) }
+	return color.RGBA{R: to(r), G: to(g), B: to(b), A: 255}
+}
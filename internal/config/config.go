@@ -2,6 +2,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
 	"path/filepath"
@@ -13,8 +14,94 @@ import (
 type OutputFormat string
 
 const (
-	FormatPNG OutputFormat = "png"
-	FormatSVG OutputFormat = "svg"
+	FormatPNG  OutputFormat = "png"
+	FormatJPEG OutputFormat = "jpg"
+	FormatSVG  OutputFormat = "svg"
+	FormatText OutputFormat = "txt"
+	FormatGIF  OutputFormat = "gif"
+
+	// Embedding formats wrap an image (see QRConfig.Embed) for pasting
+	// into other documents.
+	FormatHTML     OutputFormat = "html"    // <img> with a data URI, or inline <svg>
+	FormatDataURI  OutputFormat = "datauri" // A bare data: URI
+	FormatMarkdown OutputFormat = "md"      // ![alt](data:...) image
+)
+
+// ErrorCorrection is the QR error correction level. Higher levels survive
+// more damage at the cost of a denser symbol.
+type ErrorCorrection string
+
+const (
+	ECLow      ErrorCorrection = "L" // ~7% recovery
+	ECMedium   ErrorCorrection = "M" // ~15% recovery
+	ECQuartile ErrorCorrection = "Q" // ~25% recovery
+	ECHigh     ErrorCorrection = "H" // ~30% recovery
+)
+
+// ParseErrorCorrection parses a level name such as "M" or "high".
+func ParseErrorCorrection(s string) (ErrorCorrection, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "L", "LOW":
+		return ECLow, nil
+	case "M", "MEDIUM":
+		return ECMedium, nil
+	case "Q", "QUARTILE":
+		return ECQuartile, nil
+	case "H", "HIGH":
+		return ECHigh, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidErrorCorrection, s)
+}
+
+// RequireLevel switches to the error correction level a content type
+// mandates, such as M for EPC payment codes. An empty level is a no-op.
+// When the current level was chosen explicitly and differs, it returns an
+// error rather than silently overriding the choice.
+func (c *QRConfig) RequireLevel(level ErrorCorrection, explicit bool) error {
+	if level == "" {
+		return nil
+	}
+	if explicit && c.Level != "" && c.Level != level {
+		return fmt.Errorf("%w: this content requires %s, not %s", ErrRequiredLevel, level, c.Level)
+	}
+	c.Level = level
+	return nil
+}
+
+// StdoutPath is the special output path that streams the result to stdout.
+const StdoutPath = "-"
+
+// TextRenderer selects how QR modules are drawn as plain text.
+type TextRenderer string
+
+const (
+	RendererANSI     TextRenderer = "ansi"     // Half blocks with ANSI colors
+	RendererASCII    TextRenderer = "ascii"    // "##" and spaces, no escape codes
+	RendererBlocks   TextRenderer = "blocks"   // Unicode full blocks, no color
+	RendererQuadrant TextRenderer = "quadrant" // Unicode quadrant blocks (2x2 modules per cell)
+	RendererBraille  TextRenderer = "braille"  // Braille patterns (2x4 modules per cell)
+)
+
+// TextRenderers returns all available text renderers.
+func TextRenderers() []TextRenderer {
+	return []TextRenderer{RendererANSI, RendererASCII, RendererBlocks, RendererQuadrant, RendererBraille}
+}
+
+// Validation errors returned by QRConfig.Validate. Callers can match them
+// with errors.Is; renderer errors are wrapped with additional detail.
+var (
+	ErrEmptyContent    = errors.New("content cannot be empty")
+	ErrInvalidSize     = errors.New("size must be between 64 and 4096 pixels")
+	ErrInvalidFormat   = errors.New("format must be 'png', 'jpg', 'gif', 'svg', 'txt', 'html', 'datauri' or 'md'")
+	ErrInvalidDPI      = errors.New("DPI must be between 72 and 2400")
+	ErrHalftoneFormat  = errors.New("halftone mode requires png, jpg or gif output")
+	ErrInvalidEmbed    = errors.New("embedded format must be 'png', 'jpg', 'gif' or 'svg'")
+	ErrInvalidRenderer = errors.New("unknown text renderer")
+	ErrEmptyOutputPath = errors.New("output path cannot be empty")
+
+	ErrInvalidErrorCorrection = errors.New("error correction must be L, M, Q or H")
+	ErrRequiredLevel          = errors.New("error correction level is fixed for this content type")
+	ErrSwissCrossAnimation    = errors.New("the Swiss cross cannot be combined with animation")
 )
 
 // QRConfig holds all configuration options for QR code generation.
@@ -24,7 +111,42 @@ type QRConfig struct {
 	Size       int          // Dimensions in pixels (width = height)
 	Foreground color.RGBA   // QR code color
 	Background color.RGBA   // Background color
-	OutputPath string       // Where to save the file
+	OutputPath string       // Where to save the file ("-" for stdout)
+
+	OnCollision CollisionPolicy // What to do if OutputPath exists (empty means overwrite)
+
+	// Formats lists every format to write in one generation, starting with
+	// Format; Sizes lists the pixel sizes for raster formats, starting with
+	// Size. Both are optional; use SetFormats and SetSizes to keep them in
+	// step with Format and Size.
+	Formats []OutputFormat
+	Sizes   []int
+
+	Level ErrorCorrection // Error correction level (empty means Medium)
+
+	DPI int // Print resolution stored in PNG and JPEG metadata (0 omits it)
+
+	Halftone string // Background image blended into raster output (empty disables)
+
+	Animation Animation // Animated GIF options (gif format only)
+
+	Embed OutputFormat // Image inside html, datauri and md output (empty means png)
+	Alt   string       // Alt text for html and md output (empty means "QR code")
+
+	Renderer TextRenderer // Text renderer (txt format only)
+	Invert   bool         // Swap dark and light modules in text output
+
+	SwissCross bool // Overlay the Swiss QR-bill cross (image formats only)
+
+	// Styling for image output; see Styled.
+	Gradient    color.RGBA  // End color of a diagonal foreground gradient (zero disables)
+	ModuleStyle ModuleStyle // Shape of data modules (empty means square)
+	Logo        string      // Image drawn in the center of the code (empty disables)
+	Frame       int         // Width of a foreground border around the quiet zone, in modules
+
+	// resolved maps the paths Outputs would use to the collision-free paths
+	// chosen by ResolveCollisions.
+	resolved map[string]string
 }
 
 // DefaultConfig returns a QRConfig with sensible defaults.
@@ -36,28 +158,82 @@ func DefaultConfig() *QRConfig {
 		Foreground: color.RGBA{R: 0, G: 0, B: 0, A: 255},       // Black
 		Background: color.RGBA{R: 255, G: 255, B: 255, A: 255}, // White
 		OutputPath: "qrcode.png",
+		Level:      ECMedium,
+		Renderer:   RendererASCII,
 	}
 }
 
-// Validate checks if the configuration is valid.
+// Validate checks if the configuration is valid for writing to OutputPath.
 func (c *QRConfig) Validate() error {
+	if err := c.ValidateOptions(); err != nil {
+		return err
+	}
+	if c.OutputPath == "" {
+		return ErrEmptyOutputPath
+	}
+	return nil
+}
+
+// ValidateOptions checks the encoding and rendering options, ignoring the
+// output path. Use it when rendering to an io.Writer rather than a file.
+func (c *QRConfig) ValidateOptions() error {
 	if c.Content == "" {
-		return fmt.Errorf("content cannot be empty")
+		return ErrEmptyContent
 	}
 	if c.Size < 64 || c.Size > 4096 {
-		return fmt.Errorf("size must be between 64 and 4096 pixels")
+		return ErrInvalidSize
 	}
-	if c.Format != FormatPNG && c.Format != FormatSVG {
-		return fmt.Errorf("format must be 'png' or 'svg'")
+	if !isOutputFormat(c.Format) {
+		return ErrInvalidFormat
 	}
-	if c.OutputPath == "" {
-		return fmt.Errorf("output path cannot be empty")
+	for _, f := range c.Formats {
+		if !isOutputFormat(f) {
+			return fmt.Errorf("%w: %s", ErrInvalidFormat, f)
+		}
+	}
+	for _, size := range c.Sizes {
+		if size < 64 || size > 4096 {
+			return ErrInvalidSize
+		}
+	}
+	if c.DPI != 0 && (c.DPI < 72 || c.DPI > 2400) {
+		return ErrInvalidDPI
+	}
+	if c.Halftone != "" && !c.hasRasterFormat() {
+		return ErrHalftoneFormat
+	}
+	if c.Embed != "" && !isEmbeddable(c.Embed) {
+		return fmt.Errorf("%w: %s", ErrInvalidEmbed, c.Embed)
+	}
+	if err := c.Animation.validate(c); err != nil {
+		return err
+	}
+	if c.SwissCross && c.Animation.Mode != AnimationNone {
+		return ErrSwissCrossAnimation
+	}
+	if c.Level != "" {
+		if _, err := ParseErrorCorrection(string(c.Level)); err != nil {
+			return err
+		}
+	}
+	if c.Renderer != "" && !isTextRenderer(c.Renderer) {
+		return fmt.Errorf("%w: %s", ErrInvalidRenderer, c.Renderer)
+	}
+	if err := c.validateStyle(); err != nil {
+		return err
 	}
 	return nil
 }
 
 // SetOutputPath sets the output path with the correct extension.
+// The special path "-" (StdoutPath) is kept as-is.
 func (c *QRConfig) SetOutputPath(path string) {
+	c.resolved = nil
+	if path == StdoutPath {
+		c.OutputPath = path
+		return
+	}
+
 	ext := filepath.Ext(path)
 	if ext == "" {
 		path = path + "." + string(c.Format)
@@ -68,6 +244,70 @@ func (c *QRConfig) SetOutputPath(path string) {
 	c.OutputPath = path
 }
 
+// hasFormat reports whether f is one of the configured formats, directly
+// or embedded in html, datauri or md output.
+func (c *QRConfig) hasFormat(f OutputFormat) bool {
+	formats := append([]OutputFormat{c.Format}, c.Formats...)
+	for _, other := range formats {
+		if other == f || (other.IsEmbedding() && c.EmbedFormat() == f) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRasterFormat reports whether any configured format is a raster image,
+// directly or embedded in html, datauri or md output.
+func (c *QRConfig) hasRasterFormat() bool {
+	formats := append([]OutputFormat{c.Format}, c.Formats...)
+	for _, f := range formats {
+		if f.IsRaster() || (f.IsEmbedding() && c.EmbedFormat().IsRaster()) {
+			return true
+		}
+	}
+	return false
+}
+
+func isOutputFormat(f OutputFormat) bool {
+	switch f {
+	case FormatPNG, FormatJPEG, FormatGIF, FormatSVG, FormatText, FormatHTML, FormatDataURI, FormatMarkdown:
+		return true
+	}
+	return false
+}
+
+// isEmbeddable reports whether f can be embedded in html, datauri and md
+// output.
+func isEmbeddable(f OutputFormat) bool {
+	return f == FormatPNG || f == FormatJPEG || f == FormatGIF || f == FormatSVG
+}
+
+// EmbedFormat returns the image format wrapped by html, datauri and md
+// output.
+func (c *QRConfig) EmbedFormat() OutputFormat {
+	if c.Embed == "" {
+		return FormatPNG
+	}
+	return c.Embed
+}
+
+// AltText returns the alt text for html and md output.
+func (c *QRConfig) AltText() string {
+	if c.Alt == "" {
+		return "QR code"
+	}
+	return c.Alt
+}
+
+func isTextRenderer(r TextRenderer) bool {
+	for _, known := range TextRenderers() {
+		if r == known {
+			return true
+		}
+	}
+	return false
+}
+
 // ParseHexColor converts a hex color string to color.RGBA.
 func ParseHexColor(hex string) (color.RGBA, error) {
 	hex = strings.TrimPrefix(hex, "#")
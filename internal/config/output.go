@@ -0,0 +1,416 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CollisionPolicy decides what happens when the output file already exists.
+type CollisionPolicy string
+
+const (
+	CollisionOverwrite CollisionPolicy = "overwrite" // Replace the existing file
+	CollisionIncrement CollisionPolicy = "increment" // Write to name-2.png, name-3.png, ...
+	CollisionPrompt    CollisionPolicy = "prompt"    // Ask the user; fail when nobody can answer
+	CollisionFail      CollisionPolicy = "fail"      // Refuse to write
+)
+
+// ErrOutputExists is returned when the output file exists and the collision
+// policy does not allow replacing it.
+var ErrOutputExists = errors.New("output file already exists")
+
+// CollisionPolicies returns all collision policies.
+func CollisionPolicies() []CollisionPolicy {
+	return []CollisionPolicy{CollisionOverwrite, CollisionIncrement, CollisionPrompt, CollisionFail}
+}
+
+// ParseCollisionPolicy parses a policy name. "auto-increment" is accepted as
+// an alias for increment.
+func ParseCollisionPolicy(s string) (CollisionPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "overwrite":
+		return CollisionOverwrite, nil
+	case "increment", "auto-increment":
+		return CollisionIncrement, nil
+	case "prompt":
+		return CollisionPrompt, nil
+	case "fail":
+		return CollisionFail, nil
+	}
+	return "", fmt.Errorf("collision policy must be overwrite, increment, prompt or fail: %s", s)
+}
+
+// ResolveCollisions applies OnCollision to the files the configuration
+// would write. With the increment policy every output gets a "-2", "-3",
+// ... suffix so that none of them exist, and Outputs returns those exact
+// paths from then on; with fail or prompt an error wrapping
+// ErrOutputExists is returned. Interactive callers ask before generating
+// and switch to overwrite if the user agrees.
+func (c *QRConfig) ResolveCollisions() error {
+	c.resolved = nil
+	if c.OutputPath == StdoutPath {
+		return nil
+	}
+
+	outputs := c.OutputPaths()
+	paths, err := ResolveOutputPaths(outputs, c.OnCollision)
+	if err != nil {
+		return err
+	}
+	for i, path := range paths {
+		if path != outputs[i] {
+			if c.resolved == nil {
+				c.resolved = make(map[string]string)
+			}
+			c.resolved[outputs[i]] = path
+		}
+	}
+	return nil
+}
+
+// ExistingOutput returns the first output file that already exists, or "".
+func (c *QRConfig) ExistingOutput() string {
+	if c.OutputPath == StdoutPath {
+		return ""
+	}
+	return firstExisting(c.OutputPaths())
+}
+
+// ResolveOutputPaths applies the collision policy to a group of files
+// written together, such as the PNG and SVG of one code. With the increment
+// policy every path gets the same suffix, so the group stays recognizable.
+// The prompt policy behaves like fail, and an empty policy means overwrite.
+func ResolveOutputPaths(paths []string, policy CollisionPolicy) ([]string, error) {
+	if policy == "" || policy == CollisionOverwrite {
+		return paths, nil
+	}
+	existing := firstExisting(paths)
+	if existing == "" {
+		return paths, nil
+	}
+
+	if policy == CollisionIncrement {
+		for n := 2; ; n++ {
+			candidates := make([]string, len(paths))
+			for i, path := range paths {
+				ext := filepath.Ext(path)
+				candidates[i] = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), n, ext)
+			}
+			if firstExisting(candidates) == "" {
+				return candidates, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrOutputExists, existing)
+}
+
+// Output is a single file produced by a generation.
+type Output struct {
+	Format OutputFormat
+	Size   int
+	Path   string
+}
+
+// Outputs lists the files a generation writes: one per format, and one per
+// size for raster formats. Paths share OutputPath's base name; raster files
+// get a "-<size>" suffix when several sizes are requested, followed by any
+// suffix ResolveCollisions added.
+func (c *QRConfig) Outputs() []Output {
+	if c.OutputPath == StdoutPath {
+		return []Output{{Format: c.Format, Size: c.Size, Path: StdoutPath}}
+	}
+
+	formats := c.Formats
+	if len(formats) == 0 {
+		formats = []OutputFormat{c.Format}
+	}
+	sizes := c.Sizes
+	if len(sizes) == 0 {
+		sizes = []int{c.Size}
+	}
+	base := strings.TrimSuffix(c.OutputPath, filepath.Ext(c.OutputPath))
+
+	var outputs []Output
+	for _, f := range formats {
+		if !f.IsRaster() || len(sizes) == 1 {
+			outputs = append(outputs, Output{Format: f, Size: c.Size, Path: c.resolvedPath(base + "." + string(f))})
+			continue
+		}
+		for _, size := range sizes {
+			path := fmt.Sprintf("%s-%d.%s", base, size, f)
+			outputs = append(outputs, Output{Format: f, Size: size, Path: c.resolvedPath(path)})
+		}
+	}
+	return outputs
+}
+
+// resolvedPath returns the collision-free path chosen for path, if any.
+func (c *QRConfig) resolvedPath(path string) string {
+	if p, ok := c.resolved[path]; ok {
+		return p
+	}
+	return path
+}
+
+// OutputPaths returns the paths of Outputs.
+func (c *QRConfig) OutputPaths() []string {
+	outputs := c.Outputs()
+	paths := make([]string, len(outputs))
+	for i, o := range outputs {
+		paths[i] = o.Path
+	}
+	return paths
+}
+
+// IsRaster reports whether the format is a pixel image whose size matters.
+func (f OutputFormat) IsRaster() bool {
+	return f == FormatPNG || f == FormatJPEG || f == FormatGIF
+}
+
+// IsEmbedding reports whether the format wraps an image for embedding in
+// another document (see QRConfig.Embed).
+func (f OutputFormat) IsEmbedding() bool {
+	return f == FormatHTML || f == FormatDataURI || f == FormatMarkdown
+}
+
+// MIMEType returns the media type of the format's output.
+func (f OutputFormat) MIMEType() string {
+	switch f {
+	case FormatJPEG:
+		return "image/jpeg"
+	case FormatGIF:
+		return "image/gif"
+	case FormatSVG:
+		return "image/svg+xml"
+	case FormatText, FormatDataURI:
+		return "text/plain; charset=utf-8"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "image/png"
+	}
+}
+
+// ParseFormat normalizes a format name. "jpeg", "htm", "data-uri" and
+// "markdown" are accepted as aliases. Validation is left to Validate.
+func ParseFormat(s string) OutputFormat {
+	f := OutputFormat(strings.ToLower(strings.TrimSpace(s)))
+	switch f {
+	case "jpeg":
+		return FormatJPEG
+	case "htm":
+		return FormatHTML
+	case "data-uri", "uri":
+		return FormatDataURI
+	case "markdown":
+		return FormatMarkdown
+	}
+	return f
+}
+
+// ParseFormats parses a comma-separated list of formats such as "png,svg".
+// Duplicates are dropped; validation is left to Validate.
+func ParseFormats(s string) []OutputFormat {
+	var formats []OutputFormat
+	seen := make(map[OutputFormat]bool)
+	for _, part := range strings.Split(s, ",") {
+		f := ParseFormat(part)
+		if f == "" || seen[f] {
+			continue
+		}
+		seen[f] = true
+		formats = append(formats, f)
+	}
+	return formats
+}
+
+// ParseSizes parses a comma-separated list of pixel sizes such as
+// "256,1024".
+func ParseSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		size, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size %q", part)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// SetFormats sets the formats to write. The first one becomes Format.
+func (c *QRConfig) SetFormats(formats []OutputFormat) {
+	if len(formats) == 0 {
+		return
+	}
+	c.Format = formats[0]
+	c.Formats = nil
+	if len(formats) > 1 {
+		c.Formats = formats
+	}
+}
+
+// SetSizes sets the raster sizes to write. The first one becomes Size.
+func (c *QRConfig) SetSizes(sizes []int) {
+	if len(sizes) == 0 {
+		return
+	}
+	c.Size = sizes[0]
+	c.Sizes = nil
+	if len(sizes) > 1 {
+		c.Sizes = sizes
+	}
+}
+
+// FilenameVars holds the values substituted into output filename patterns.
+type FilenameVars struct {
+	Content string    // Encoded content, used for {slug} and {hash}
+	Type    string    // Content type name, e.g. "wifi"
+	Time    time.Time // Generation time, used for {date}
+
+	// Fields are extra placeholders, such as batch manifest columns:
+	// {id} is replaced with Fields["id"]. They cannot override the
+	// built-in placeholders.
+	Fields map[string]string
+
+	// Claimed reports whether a path is already taken by a file that is
+	// about to be written, such as an earlier batch row's output. {n} skips
+	// numbers whose paths are claimed. Nil means no path is claimed.
+	Claimed func(path string) bool
+}
+
+// placeholderPattern matches a {name} placeholder in a filename pattern.
+var placeholderPattern = regexp.MustCompile(`\{([a-z0-9_]+)\}`)
+
+// ExpandFilename replaces {date}, {type}, {slug}, {hash} and the names in
+// vars.Fields in pattern. {n} is left in place; see SetOutputPattern.
+func ExpandFilename(pattern string, vars FilenameVars) string {
+	if !strings.Contains(pattern, "{") {
+		return pattern
+	}
+
+	typ := vars.Type
+	if typ == "" {
+		typ = "text"
+	}
+	sum := sha256.Sum256([]byte(vars.Content))
+
+	pairs := []string{
+		"{date}", vars.Time.Format("2006-01-02"),
+		"{type}", typ,
+		"{slug}", Slugify(vars.Content),
+		"{hash}", hex.EncodeToString(sum[:])[:8],
+		"{n}", "{n}",
+	}
+	for name, value := range vars.Fields {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(pattern)
+}
+
+// UnknownPlaceholder returns the first {name} in pattern that is neither a
+// built-in placeholder nor one of vars.Fields, or "" if there is none.
+func UnknownPlaceholder(pattern string, vars FilenameVars) string {
+	for _, m := range placeholderPattern.FindAllStringSubmatch(pattern, -1) {
+		switch m[1] {
+		case "date", "type", "slug", "hash", "n":
+			continue
+		}
+		if _, ok := vars.Fields[m[1]]; !ok {
+			return m[0]
+		}
+	}
+	return ""
+}
+
+// SetOutputPattern expands a filename pattern and sets it as the output
+// path with the correct extension. {n} is replaced with the smallest
+// positive number for which none of the output files exist yet or are
+// claimed (see FilenameVars.Claimed). Set the formats and sizes first.
+func (c *QRConfig) SetOutputPattern(pattern string, vars FilenameVars) {
+	c.SetOutputPath(ExpandFilename(pattern, vars))
+	if !strings.Contains(c.OutputPath, "{n}") {
+		return
+	}
+
+	template := c.OutputPath
+	for n := 1; ; n++ {
+		c.OutputPath = strings.ReplaceAll(template, "{n}", strconv.Itoa(n))
+		if c.ExistingOutput() == "" && !vars.claimed(c.OutputPaths()) {
+			return
+		}
+	}
+}
+
+// claimed reports whether any of paths is claimed.
+func (v FilenameVars) claimed(paths []string) bool {
+	if v.Claimed == nil {
+		return false
+	}
+	for _, path := range paths {
+		if v.Claimed(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Slugify turns content into a short, filename-safe slug: lowercase ASCII
+// letters and digits separated by dashes, at most 40 characters. URL
+// schemes are dropped so "https://example.com/a" becomes "example-com-a".
+func Slugify(content string) string {
+	if i := strings.Index(content, "://"); i > 0 && i < 10 {
+		content = content[i+3:]
+	}
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(content) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			if b.Len() >= 40 {
+				break
+			}
+			continue
+		}
+		dash = true
+	}
+
+	if b.Len() == 0 {
+		return "qrcode"
+	}
+	return b.String()
+}
+
+func firstExisting(paths []string) string {
+	for _, path := range paths {
+		if path != StdoutPath && fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	mmPerInch = 25.4
+
+	// DefaultScanDistance is the scanning distance assumed when none is
+	// given: a phone held at arm's length from a poster or label, in mm.
+	DefaultScanDistance = 300.0
+
+	// scanDistanceRatio is how many times the scanning distance may exceed
+	// the width of one module. It corresponds to the common "10:1 rule"
+	// (code width = distance / 10) for a typical 25-module code with its
+	// quiet zone.
+	scanDistanceRatio = 300.0
+
+	// minPrintModule is the smallest module in mm that common printers
+	// reproduce reliably, regardless of distance.
+	minPrintModule = 0.33
+
+	// SwissQRSymbolMM is the printed width of a Swiss QR-bill code without
+	// its quiet zone, and SwissCrossMM the width of the cross in its center.
+	SwissQRSymbolMM = 46.0
+	SwissCrossMM    = 7.0
+)
+
+// ParseLength parses a physical length such as "30mm", "2.5cm" or "1.5in"
+// and returns it in millimetres. A bare number is taken as millimetres.
+func ParseLength(s string) (float64, error) {
+	input := s
+	s = strings.ToLower(strings.TrimSpace(s))
+	unit := 1.0
+	for _, u := range []struct {
+		suffix string
+		mm     float64
+	}{{"mm", 1}, {"cm", 10}, {"in", mmPerInch}, {`"`, mmPerInch}, {"m", 1000}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.mm
+			break
+		}
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v <= 0 || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid length %q: use a positive number with mm, cm or in", input)
+	}
+	return v * unit, nil
+}
+
+// PixelsForLength returns the pixel count that prints mm millimetres wide
+// at the given DPI.
+func PixelsForLength(mm float64, dpi int) int {
+	return int(math.Round(mm / mmPerInch * float64(dpi)))
+}
+
+// SwissQRSize returns the pixel size at which a code of the given module
+// count (including the 4-module quiet zone on each side) prints with a
+// 46 mm symbol at dpi, as QR-bills require.
+func SwissQRSize(modules, dpi int) int {
+	return PixelsForLength(SwissQRSymbolMM*float64(modules)/float64(modules-8), dpi)
+}
+
+// LengthForPixels returns the printed width in mm of px pixels at the
+// given DPI.
+func LengthForPixels(px, dpi int) float64 {
+	return float64(px) / float64(dpi) * mmPerInch
+}
+
+// FormatLength formats a length in mm with its inch equivalent.
+func FormatLength(mm float64) string {
+	return fmt.Sprintf("%.1f mm (%.2f in)", mm, mm/mmPerInch)
+}
+
+// MinPrintSize returns the recommended minimum printed width in mm for a
+// code of the given module count (including the quiet zone) scanned from
+// distance mm away.
+func MinPrintSize(modules int, distance float64) float64 {
+	module := math.Max(distance/scanDistanceRatio, minPrintModule)
+	return module * float64(modules)
+}
+
+// PrintSize returns the printed width in mm of the configured Size at the
+// configured DPI, or 0 if no DPI is set.
+func (c *QRConfig) PrintSize() float64 {
+	if c.DPI == 0 {
+		return 0
+	}
+	return LengthForPixels(c.Size, c.DPI)
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// ModuleStyle is the shape data modules are drawn with in image output.
+// Finder patterns always stay square so scanners can locate the code.
+type ModuleStyle string
+
+const (
+	ModuleSquare  ModuleStyle = "square"  // Plain square modules
+	ModuleDots    ModuleStyle = "dots"    // Round dots
+	ModuleRounded ModuleStyle = "rounded" // Squares with rounded corners
+)
+
+// ModuleStyles returns all module styles.
+func ModuleStyles() []ModuleStyle {
+	return []ModuleStyle{ModuleSquare, ModuleDots, ModuleRounded}
+}
+
+// ParseModuleStyle parses a module style name. An empty name means square.
+func ParseModuleStyle(s string) (ModuleStyle, error) {
+	switch m := ModuleStyle(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return ModuleSquare, nil
+	case ModuleSquare, ModuleDots, ModuleRounded:
+		return m, nil
+	case "dot", "circle", "circles":
+		return ModuleDots, nil
+	case "round":
+		return ModuleRounded, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidModuleStyle, s)
+}
+
+const (
+	// MaxFrame is the widest frame around a code, in modules.
+	MaxFrame = 8
+
+	// MinGradientContrast is the lowest WCAG contrast ratio a gradient's
+	// end color may have against the background.
+	MinGradientContrast = 4.5
+)
+
+// Style errors returned by QRConfig.Validate.
+var (
+	ErrInvalidModuleStyle = errors.New("module style must be square, dots or rounded")
+	ErrInvalidFrame       = fmt.Errorf("frame must be between 0 and %d modules", MaxFrame)
+	ErrStyleAnimation     = errors.New("gradients, module styles, logos and frames cannot be animated")
+	ErrStyleHalftone      = errors.New("gradients, module styles, logos and frames cannot be combined with halftone mode")
+	ErrStyleSwissCross    = errors.New("Swiss QR-bills cannot have gradients, module styles, logos or frames")
+	ErrGradientContrast   = errors.New("gradient color lacks contrast with the background")
+)
+
+// Styled reports whether any image styling beyond plain colors is set:
+// a gradient, a module style other than square, a logo or a frame. Text
+// output ignores these options.
+func (c *QRConfig) Styled() bool {
+	return c.Gradient.A != 0 ||
+		(c.ModuleStyle != "" && c.ModuleStyle != ModuleSquare) ||
+		c.Logo != "" ||
+		c.Frame != 0
+}
+
+// validateStyle checks the styling options.
+func (c *QRConfig) validateStyle() error {
+	if c.ModuleStyle != "" {
+		if _, err := ParseModuleStyle(string(c.ModuleStyle)); err != nil {
+			return err
+		}
+	}
+	if c.Frame < 0 || c.Frame > MaxFrame {
+		return ErrInvalidFrame
+	}
+	// Scanners threshold at mid-gray, so the far end of the gradient must
+	// stay as readable against the background as the foreground.
+	if c.Gradient.A != 0 {
+		if ratio := ContrastRatio(c.Gradient, c.Background); ratio < MinGradientContrast {
+			return fmt.Errorf("%w: %s is %.1f:1, need %.1f:1",
+				ErrGradientContrast, ColorToHex(c.Gradient), ratio, MinGradientContrast)
+		}
+	}
+	if !c.Styled() {
+		return nil
+	}
+	if c.Animation.Mode != AnimationNone {
+		return ErrStyleAnimation
+	}
+	if c.Halftone != "" {
+		return ErrStyleHalftone
+	}
+	if c.SwissCross {
+		return ErrStyleSwissCross
+	}
+	return nil
+}
+
+// ContrastRatio returns the WCAG 2 contrast ratio between two colors, from
+// 1 (identical) to 21 (black on white).
+func ContrastRatio(a, b color.RGBA) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+func relativeLuminance(c color.RGBA) float64 {
+	channel := func(v uint8) float64 {
+		x := float64(v) / 255
+		if x <= 0.03928 {
+			return x / 12.92
+		}
+		return math.Pow((x+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.R) + 0.7152*channel(c.G) + 0.0722*channel(c.B)
+}
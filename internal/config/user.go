@@ -0,0 +1,430 @@
+// User configuration file support.
+//
+// Persistent defaults live in os.UserConfigDir()/qrgen/config.toml or
+// config.json (TOML wins if both exist). Only a flat set of keys is
+// supported, so the TOML reader handles simple `key = value` lines rather
+// than the full TOML grammar. Every key can also be overridden with a
+// QRGEN_<KEY> environment variable, e.g. QRGEN_SIZE=512.
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	appConfigDir   = "qrgen"
+	userConfigJSON = "config.json"
+	userConfigTOML = "config.toml"
+)
+
+// UserSettings holds persistent defaults from the user configuration file.
+// Empty fields fall back to the built-in defaults.
+type UserSettings struct {
+	Format          string `json:"format,omitempty"`
+	Size            int    `json:"size,omitempty"`
+	Foreground      string `json:"foreground,omitempty"`
+	Background      string `json:"background,omitempty"`
+	OutputDir       string `json:"output_dir,omitempty"`
+	FilenamePattern string `json:"filename_pattern,omitempty"`
+	ErrorCorrection string `json:"error_correction,omitempty"`
+	OnCollision     string `json:"on_collision,omitempty"`
+	DPI             int    `json:"dpi,omitempty"`
+}
+
+// integerSettings are the keys written to TOML as bare integers.
+var integerSettings = map[string]bool{"size": true, "dpi": true}
+
+// SettingKeys returns the supported configuration keys in display order.
+func SettingKeys() []string {
+	return []string{
+		"format", "size", "foreground", "background",
+		"output_dir", "filename_pattern", "error_correction", "on_collision", "dpi",
+	}
+}
+
+// AppConfigDir returns the qrgen configuration directory, creating it if
+// needed.
+func AppConfigDir() (string, error) {
+	configHome, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(configHome, appConfigDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return dir, nil
+}
+
+// UserConfigPath returns the path of the user configuration file. An
+// existing config.toml is preferred; otherwise config.json is used.
+func UserConfigPath() (string, error) {
+	dir, err := AppConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	tomlPath := filepath.Join(dir, userConfigTOML)
+	if _, err := os.Stat(tomlPath); err == nil {
+		return tomlPath, nil
+	}
+	return filepath.Join(dir, userConfigJSON), nil
+}
+
+// LoadUserSettings reads the user configuration file. A missing file yields
+// empty settings. Environment overrides are not applied; see ApplyEnv.
+func LoadUserSettings() (*UserSettings, error) {
+	path, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return ReadUserSettings(path)
+}
+
+// ReadUserSettings reads settings from a JSON or TOML file.
+func ReadUserSettings(path string) (*UserSettings, error) {
+	s := &UserSettings{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if filepath.Ext(path) == ".toml" {
+		values, err := parseFlatTOML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for key, value := range values {
+			if err := s.Set(key, value); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+			}
+		}
+		return s, nil
+	}
+
+	// Decode into a scratch value, then store every field through Set so
+	// JSON gets the same validation and normalization as TOML.
+	var raw UserSettings
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: invalid JSON: %w", path, err)
+	}
+	for _, key := range SettingKeys() {
+		value, _ := raw.Get(key)
+		if err := s.Set(key, value); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+	}
+	return s, nil
+}
+
+// Save writes the settings to path, as TOML or JSON depending on the
+// extension.
+func (s *UserSettings) Save(path string) error {
+	var data []byte
+	if filepath.Ext(path) == ".toml" {
+		var b bytes.Buffer
+		b.WriteString("# qrgen configuration\n")
+		for _, key := range SettingKeys() {
+			value, _ := s.Get(key)
+			if value == "" {
+				continue
+			}
+			if integerSettings[key] {
+				fmt.Fprintf(&b, "%s = %s\n", key, value)
+			} else {
+				fmt.Fprintf(&b, "%s = %s\n", key, strconv.Quote(value))
+			}
+		}
+		data = b.Bytes()
+	} else {
+		var err error
+		if data, err = json.MarshalIndent(s, "", "  "); err != nil {
+			return fmt.Errorf("failed to marshal config: %w", err)
+		}
+		data = append(data, '\n')
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Get returns the value of a setting as a string. Unset values are empty.
+func (s *UserSettings) Get(key string) (string, error) {
+	switch normalizeSettingKey(key) {
+	case "format":
+		return s.Format, nil
+	case "size":
+		if s.Size == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(s.Size), nil
+	case "foreground":
+		return s.Foreground, nil
+	case "background":
+		return s.Background, nil
+	case "output_dir":
+		return s.OutputDir, nil
+	case "filename_pattern":
+		return s.FilenamePattern, nil
+	case "error_correction":
+		return s.ErrorCorrection, nil
+	case "on_collision":
+		return s.OnCollision, nil
+	case "dpi":
+		if s.DPI == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(s.DPI), nil
+	}
+	return "", unknownSettingError(key)
+}
+
+// Set validates and stores a setting. An empty value clears it.
+func (s *UserSettings) Set(key, value string) error {
+	value = strings.TrimSpace(value)
+
+	switch normalizeSettingKey(key) {
+	case "format":
+		f := ParseFormat(value)
+		if value != "" && !isOutputFormat(f) {
+			return ErrInvalidFormat
+		}
+		s.Format = string(f)
+	case "size":
+		if value == "" {
+			s.Size = 0
+			return nil
+		}
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 64 || size > 4096 {
+			return ErrInvalidSize
+		}
+		s.Size = size
+	case "foreground", "background":
+		if value != "" {
+			c, err := ParseHexColor(value)
+			if err != nil {
+				return err
+			}
+			value = ColorToHex(c)
+		}
+		if normalizeSettingKey(key) == "foreground" {
+			s.Foreground = value
+		} else {
+			s.Background = value
+		}
+	case "output_dir":
+		s.OutputDir = value
+	case "filename_pattern":
+		s.FilenamePattern = value
+	case "error_correction":
+		if value != "" {
+			level, err := ParseErrorCorrection(value)
+			if err != nil {
+				return err
+			}
+			value = string(level)
+		}
+		s.ErrorCorrection = value
+	case "on_collision":
+		if value != "" {
+			policy, err := ParseCollisionPolicy(value)
+			if err != nil {
+				return err
+			}
+			value = string(policy)
+		}
+		s.OnCollision = value
+	case "dpi":
+		if value == "" {
+			s.DPI = 0
+			return nil
+		}
+		dpi, err := strconv.Atoi(value)
+		if err != nil || dpi < 72 || dpi > 2400 {
+			return ErrInvalidDPI
+		}
+		s.DPI = dpi
+	default:
+		return unknownSettingError(key)
+	}
+	return nil
+}
+
+// ApplyEnv overrides settings from QRGEN_<KEY> environment variables.
+func (s *UserSettings) ApplyEnv() error {
+	for _, key := range SettingKeys() {
+		name := "QRGEN_" + strings.ToUpper(key)
+		if value, ok := os.LookupEnv(name); ok {
+			if err := s.Set(key, value); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply copies the configured defaults onto cfg. The output path is set to
+// the unexpanded filename pattern inside the output directory; callers
+// expand it with SetOutputPattern once the content is known.
+func (s *UserSettings) Apply(cfg *QRConfig) {
+	if s.Format != "" {
+		cfg.Format = OutputFormat(s.Format)
+	}
+	if s.Size != 0 {
+		cfg.Size = s.Size
+	}
+	if c, err := ParseHexColor(s.Foreground); err == nil {
+		cfg.Foreground = c
+	}
+	if c, err := ParseHexColor(s.Background); err == nil {
+		cfg.Background = c
+	}
+	if s.ErrorCorrection != "" {
+		cfg.Level = ErrorCorrection(s.ErrorCorrection)
+	}
+	if s.DPI != 0 {
+		cfg.DPI = s.DPI
+	}
+	cfg.OnCollision = s.CollisionPolicy()
+
+	cfg.SetOutputPath(filepath.Join(ExpandHome(s.OutputDir), s.DefaultFilename()))
+}
+
+// CollisionPolicy returns the configured collision policy. Interactive
+// tools ask before overwriting unless configured otherwise.
+func (s *UserSettings) CollisionPolicy() CollisionPolicy {
+	if s.OnCollision != "" {
+		return CollisionPolicy(s.OnCollision)
+	}
+	return CollisionPrompt
+}
+
+// DefaultFilename returns the configured filename pattern, or "qrcode".
+func (s *UserSettings) DefaultFilename() string {
+	if s.FilenamePattern != "" {
+		return s.FilenamePattern
+	}
+	return "qrcode"
+}
+
+// LoadUserConfig returns the built-in defaults overlaid with the user
+// configuration file and environment overrides. It is the starting point
+// for both the TUI and the CLI commands.
+func LoadUserConfig() (*QRConfig, *UserSettings, error) {
+	cfg := DefaultConfig()
+
+	settings, err := LoadUserSettings()
+	if err != nil {
+		return cfg, &UserSettings{}, err
+	}
+	if err := settings.ApplyEnv(); err != nil {
+		return cfg, settings, err
+	}
+
+	settings.Apply(cfg)
+	return cfg, settings, nil
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory.
+func ExpandHome(path string) string {
+	if !strings.HasPrefix(path, "~") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// normalizeSettingKey accepts "output-dir" and "output_dir" alike.
+func normalizeSettingKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "-", "_")
+}
+
+func unknownSettingError(key string) error {
+	keys := SettingKeys()
+	sort.Strings(keys)
+	return fmt.Errorf("unknown setting %q (available: %s)", key, strings.Join(keys, ", "))
+}
+
+// parseFlatTOML parses `key = value` lines with # comments. Values may be
+// basic "strings", 'literal strings', integers or booleans.
+func parseFlatTOML(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if strings.HasPrefix(text, "[") {
+			return nil, fmt.Errorf("line %d: tables are not supported", line)
+		}
+
+		key, raw, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", line)
+		}
+		key = strings.TrimSpace(key)
+		raw = strings.TrimSpace(raw)
+
+		var value string
+		switch {
+		case strings.HasPrefix(raw, `"`):
+			end := closingQuote(raw)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			unquoted, err := strconv.Unquote(raw[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid string: %w", line, err)
+			}
+			value = unquoted
+		case strings.HasPrefix(raw, "'"):
+			end := strings.Index(raw[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			value = raw[1 : end+1]
+		default:
+			value, _, _ = strings.Cut(raw, "#")
+			value = strings.TrimSpace(value)
+		}
+
+		values[key] = value
+	}
+
+	return values, scanner.Err()
+}
+
+// closingQuote returns the index of the unescaped quote ending a basic
+// string that starts at s[0], or -1.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,49 @@
+// Package fsutil provides filesystem helpers shared by the generator, the
+// CLI and the preset store.
+package fsutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic renders into a temporary file next to path and renames it
+// over path once the output is complete. On failure the temporary file is
+// removed and any existing file at path is left untouched.
+func WriteFileAtomic(path string, render func(io.Writer) error) (err error) {
+	// Ensure output directory exists
+	dir := filepath.Dir(path)
+	if dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, ".qrgen-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = render(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(0644); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to move output file into place: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package generator
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+
+	"github.com/DalyChouikh/internal/config"
+	"github.com/skip2/go-qrcode"
+)
+
+// RenderGIF writes the QR code to w as a GIF, animated according to the
+// configured animation options.
+func (g *Generator) RenderGIF(w io.Writer) error {
+	qrc, err := g.encode()
+	if err != nil {
+		return err
+	}
+	return g.writeGIF(w, qrc, g.config.Size)
+}
+
+// writeGIF writes the QR code as a GIF. Without an animation mode it is a
+// single still frame. Animated frames are each a complete code whose
+// colors were checked for contrast by config validation, so a scanner can
+// read whichever frame it catches.
+func (g *Generator) writeGIF(w io.Writer, qrc *qrcode.QRCode, size int) error {
+	anim := g.config.Animation
+
+	var frames []*image.Paletted
+	var err error
+	switch anim.Mode {
+	case config.AnimationNone:
+		frames, err = g.stillFrame(qrc, size)
+	case config.AnimationColorCycle:
+		frames = g.colorCycleFrames(qrc.Bitmap(), size)
+	case config.AnimationReveal:
+		frames = g.revealFrames(qrc.Bitmap(), size)
+	case config.AnimationPayloads:
+		frames, err = g.payloadFrames(qrc, size)
+	default:
+		err = fmt.Errorf("%w: %s", config.ErrInvalidAnimation, anim.Mode)
+	}
+	if err != nil {
+		return err
+	}
+
+	// GIF delays are in hundredths of a second; browsers treat anything
+	// below 2 as "as fast as possible" and slow it right down.
+	delay := max(anim.FrameDelay()/10, 2)
+	out := &gif.GIF{Image: frames, LoopCount: gifLoopCount(anim.Loop)}
+	for range frames {
+		out.Delay = append(out.Delay, delay)
+	}
+	if err := gif.EncodeAll(w, out); err != nil {
+		return fmt.Errorf("failed to encode GIF: %w", err)
+	}
+	return nil
+}
+
+// stillFrame converts the regular raster image to a single GIF frame.
+func (g *Generator) stillFrame(qrc *qrcode.QRCode, size int) ([]*image.Paletted, error) {
+	img, err := g.rasterImage(qrc, size)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := img.(*image.Paletted); ok {
+		return []*image.Paletted{p}, nil
+	}
+
+	// Halftone images only use the two configured colors, so mapping to
+	// the nearest palette entry is exact. Gradients and logos add more
+	// colors, which are mapped onto a general palette.
+	pal := color.Palette{g.config.Background, g.config.Foreground}
+	if g.config.Gradient.A != 0 || g.config.Logo != "" {
+		pal = append(pal, palette.Plan9[:254]...)
+	}
+	p := image.NewPaletted(img.Bounds(), pal)
+	draw.Draw(p, p.Rect, img, img.Bounds().Min, draw.Src)
+	return []*image.Paletted{p}, nil
+}
+
+// colorCycleFrames draws the code once per cycle color.
+func (g *Generator) colorCycleFrames(bitmap [][]bool, size int) []*image.Paletted {
+	var frames []*image.Paletted
+	for _, fg := range g.config.Animation.CycleColors(g.config.Foreground) {
+		palette := color.Palette{g.config.Background, fg}
+		frames = append(frames, drawFrame(bitmap, size, palette, func(x, y int) uint8 { return 1 }))
+	}
+	return frames
+}
+
+// revealFrames sweep the foreground color diagonally across a code drawn
+// in a muted tone, from the top-left corner to the bottom-right. Muted
+// modules are still dark enough to scan, so no frame is incomplete.
+func (g *Generator) revealFrames(bitmap [][]bool, size int) []*image.Paletted {
+	palette := color.Palette{
+		g.config.Background,
+		config.RevealColor(g.config.Foreground, g.config.Background),
+		g.config.Foreground,
+	}
+
+	n := g.config.Animation.FrameCount()
+	diagonals := 2*len(bitmap) - 1
+	var frames []*image.Paletted
+	for i := 0; i < n; i++ {
+		front := i * diagonals / (n - 1)
+		frames = append(frames, drawFrame(bitmap, size, palette, func(x, y int) uint8 {
+			if x+y < front {
+				return 2
+			}
+			return 1
+		}))
+	}
+	return frames
+}
+
+// payloadFrames draws one frame per content: the configured content, which
+// is already encoded as qrc, followed by each extra payload.
+func (g *Generator) payloadFrames(qrc *qrcode.QRCode, size int) ([]*image.Paletted, error) {
+	palette := color.Palette{g.config.Background, g.config.Foreground}
+	dark := func(x, y int) uint8 { return 1 }
+
+	frames := []*image.Paletted{drawFrame(qrc.Bitmap(), size, palette, dark)}
+	for i, payload := range g.config.Animation.Payloads {
+		q, err := qrcode.New(payload, recoveryLevel(g.config.Level))
+		if err != nil {
+			return nil, fmt.Errorf("%w: payload %d: %w", ErrEncoding, i+2, err)
+		}
+		frames = append(frames, drawFrame(q.Bitmap(), size, palette, dark))
+	}
+	return frames, nil
+}
+
+// drawFrame draws a module matrix at size x size pixels, mapping each
+// pixel to the nearest module like go-qrcode's Image. Light modules use
+// palette entry 0; dark modules use the entry returned by index.
+func drawFrame(bitmap [][]bool, size int, palette color.Palette, index func(x, y int) uint8) *image.Paletted {
+	modules := len(bitmap)
+	size = max(size, modules)
+	img := image.NewPaletted(image.Rect(0, 0, size, size), palette)
+	for y := 0; y < size; y++ {
+		my := y * modules / size
+		for x := 0; x < size; x++ {
+			mx := x * modules / size
+			if bitmap[my][mx] {
+				img.Pix[img.PixOffset(x, y)] = index(mx, my)
+			}
+		}
+	}
+	return img
+}
+
+// gifLoopCount converts a play count (0 = forever) to the GIF loop count,
+// which counts repeats after the first play and uses -1 for "play once".
+func gifLoopCount(plays int) int {
+	switch plays {
+	case 0:
+		return 0
+	case 1:
+		return -1
+	}
+	return plays - 1
+}
@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/DalyChouikh/internal/config"
+	"github.com/skip2/go-qrcode"
+)
+
+// writeHTML writes a self-contained HTML snippet. SVG is inlined as an
+// <svg> element; other formats become an <img> with a data URI, which
+// also works in email clients that strip inline SVG.
+func (g *Generator) writeHTML(w io.Writer, qrc *qrcode.QRCode, size int) error {
+	alt := html.EscapeString(g.config.AltText())
+
+	var snippet string
+	if g.config.EmbedFormat() == config.FormatSVG {
+		svg, err := g.createSVG(qrc, size)
+		if err != nil {
+			return err
+		}
+		svg = svg[strings.Index(svg, "<svg"):] // Drop the XML declaration
+		snippet = strings.Replace(svg, "<svg ", `<svg role="img" aria-label="`+alt+`" `, 1)
+	} else {
+		uri, err := g.dataURI(qrc, size)
+		if err != nil {
+			return err
+		}
+		snippet = fmt.Sprintf(`<img src="%s" width="%d" height="%d" alt="%s">`, uri, size, size, alt)
+	}
+
+	if _, err := io.WriteString(w, snippet+"\n"); err != nil {
+		return fmt.Errorf("failed to write HTML: %w", err)
+	}
+	return nil
+}
+
+// writeDataURI writes the embedded image as a bare data URI.
+func (g *Generator) writeDataURI(w io.Writer, qrc *qrcode.QRCode, size int) error {
+	uri, err := g.dataURI(qrc, size)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, uri+"\n"); err != nil {
+		return fmt.Errorf("failed to write data URI: %w", err)
+	}
+	return nil
+}
+
+// writeMarkdown writes a Markdown image whose source is a data URI.
+func (g *Generator) writeMarkdown(w io.Writer, qrc *qrcode.QRCode, size int) error {
+	uri, err := g.dataURI(qrc, size)
+	if err != nil {
+		return err
+	}
+	alt := markdownEscaper.Replace(g.config.AltText())
+	if _, err := fmt.Fprintf(w, "![%s](%s)\n", alt, uri); err != nil {
+		return fmt.Errorf("failed to write Markdown: %w", err)
+	}
+	return nil
+}
+
+// markdownEscaper escapes characters that would end a Markdown alt text.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+
+// dataURI renders the QR code in the embed format with the regular
+// renderer and returns it base64 encoded as a data URI.
+func (g *Generator) dataURI(qrc *qrcode.QRCode, size int) (string, error) {
+	format := g.config.EmbedFormat()
+
+	var buf bytes.Buffer
+	if err := g.renderEncoded(&buf, qrc, format, size); err != nil {
+		return "", err
+	}
+	return "data:" + format.MIMEType() + ";base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -1,21 +1,40 @@
 // Package generator provides QR code generation functionality.
+//
+// Encoding and output are kept separate: the Render methods write a QR code
+// to any io.Writer (an HTTP response, a buffer in tests, stdout), while
+// Generate is a thin wrapper that writes the result to the configured output
+// path atomically.
 package generator
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"image"
 	"image/color"
+	"image/jpeg"
 	"image/png"
+	"io"
 	"os"
-	"path/filepath"
 
 	"github.com/DalyChouikh/internal/config"
+	"github.com/DalyChouikh/internal/fsutil"
+	"github.com/DalyChouikh/internal/halftone"
 	"github.com/skip2/go-qrcode"
 )
 
+// ErrEncoding is returned (wrapped) when the content cannot be encoded as a
+// QR code, typically because it exceeds the maximum capacity.
+var ErrEncoding = errors.New("failed to create QR code")
+
 // Generator handles QR code generation.
 type Generator struct {
 	config *config.QRConfig
+	paths  []string // Files written by the last Generate
+
+	background image.Image // Loaded halftone background, if any
+	logo       image.Image // Loaded logo, if any
+	logoData   []byte      // Logo file contents, embedded in SVG output
 }
 
 // New creates a new Generator with the given configuration.
@@ -24,84 +43,284 @@ func New(cfg *config.QRConfig) *Generator {
 }
 
 // Generate creates the QR code and saves it to the specified path.
+//
+// Every configured format and raster size is rendered from a single encode
+// (see QRConfig.Outputs). Each file is written to a temporary file in the
+// same directory and renamed into place, so readers never observe a
+// partially written QR code. The special output path "-" streams a single
+// format to stdout instead.
+//
+// Existing files are handled according to the config's collision policy.
+// With the increment policy the config's Outputs report the paths that were
+// actually written; Paths lists every file.
 func (g *Generator) Generate() error {
+	g.paths = nil
 	if err := g.config.Validate(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	// Ensure output directory exists
-	dir := filepath.Dir(g.config.OutputPath)
-	if dir != "" && dir != "." {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create output directory: %w", err)
+	if g.config.OutputPath == config.StdoutPath {
+		if len(g.config.Formats) > 1 || len(g.config.Sizes) > 1 {
+			return fmt.Errorf("cannot write several formats or sizes to stdout")
 		}
+		return g.Render(os.Stdout)
+	}
+
+	if err := g.config.ResolveCollisions(); err != nil {
+		return err
 	}
 
-	switch g.config.Format {
+	qrc, err := g.encode()
+	if err != nil {
+		return err
+	}
+	for _, o := range g.config.Outputs() {
+		err := fsutil.WriteFileAtomic(o.Path, func(w io.Writer) error {
+			return g.renderEncoded(w, qrc, o.Format, o.Size)
+		})
+		if err != nil {
+			return err
+		}
+		g.paths = append(g.paths, o.Path)
+	}
+	return nil
+}
+
+// Bitmap returns the encoded module matrix, including the quiet zone.
+// true marks a dark module.
+func (g *Generator) Bitmap() ([][]bool, error) {
+	qrc, err := g.encode()
+	if err != nil {
+		return nil, err
+	}
+	return qrc.Bitmap(), nil
+}
+
+// Paths returns the files written by the last successful Generate, in the
+// order of QRConfig.Outputs.
+func (g *Generator) Paths() []string {
+	return g.paths
+}
+
+// Render encodes the QR code in the configured format and writes it to w.
+// It does not touch the filesystem, and the output path is ignored.
+func (g *Generator) Render(w io.Writer) error {
+	if err := g.config.ValidateOptions(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	qrc, err := g.encode()
+	if err != nil {
+		return err
+	}
+	return g.renderEncoded(w, qrc, g.config.Format, g.config.Size)
+}
+
+// RenderPNG writes the QR code to w as a PNG image.
+func (g *Generator) RenderPNG(w io.Writer) error {
+	qrc, err := g.encode()
+	if err != nil {
+		return err
+	}
+	return g.writePNG(w, qrc, g.config.Size)
+}
+
+// RenderJPEG writes the QR code to w as a JPEG image.
+func (g *Generator) RenderJPEG(w io.Writer) error {
+	qrc, err := g.encode()
+	if err != nil {
+		return err
+	}
+	return g.writeJPEG(w, qrc, g.config.Size)
+}
+
+// RenderSVG writes the QR code to w as an SVG document.
+func (g *Generator) RenderSVG(w io.Writer) error {
+	qrc, err := g.encode()
+	if err != nil {
+		return err
+	}
+	return g.writeSVG(w, qrc, g.config.Size)
+}
+
+// RenderText writes the QR code to w as plain text using the configured
+// text renderer.
+func (g *Generator) RenderText(w io.Writer) error {
+	qrc, err := g.encode()
+	if err != nil {
+		return err
+	}
+	return g.writeText(w, qrc)
+}
+
+// renderEncoded writes an already encoded QR code in the given format and
+// size, so several outputs can share one encode.
+func (g *Generator) renderEncoded(w io.Writer, qrc *qrcode.QRCode, format config.OutputFormat, size int) error {
+	switch format {
 	case config.FormatPNG:
-		return g.generatePNG()
+		return g.writePNG(w, qrc, size)
+	case config.FormatJPEG:
+		return g.writeJPEG(w, qrc, size)
+	case config.FormatGIF:
+		return g.writeGIF(w, qrc, size)
 	case config.FormatSVG:
-		return g.generateSVG()
+		return g.writeSVG(w, qrc, size)
+	case config.FormatText:
+		return g.writeText(w, qrc)
+	case config.FormatHTML:
+		return g.writeHTML(w, qrc, size)
+	case config.FormatDataURI:
+		return g.writeDataURI(w, qrc, size)
+	case config.FormatMarkdown:
+		return g.writeMarkdown(w, qrc, size)
 	default:
-		return fmt.Errorf("unsupported format: %s", g.config.Format)
+		return fmt.Errorf("unsupported format: %s", format)
 	}
 }
 
-// generatePNG creates a PNG QR code.
-func (g *Generator) generatePNG() (err error) {
-	qrc, err := qrcode.New(g.config.Content, qrcode.Medium)
-	if err != nil {
-		return fmt.Errorf("failed to create QR code: %w", err)
+// rasterImage draws the QR code as an image, blended with the halftone
+// background when one is configured, with the Swiss cross on top when
+// enabled.
+func (g *Generator) rasterImage(qrc *qrcode.QRCode, size int) (image.Image, error) {
+	img, err := g.codeImage(qrc, size)
+	if err != nil || !g.config.SwissCross {
+		return img, err
 	}
+	return drawSwissCross(img, len(qrc.Bitmap()), g.config.Foreground, g.config.Background), nil
+}
 
-	qrc.ForegroundColor = g.config.Foreground
-	qrc.BackgroundColor = g.config.Background
-	qrc.DisableBorder = false
+// codeImage draws the modules of the QR code, plain, styled or halftone.
+func (g *Generator) codeImage(qrc *qrcode.QRCode, size int) (image.Image, error) {
+	if g.config.Styled() {
+		return g.styledImage(qrc.Bitmap(), size)
+	}
+	if g.config.Halftone == "" {
+		qrc.ForegroundColor = g.config.Foreground
+		qrc.BackgroundColor = g.config.Background
+		qrc.DisableBorder = false
+		return qrc.Image(size), nil
+	}
 
-	img := qrc.Image(g.config.Size)
+	if g.background == nil {
+		bg, err := halftone.LoadImage(g.config.Halftone)
+		if err != nil {
+			return nil, err
+		}
+		g.background = bg
+	}
+	return halftone.Render(qrc.Bitmap(), g.background, halftone.Options{
+		Foreground: g.config.Foreground,
+		Background: g.config.Background,
+		Size:       size,
+	})
+}
 
-	file, err := os.Create(g.config.OutputPath)
+func (g *Generator) writePNG(w io.Writer, qrc *qrcode.QRCode, size int) error {
+	img, err := g.rasterImage(qrc, size)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+		return err
 	}
-	defer func() {
-		if cerr := file.Close(); cerr != nil && err == nil {
-			err = fmt.Errorf("failed to close output file: %w", cerr)
-		}
-	}()
 
-	if err = png.Encode(file, img); err != nil {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
 		return fmt.Errorf("failed to encode PNG: %w", err)
 	}
+	if _, err := w.Write(withPNGDensity(buf.Bytes(), g.config.DPI)); err != nil {
+		return fmt.Errorf("failed to write PNG: %w", err)
+	}
+
+	return nil
+}
+
+func (g *Generator) writeJPEG(w io.Writer, qrc *qrcode.QRCode, size int) error {
+	img, err := g.rasterImage(qrc, size)
+	if err != nil {
+		return err
+	}
+
+	// High quality keeps module edges crisp enough to scan.
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		return fmt.Errorf("failed to encode JPEG: %w", err)
+	}
+	if _, err := w.Write(withJFIFDensity(buf.Bytes(), g.config.DPI)); err != nil {
+		return fmt.Errorf("failed to write JPEG: %w", err)
+	}
 
 	return nil
 }
 
-// generateSVG creates an SVG QR code.
-func (g *Generator) generateSVG() error {
-	qrc, err := qrcode.New(g.config.Content, qrcode.Medium)
+func (g *Generator) writeSVG(w io.Writer, qrc *qrcode.QRCode, size int) error {
+	svg, err := g.createSVG(qrc, size)
 	if err != nil {
-		return fmt.Errorf("failed to create QR code: %w", err)
+		return err
+	}
+	if _, err := io.WriteString(w, svg); err != nil {
+		return fmt.Errorf("failed to write SVG: %w", err)
 	}
 
-	svg := g.createSVG(qrc)
+	return nil
+}
+
+func (g *Generator) writeText(w io.Writer, qrc *qrcode.QRCode) error {
+	text, err := RenderBitmapText(qrc.Bitmap(), g.config.Renderer, g.config.Invert)
+	if err != nil {
+		return err
+	}
 
-	if err := os.WriteFile(g.config.OutputPath, []byte(svg), 0644); err != nil {
-		return fmt.Errorf("failed to write SVG file: %w", err)
+	if _, err := io.WriteString(w, text); err != nil {
+		return fmt.Errorf("failed to write text: %w", err)
 	}
 
 	return nil
 }
 
+// encode builds the QR code for the configured content.
+func (g *Generator) encode() (*qrcode.QRCode, error) {
+	qrc, err := qrcode.New(g.config.Content, recoveryLevel(g.config.Level))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncoding, err)
+	}
+	return qrc, nil
+}
+
+// SymbolVersion returns the QR version (1-40) content needs at the given
+// error correction level. A version-n symbol is 17+4n modules wide.
+func SymbolVersion(content string, level config.ErrorCorrection) (int, error) {
+	qrc, err := qrcode.New(content, recoveryLevel(level))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrEncoding, err)
+	}
+	return qrc.VersionNumber, nil
+}
+
+// recoveryLevel maps a configured error correction level to go-qrcode's.
+func recoveryLevel(level config.ErrorCorrection) qrcode.RecoveryLevel {
+	switch level {
+	case config.ECLow:
+		return qrcode.Low
+	case config.ECQuartile:
+		return qrcode.High // go-qrcode's "High" is the Q (25%) level
+	case config.ECHigh:
+		return qrcode.Highest
+	default:
+		return qrcode.Medium
+	}
+}
+
 // createSVG generates SVG content from a QR code.
-func (g *Generator) createSVG(qrc *qrcode.QRCode) string {
+func (g *Generator) createSVG(qrc *qrcode.QRCode, size int) (string, error) {
+	if g.config.Styled() {
+		return g.styledSVG(qrc.Bitmap(), size)
+	}
+
 	var buf bytes.Buffer
 
 	bitmap := qrc.Bitmap()
 	moduleCount := len(bitmap)
 
 	// Calculate module size for the target dimension
-	moduleSize := float64(g.config.Size) / float64(moduleCount)
+	moduleSize := float64(size) / float64(moduleCount)
 
 	fgColor := colorToSVG(g.config.Foreground)
 	bgColor := colorToSVG(g.config.Background)
@@ -109,7 +328,7 @@ func (g *Generator) createSVG(qrc *qrcode.QRCode) string {
 	// SVG header
 	buf.WriteString(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
 <svg xmlns="http://www.w3.org/2000/svg" version="1.1" viewBox="0 0 %d %d" width="%d" height="%d">
-`, g.config.Size, g.config.Size, g.config.Size, g.config.Size))
+`, size, size, size, size))
 
 	// Background
 	buf.WriteString(fmt.Sprintf(`  <rect width="100%%" height="100%%" fill="%s"/>
@@ -131,9 +350,13 @@ func (g *Generator) createSVG(qrc *qrcode.QRCode) string {
 	}
 
 	buf.WriteString(`  </g>
-</svg>`)
+`)
+	if g.config.SwissCross {
+		buf.WriteString(svgSwissCross(moduleCount, size, g.config.Foreground, g.config.Background))
+	}
+	buf.WriteString(`</svg>`)
 
-	return buf.String()
+	return buf.String(), nil
 }
 
 // colorToSVG converts a color.RGBA to an SVG-compatible color string.
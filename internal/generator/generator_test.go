@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DalyChouikh/internal/config"
+)
+
+func TestGenerateIncrementSeveralSizes(t *testing.T) {
+	dir := t.TempDir()
+	existing := []string{"a-128.png", "a-256.png", "a-128-2.png"}
+	for _, name := range existing {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("keep"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Content = "https://example.com"
+	cfg.SetOutputPath(filepath.Join(dir, "a.png"))
+	cfg.SetSizes([]int{128, 256})
+	cfg.OnCollision = config.CollisionIncrement
+
+	gen := New(cfg)
+	if err := gen.Generate(); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	want := []string{filepath.Join(dir, "a-128-3.png"), filepath.Join(dir, "a-256-3.png")}
+	got := gen.Paths()
+	if len(got) != len(want) {
+		t.Fatalf("Paths() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Paths()[%d] = %s, want %s", i, got[i], want[i])
+		}
+		if _, err := os.Stat(want[i]); err != nil {
+			t.Errorf("output not written: %v", err)
+		}
+	}
+	for _, name := range existing {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "keep" {
+			t.Errorf("%s was overwritten", name)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"math"
+)
+
+// withPNGDensity inserts a pHYs chunk recording dpi into an encoded PNG.
+// Without it, print software assumes 72 DPI. The chunk must come before
+// the image data, so it is placed right after IHDR.
+func withPNGDensity(data []byte, dpi int) []byte {
+	// 8-byte signature, then IHDR: length (4) + type (4) + data (13) + CRC (4).
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+	if dpi <= 0 || len(data) < ihdrEnd || !bytes.Equal(data[12:16], []byte("IHDR")) {
+		return data
+	}
+
+	ppm := uint32(math.Round(float64(dpi) / 0.0254)) // Pixels per metre
+	chunk := make([]byte, 4+4+9+4)
+	binary.BigEndian.PutUint32(chunk[0:], 9)
+	copy(chunk[4:], "pHYs")
+	binary.BigEndian.PutUint32(chunk[8:], ppm)
+	binary.BigEndian.PutUint32(chunk[12:], ppm)
+	chunk[16] = 1 // Unit: metre
+	binary.BigEndian.PutUint32(chunk[17:], crc32.ChecksumIEEE(chunk[4:17]))
+
+	out := make([]byte, 0, len(data)+len(chunk))
+	out = append(out, data[:ihdrEnd]...)
+	out = append(out, chunk...)
+	return append(out, data[ihdrEnd:]...)
+}
+
+// withJFIFDensity inserts a JFIF APP0 segment into an encoded JPEG, right
+// after the start-of-image marker. image/jpeg writes no APP0 segment, so
+// the density would otherwise be unknown. With dpi <= 0 only the aspect
+// ratio (1:1) is recorded.
+func withJFIFDensity(data []byte, dpi int) []byte {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return data
+	}
+
+	unit, density := byte(0), uint16(1) // No unit: aspect ratio only
+	if dpi > 0 {
+		unit, density = 1, uint16(dpi) // Dots per inch
+	}
+	app0 := []byte{
+		0xFF, 0xE0, 0, 16, // APP0 marker and segment length
+		'J', 'F', 'I', 'F', 0,
+		1, 2, // Version 1.02
+		unit,
+		byte(density >> 8), byte(density), // X density
+		byte(density >> 8), byte(density), // Y density
+		0, 0, // No thumbnail
+	}
+
+	out := make([]byte, 0, len(data)+len(app0))
+	out = append(out, data[:2]...)
+	out = append(out, app0...)
+	return append(out, data[2:]...)
+}
@@ -0,0 +1,340 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // Logo decoders
+	_ "image/jpeg"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/DalyChouikh/internal/config"
+)
+
+// Styled codes are drawn by qrgen itself rather than go-qrcode: modules
+// take the configured shape and gradient color, an optional frame is drawn
+// around the quiet zone, and an optional logo covers the center. Finder
+// patterns always stay square so scanners can locate the code.
+
+const (
+	quietModules  = 4    // Quiet zone go-qrcode draws on each side, in modules
+	dotRadius     = 0.45 // Dot radius, in modules
+	cornerRadius  = 0.3  // Corner radius of rounded modules, in modules
+	logoFraction  = 0.2  // Logo width as a fraction of the symbol width
+	finderModules = 7    // Width of a finder pattern, in modules
+)
+
+// styleGeometry lays out a styled code of modules modules (including the
+// quiet zone) in a size x size image.
+type styleGeometry struct {
+	modules int     // Modules per side, including the quiet zone
+	frame   int     // Frame width in modules
+	module  float64 // Module size in pixels
+	offset  float64 // Pixel offset of the frame's outer edge
+}
+
+// newStyleGeometry gives raster output a whole number of pixels per module,
+// centered in the image. This differs from go-qrcode, whose plain images
+// map each pixel to the nearest module and fill the canvas, so module
+// widths vary by a pixel; equal widths keep dots and rounded corners
+// uniform, at the cost of a background margin of up to one pixel per
+// module. SVG output uses fractional module sizes and fills the canvas.
+func newStyleGeometry(modules, frame, size int, raster bool) (styleGeometry, error) {
+	units := modules + 2*frame
+	if !raster {
+		return styleGeometry{modules: modules, frame: frame, module: float64(size) / float64(units)}, nil
+	}
+	if size < units {
+		return styleGeometry{}, fmt.Errorf("size %d is too small for a code of %d modules with a %d-module frame: use at least %d pixels",
+			size, modules, frame, units)
+	}
+	ppm := size / units
+	return styleGeometry{
+		modules: modules,
+		frame:   frame,
+		module:  float64(ppm),
+		offset:  float64((size - ppm*units) / 2),
+	}, nil
+}
+
+// origin returns the pixel position of module (x, y) of the bitmap.
+func (sg styleGeometry) origin(x, y int) (float64, float64) {
+	return sg.offset + float64(x+sg.frame)*sg.module, sg.offset + float64(y+sg.frame)*sg.module
+}
+
+// outer returns the pixel bounds of the frame's outer edge.
+func (sg styleGeometry) outer() (x0, y0, side float64) {
+	return sg.offset, sg.offset, float64(sg.modules+2*sg.frame) * sg.module
+}
+
+// symbol returns the pixel bounds of the symbol without its quiet zone.
+func (sg styleGeometry) symbol() (x0, y0, side float64) {
+	x0, y0 = sg.origin(quietModules, quietModules)
+	return x0, y0, float64(sg.modules-2*quietModules) * sg.module
+}
+
+// logoBox returns the pixel bounds of the logo and of the background pad
+// behind it, which is one module wider on every side.
+func (sg styleGeometry) logoBox() (logo, pad [4]float64) {
+	x0, y0, side := sg.symbol()
+	cx, cy := x0+side/2, y0+side/2
+	half := side * logoFraction / 2
+	logo = [4]float64{cx - half, cy - half, 2 * half, 2 * half}
+	pad = [4]float64{cx - half - sg.module, cy - half - sg.module, 2*half + 2*sg.module, 2*half + 2*sg.module}
+	return logo, pad
+}
+
+// isFinder reports whether module (x, y) of a bitmap with modules modules
+// (including the quiet zone) belongs to one of the three finder patterns.
+func isFinder(x, y, modules int) bool {
+	lo, hi := quietModules, modules-quietModules-finderModules
+	inRange := func(v, start int) bool { return v >= start && v < start+finderModules }
+	return (inRange(x, lo) && inRange(y, lo)) ||
+		(inRange(x, hi) && inRange(y, lo)) ||
+		(inRange(x, lo) && inRange(y, hi))
+}
+
+// moduleColor returns the foreground color of module (x, y): the
+// foreground, or a point on the diagonal gradient from the top-left to
+// the bottom-right of the symbol.
+func (g *Generator) moduleColor(x, y, modules int) color.RGBA {
+	fg, end := g.config.Foreground, g.config.Gradient
+	if end.A == 0 {
+		return fg
+	}
+	span := float64(2 * (modules - 2*quietModules - 1))
+	t := math.Max(0, math.Min(1, float64(x+y-2*quietModules)/span))
+	mix := func(a, b uint8) uint8 { return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t)) }
+	return color.RGBA{R: mix(fg.R, end.R), G: mix(fg.G, end.G), B: mix(fg.B, end.B), A: 255}
+}
+
+// inModule reports whether the point (u, v), in module units relative to
+// the module's top-left corner, is inside a module of the given shape.
+func inModule(shape config.ModuleStyle, u, v float64) bool {
+	switch shape {
+	case config.ModuleDots:
+		du, dv := u-0.5, v-0.5
+		return du*du+dv*dv <= dotRadius*dotRadius
+	case config.ModuleRounded:
+		// Distance from the nearest corner circle's center, if the point
+		// lies in a corner square.
+		cu := math.Min(math.Max(u, cornerRadius), 1-cornerRadius)
+		cv := math.Min(math.Max(v, cornerRadius), 1-cornerRadius)
+		du, dv := u-cu, v-cv
+		return du*du+dv*dv <= cornerRadius*cornerRadius
+	}
+	return true
+}
+
+// moduleShape returns the shape module (x, y) is drawn with.
+func (g *Generator) moduleShape(x, y, modules int) config.ModuleStyle {
+	if isFinder(x, y, modules) {
+		return config.ModuleSquare
+	}
+	return g.config.ModuleStyle
+}
+
+// styledImage draws a styled code at size x size pixels.
+func (g *Generator) styledImage(bitmap [][]bool, size int) (image.Image, error) {
+	modules := len(bitmap)
+	sg, err := newStyleGeometry(modules, g.config.Frame, size, true)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	fillRect(img, 0, 0, float64(size), g.config.Background)
+	if sg.frame > 0 {
+		x0, y0, side := sg.outer()
+		fillRect(img, x0, y0, side, g.config.Foreground)
+		inset := float64(sg.frame) * sg.module
+		fillRect(img, x0+inset, y0+inset, side-2*inset, g.config.Background)
+	}
+
+	ppm := int(sg.module)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			shape := g.moduleShape(x, y, modules)
+			col := g.moduleColor(x, y, modules)
+			px, py := sg.origin(x, y)
+			for j := 0; j < ppm; j++ {
+				for i := 0; i < ppm; i++ {
+					u := (float64(i) + 0.5) / sg.module
+					v := (float64(j) + 0.5) / sg.module
+					if inModule(shape, u, v) {
+						img.SetRGBA(int(px)+i, int(py)+j, col)
+					}
+				}
+			}
+		}
+	}
+
+	if g.config.Logo != "" {
+		logo, _, err := g.loadLogo()
+		if err != nil {
+			return nil, err
+		}
+		box, pad := sg.logoBox()
+		fillRect(img, pad[0], pad[1], pad[2], g.config.Background)
+		drawScaled(img, logo, image.Rect(
+			int(math.Round(box[0])), int(math.Round(box[1])),
+			int(math.Round(box[0]+box[2])), int(math.Round(box[1]+box[3]))),
+			g.config.Background)
+	}
+	return img, nil
+}
+
+// styledSVG returns a styled code as an SVG document.
+func (g *Generator) styledSVG(bitmap [][]bool, size int) (string, error) {
+	modules := len(bitmap)
+	sg, _ := newStyleGeometry(modules, g.config.Frame, size, false)
+	fg, bg := colorToSVG(g.config.Foreground), colorToSVG(g.config.Background)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" version="1.1" viewBox="0 0 %d %d" width="%d" height="%d">
+`, size, size, size, size)
+
+	fill := fg
+	if g.config.Gradient.A != 0 {
+		// Gradient stops sit on the centers of the first and last modules,
+		// matching the per-module colors of raster output.
+		x0, y0, side := sg.symbol()
+		start, end := x0+sg.module/2, y0+side-sg.module/2
+		fmt.Fprintf(&b, `  <defs>
+    <linearGradient id="qrgen-fg" gradientUnits="userSpaceOnUse" x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f">
+      <stop offset="0" stop-color="%s"/>
+      <stop offset="1" stop-color="%s"/>
+    </linearGradient>
+  </defs>
+`, start, start, end, end, fg, colorToSVG(g.config.Gradient))
+		fill = "url(#qrgen-fg)"
+	}
+
+	fmt.Fprintf(&b, `  <rect width="100%%" height="100%%" fill="%s"/>
+`, bg)
+	if sg.frame > 0 {
+		x0, y0, side := sg.outer()
+		inset := float64(sg.frame) * sg.module
+		fmt.Fprintf(&b, `  <rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>
+  <rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>
+`, x0, y0, side, side, fg, x0+inset, y0+inset, side-2*inset, side-2*inset, bg)
+	}
+
+	fmt.Fprintf(&b, `  <g fill="%s">
+`, fill)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			px, py := sg.origin(x, y)
+			switch g.moduleShape(x, y, modules) {
+			case config.ModuleDots:
+				fmt.Fprintf(&b, `    <circle cx="%.2f" cy="%.2f" r="%.2f"/>
+`, px+sg.module/2, py+sg.module/2, sg.module*dotRadius)
+			case config.ModuleRounded:
+				fmt.Fprintf(&b, `    <rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" rx="%.2f"/>
+`, px, py, sg.module, sg.module, sg.module*cornerRadius)
+			default:
+				fmt.Fprintf(&b, `    <rect x="%.2f" y="%.2f" width="%.2f" height="%.2f"/>
+`, px, py, sg.module, sg.module)
+			}
+		}
+	}
+	b.WriteString(`  </g>
+`)
+
+	if g.config.Logo != "" {
+		_, data, err := g.loadLogo()
+		if err != nil {
+			return "", err
+		}
+		box, pad := sg.logoBox()
+		fmt.Fprintf(&b, `  <rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>
+  <image x="%.2f" y="%.2f" width="%.2f" height="%.2f" preserveAspectRatio="xMidYMid meet" href="data:%s;base64,%s"/>
+`, pad[0], pad[1], pad[2], pad[3], bg,
+			box[0], box[1], box[2], box[3], http.DetectContentType(data), base64.StdEncoding.EncodeToString(data))
+	}
+
+	b.WriteString(`</svg>`)
+	return b.String(), nil
+}
+
+// loadLogo reads and decodes the configured logo once per generator. It
+// returns the decoded image and the file contents, which SVG embeds as is.
+func (g *Generator) loadLogo() (image.Image, []byte, error) {
+	if g.logo != nil {
+		return g.logo, g.logoData, nil
+	}
+	data, err := os.ReadFile(g.config.Logo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read logo: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode logo %s: %w", g.config.Logo, err)
+	}
+	g.logo, g.logoData = img, data
+	return img, data, nil
+}
+
+// fillRect fills a side x side square at (x, y), rounded to whole pixels.
+func fillRect(img *image.RGBA, x, y, side float64, c color.RGBA) {
+	r := image.Rect(round(x), round(y), round(x+side), round(y+side)).Intersect(img.Rect)
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			img.SetRGBA(px, py, c)
+		}
+	}
+}
+
+func round(v float64) int {
+	return int(math.Round(v))
+}
+
+// drawScaled draws src into dst, scaled to fit r while keeping its aspect
+// ratio. Each destination pixel averages the source pixels under it, and
+// transparent areas are composited over bg.
+func drawScaled(dst *image.RGBA, src image.Image, r image.Rectangle, bg color.RGBA) {
+	sb := src.Bounds()
+	if sb.Empty() || r.Empty() {
+		return
+	}
+	scale := math.Min(float64(r.Dx())/float64(sb.Dx()), float64(r.Dy())/float64(sb.Dy()))
+	w, h := int(float64(sb.Dx())*scale), int(float64(sb.Dy())*scale)
+	ox, oy := r.Min.X+(r.Dx()-w)/2, r.Min.Y+(r.Dy()-h)/2
+
+	for y := 0; y < h; y++ {
+		sy0, sy1 := sb.Min.Y+y*sb.Dy()/h, sb.Min.Y+(y+1)*sb.Dy()/h
+		sy1 = max(sy1, sy0+1)
+		for x := 0; x < w; x++ {
+			sx0, sx1 := sb.Min.X+x*sb.Dx()/w, sb.Min.X+(x+1)*sb.Dx()/w
+			sx1 = max(sx1, sx0+1)
+
+			var rs, gs, bs, as, n float64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					rs, gs, bs, as = rs+float64(cr), gs+float64(cg), bs+float64(cb), as+float64(ca)
+					n++
+				}
+			}
+			// Colors are alpha-premultiplied, so compositing over bg adds
+			// bg weighted by the remaining transparency.
+			alpha := as / n / 0xffff
+			blend := func(sum float64, b uint8) uint8 {
+				return uint8(math.Round((sum/n/0xffff + (1-alpha)*float64(b)/255) * 255))
+			}
+			dst.SetRGBA(ox+x, oy+y, color.RGBA{R: blend(rs, bg.R), G: blend(gs, bg.G), B: blend(bs, bg.B), A: 255})
+		}
+	}
+}
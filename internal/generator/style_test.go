@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DalyChouikh/internal/config"
+)
+
+func TestStyledImageKeepsModules(t *testing.T) {
+	logo := filepath.Join(t.TempDir(), "logo.png")
+	writeTestLogo(t, logo)
+
+	tests := []struct {
+		name  string
+		style func(*config.QRConfig)
+	}{
+		{"gradient", func(c *config.QRConfig) { c.Gradient = color.RGBA{R: 0xC2, G: 0x18, B: 0x5B, A: 255} }},
+		{"dots", func(c *config.QRConfig) { c.ModuleStyle = config.ModuleDots }},
+		{"rounded", func(c *config.QRConfig) { c.ModuleStyle = config.ModuleRounded }},
+		{"frame", func(c *config.QRConfig) { c.Frame = 2 }},
+		{"logo", func(c *config.QRConfig) { c.Logo = logo; c.Level = config.ECHigh }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Content = "https://example.com/styled"
+			cfg.Size = 400
+			tt.style(cfg)
+
+			gen := New(cfg)
+			var buf bytes.Buffer
+			if err := gen.Render(&buf); err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			img, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != cfg.Size || b.Dy() != cfg.Size {
+				t.Fatalf("image is %dx%d, want %dx%d", b.Dx(), b.Dy(), cfg.Size, cfg.Size)
+			}
+
+			bitmap := mustBitmap(t, cfg)
+			sg, err := newStyleGeometry(len(bitmap), cfg.Frame, cfg.Size, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, pad := sg.logoBox()
+			misread := 0
+			for y, row := range bitmap {
+				for x, want := range row {
+					px, py := sg.origin(x, y)
+					cx, cy := px+sg.module/2, py+sg.module/2
+					if cfg.Logo != "" && cx >= pad[0] && cx < pad[0]+pad[2] && cy >= pad[1] && cy < pad[1]+pad[3] {
+						continue // Hidden by the logo, recovered by error correction
+					}
+					gray := color.GrayModel.Convert(img.At(int(cx), int(cy))).(color.Gray)
+					if got := gray.Y < 128; got != want {
+						misread++
+					}
+				}
+			}
+			if misread > 0 {
+				t.Errorf("%d module centers do not match the code", misread)
+			}
+		})
+	}
+}
+
+func TestStyledImageFrame(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Content = "https://example.com"
+	cfg.Frame = 1
+
+	img, err := New(cfg).styledImage(mustBitmap(t, cfg), 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sg, _ := newStyleGeometry(len(mustBitmap(t, cfg)), cfg.Frame, 256, true)
+	x0, y0, _ := sg.outer()
+	if got := color.RGBAModel.Convert(img.At(int(x0), int(y0))); got != cfg.Foreground {
+		t.Errorf("frame corner = %v, want %v", got, cfg.Foreground)
+	}
+}
+
+func TestStyledImageTooSmall(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Content = "https://example.com"
+	cfg.Frame = config.MaxFrame
+
+	if _, err := New(cfg).styledImage(mustBitmap(t, cfg), 32); err == nil {
+		t.Error("expected an error for a size below one pixel per module")
+	}
+}
+
+func mustBitmap(t *testing.T, cfg *config.QRConfig) [][]bool {
+	t.Helper()
+	qrc, err := New(cfg).encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return qrc.Bitmap()
+}
+
+func writeTestLogo(t *testing.T, path string) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			img.Set(x, y, color.RGBA{R: 0xE5, G: 0x39, B: 0x35, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"github.com/DalyChouikh/internal/config"
+)
+
+// swissCross describes the Swiss cross badge in pixel coordinates: a square
+// in the background color, a slightly smaller square in the foreground
+// color, and a cross in the background color on top.
+type swissCross struct {
+	outer, inner, horizontal, vertical image.Rectangle
+}
+
+// newSwissCross lays out the cross for an image of size pixels holding a
+// code of modules modules (including the quiet zone). The badge is 7 mm
+// on a 46 mm symbol, centered, with a 0.5 mm border; the cross arms follow
+// the proportions of the Swiss flag.
+func newSwissCross(modules, size int) swissCross {
+	symbol := float64(size) * float64(modules-2*quietModules) / float64(modules)
+	badge := symbol * config.SwissCrossMM / config.SwissQRSymbolMM
+	border := badge / 14
+	inner := badge - 2*border
+	arm, reach := inner*6/32, inner*10/32
+
+	center := float64(size) / 2
+	square := func(half float64) image.Rectangle {
+		return image.Rect(round(center-half), round(center-half), round(center+half), round(center+half))
+	}
+	return swissCross{
+		outer:      square(badge / 2),
+		inner:      square(inner / 2),
+		horizontal: image.Rect(round(center-reach), round(center-arm/2), round(center+reach), round(center+arm/2)),
+		vertical:   image.Rect(round(center-arm/2), round(center-reach), round(center+arm/2), round(center+reach)),
+	}
+}
+
+// drawSwissCross returns a copy of img with the Swiss cross in its center.
+func drawSwissCross(img image.Image, modules int, fg, bg color.RGBA) image.Image {
+	b := img.Bounds()
+	out := image.NewRGBA(b)
+	draw.Draw(out, b, img, b.Min, draw.Src)
+
+	c := newSwissCross(modules, b.Dx())
+	fill := func(r image.Rectangle, col color.RGBA) {
+		draw.Draw(out, r.Add(b.Min), &image.Uniform{C: col}, image.Point{}, draw.Src)
+	}
+	fill(c.outer, bg)
+	fill(c.inner, fg)
+	fill(c.horizontal, bg)
+	fill(c.vertical, bg)
+	return out
+}
+
+// svgSwissCross returns SVG elements drawing the Swiss cross in the center
+// of a size x size code.
+func svgSwissCross(modules, size int, fg, bg color.RGBA) string {
+	c := newSwissCross(modules, size)
+	var s strings.Builder
+	rect := func(r image.Rectangle, col color.RGBA) {
+		fmt.Fprintf(&s, `  <rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>
+`, r.Min.X, r.Min.Y, r.Dx(), r.Dy(), colorToSVG(col))
+	}
+	rect(c.outer, bg)
+	rect(c.inner, fg)
+	rect(c.horizontal, bg)
+	rect(c.vertical, bg)
+	return s.String()
+}
@@ -0,0 +1,170 @@
+// Plain text QR code rendering.
+//
+// This file provides alternative renderers for environments where ANSI colors
+// are unavailable or undesirable, such as log files, emails, or terminals with
+// a dark background. All renderers except ANSI produce output without escape
+// codes, so the dark modules are drawn with glyphs and light modules are left
+// blank. Inverted polarity swaps this, which is what most dark-themed
+// terminals need for a scannable result.
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DalyChouikh/internal/config"
+	"github.com/skip2/go-qrcode"
+)
+
+// quadrantChars maps a 2x2 module block to a Unicode quadrant character.
+// Index bits: 1 = top-left, 2 = top-right, 4 = bottom-left, 8 = bottom-right.
+var quadrantChars = [16]string{
+	" ", "▘", "▝", "▀", "▖", "▌", "▞", "▛",
+	"▗", "▚", "▐", "▜", "▄", "▙", "▟", "█",
+}
+
+// brailleDots maps a module offset within a 2x4 braille cell to its dot bit.
+// Index: [row][col]
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// GenerateTextPreview encodes content and renders it with the given text
+// renderer. See RenderBitmapText for details on the available renderers.
+func GenerateTextPreview(content string, renderer config.TextRenderer, invert bool) (string, error) {
+	if content == "" {
+		return "", fmt.Errorf("content cannot be empty")
+	}
+
+	qrc, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return "", fmt.Errorf("failed to create QR code for preview: %w", err)
+	}
+
+	return RenderBitmapText(qrc.Bitmap(), renderer, invert)
+}
+
+// RenderBitmapText converts a QR code bitmap into text using the given
+// renderer. When invert is true, light modules are drawn instead of dark ones.
+// An empty renderer defaults to ASCII.
+func RenderBitmapText(bitmap [][]bool, renderer config.TextRenderer, invert bool) (string, error) {
+	if len(bitmap) == 0 {
+		return "", nil
+	}
+
+	if invert {
+		bitmap = invertBitmap(bitmap)
+	}
+
+	switch renderer {
+	case config.RendererANSI:
+		return renderBitmapToTerminal(bitmap), nil
+	case config.RendererASCII, "":
+		return renderPerModule(bitmap, "##", "  "), nil
+	case config.RendererBlocks:
+		return renderPerModule(bitmap, "██", "  "), nil
+	case config.RendererQuadrant:
+		return renderQuadrant(bitmap), nil
+	case config.RendererBraille:
+		return renderBraille(bitmap), nil
+	default:
+		return "", fmt.Errorf("unknown text renderer: %s", renderer)
+	}
+}
+
+// renderPerModule draws each module as a fixed two-character string so that
+// modules appear roughly square in a monospace font.
+func renderPerModule(bitmap [][]bool, dark, light string) string {
+	var buf strings.Builder
+	buf.Grow(len(bitmap) * (len(bitmap[0])*len(dark) + 1))
+
+	for _, row := range bitmap {
+		for _, module := range row {
+			if module {
+				buf.WriteString(dark)
+			} else {
+				buf.WriteString(light)
+			}
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+// renderQuadrant draws 2x2 modules per character cell.
+func renderQuadrant(bitmap [][]bool) string {
+	rows := len(bitmap)
+	cols := len(bitmap[0])
+
+	var buf strings.Builder
+	for y := 0; y < rows; y += 2 {
+		for x := 0; x < cols; x += 2 {
+			idx := 0
+			if moduleAt(bitmap, x, y) {
+				idx |= 1
+			}
+			if moduleAt(bitmap, x+1, y) {
+				idx |= 2
+			}
+			if moduleAt(bitmap, x, y+1) {
+				idx |= 4
+			}
+			if moduleAt(bitmap, x+1, y+1) {
+				idx |= 8
+			}
+			buf.WriteString(quadrantChars[idx])
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+// renderBraille draws 2x4 modules per character cell using braille patterns.
+func renderBraille(bitmap [][]bool) string {
+	rows := len(bitmap)
+	cols := len(bitmap[0])
+
+	var buf strings.Builder
+	for y := 0; y < rows; y += 4 {
+		for x := 0; x < cols; x += 2 {
+			r := rune(0x2800)
+			for dy := 0; dy < 4; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					if moduleAt(bitmap, x+dx, y+dy) {
+						r |= brailleDots[dy][dx]
+					}
+				}
+			}
+			buf.WriteRune(r)
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+// moduleAt reports whether the module at (x, y) is dark, treating
+// out-of-range coordinates as light.
+func moduleAt(bitmap [][]bool, x, y int) bool {
+	if y < 0 || y >= len(bitmap) || x < 0 || x >= len(bitmap[y]) {
+		return false
+	}
+	return bitmap[y][x]
+}
+
+// invertBitmap returns a copy of the bitmap with every module flipped.
+func invertBitmap(bitmap [][]bool) [][]bool {
+	inverted := make([][]bool, len(bitmap))
+	for y, row := range bitmap {
+		inverted[y] = make([]bool, len(row))
+		for x, module := range row {
+			inverted[y][x] = !module
+		}
+	}
+	return inverted
+}
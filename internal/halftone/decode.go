@@ -0,0 +1,385 @@
+package halftone
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrUnreadable is returned (wrapped) when a module matrix cannot be
+// decoded.
+var ErrUnreadable = errors.New("QR code cannot be decoded")
+
+// blockSpec describes a group of error correction blocks of one size.
+type blockSpec struct {
+	count int // Number of blocks in the group
+	total int // Codewords per block
+	data  int // Data codewords per block
+}
+
+// versionBlocks lists the block structure for each version (1-40) and
+// error correction level, in the order L, M, Q, H (ISO/IEC 18004, Table 9).
+var versionBlocks = [40][4][]blockSpec{
+	{{{1, 26, 19}}, {{1, 26, 16}}, {{1, 26, 13}}, {{1, 26, 9}}},                                                                 // 1
+	{{{1, 44, 34}}, {{1, 44, 28}}, {{1, 44, 22}}, {{1, 44, 16}}},                                                                // 2
+	{{{1, 70, 55}}, {{1, 70, 44}}, {{2, 35, 17}}, {{2, 35, 13}}},                                                                // 3
+	{{{1, 100, 80}}, {{2, 50, 32}}, {{2, 50, 24}}, {{4, 25, 9}}},                                                                // 4
+	{{{1, 134, 108}}, {{2, 67, 43}}, {{2, 33, 15}, {2, 34, 16}}, {{2, 33, 11}, {2, 34, 12}}},                                    // 5
+	{{{2, 86, 68}}, {{4, 43, 27}}, {{4, 43, 19}}, {{4, 43, 15}}},                                                                // 6
+	{{{2, 98, 78}}, {{4, 49, 31}}, {{2, 32, 14}, {4, 33, 15}}, {{4, 39, 13}, {1, 40, 14}}},                                      // 7
+	{{{2, 121, 97}}, {{2, 60, 38}, {2, 61, 39}}, {{4, 40, 18}, {2, 41, 19}}, {{4, 40, 14}, {2, 41, 15}}},                        // 8
+	{{{2, 146, 116}}, {{3, 58, 36}, {2, 59, 37}}, {{4, 36, 16}, {4, 37, 17}}, {{4, 36, 12}, {4, 37, 13}}},                       // 9
+	{{{2, 86, 68}, {2, 87, 69}}, {{4, 69, 43}, {1, 70, 44}}, {{6, 43, 19}, {2, 44, 20}}, {{6, 43, 15}, {2, 44, 16}}},            // 10
+	{{{4, 101, 81}}, {{1, 80, 50}, {4, 81, 51}}, {{4, 50, 22}, {4, 51, 23}}, {{3, 36, 12}, {8, 37, 13}}},                        // 11
+	{{{2, 116, 92}, {2, 117, 93}}, {{6, 58, 36}, {2, 59, 37}}, {{4, 46, 20}, {6, 47, 21}}, {{7, 42, 14}, {4, 43, 15}}},          // 12
+	{{{4, 133, 107}}, {{8, 59, 37}, {1, 60, 38}}, {{8, 44, 20}, {4, 45, 21}}, {{12, 33, 11}, {4, 34, 12}}},                      // 13
+	{{{3, 145, 115}, {1, 146, 116}}, {{4, 64, 40}, {5, 65, 41}}, {{11, 36, 16}, {5, 37, 17}}, {{11, 36, 12}, {5, 37, 13}}},      // 14
+	{{{5, 109, 87}, {1, 110, 88}}, {{5, 65, 41}, {5, 66, 42}}, {{5, 54, 24}, {7, 55, 25}}, {{11, 36, 12}, {7, 37, 13}}},         // 15
+	{{{5, 122, 98}, {1, 123, 99}}, {{7, 73, 45}, {3, 74, 46}}, {{15, 43, 19}, {2, 44, 20}}, {{3, 45, 15}, {13, 46, 16}}},        // 16
+	{{{1, 135, 107}, {5, 136, 108}}, {{10, 74, 46}, {1, 75, 47}}, {{1, 50, 22}, {15, 51, 23}}, {{2, 42, 14}, {17, 43, 15}}},     // 17
+	{{{5, 150, 120}, {1, 151, 121}}, {{9, 69, 43}, {4, 70, 44}}, {{17, 50, 22}, {1, 51, 23}}, {{2, 42, 14}, {19, 43, 15}}},      // 18
+	{{{3, 141, 113}, {4, 142, 114}}, {{3, 70, 44}, {11, 71, 45}}, {{17, 47, 21}, {4, 48, 22}}, {{9, 39, 13}, {16, 40, 14}}},     // 19
+	{{{3, 135, 107}, {5, 136, 108}}, {{3, 67, 41}, {13, 68, 42}}, {{15, 54, 24}, {5, 55, 25}}, {{15, 43, 15}, {10, 44, 16}}},    // 20
+	{{{4, 144, 116}, {4, 145, 117}}, {{17, 68, 42}}, {{17, 50, 22}, {6, 51, 23}}, {{19, 46, 16}, {6, 47, 17}}},                  // 21
+	{{{2, 139, 111}, {7, 140, 112}}, {{17, 74, 46}}, {{7, 54, 24}, {16, 55, 25}}, {{34, 37, 13}}},                               // 22
+	{{{4, 151, 121}, {5, 152, 122}}, {{4, 75, 47}, {14, 76, 48}}, {{11, 54, 24}, {14, 55, 25}}, {{16, 45, 15}, {14, 46, 16}}},   // 23
+	{{{6, 147, 117}, {4, 148, 118}}, {{6, 73, 45}, {14, 74, 46}}, {{11, 54, 24}, {16, 55, 25}}, {{30, 46, 16}, {2, 47, 17}}},    // 24
+	{{{8, 132, 106}, {4, 133, 107}}, {{8, 75, 47}, {13, 76, 48}}, {{7, 54, 24}, {22, 55, 25}}, {{22, 45, 15}, {13, 46, 16}}},    // 25
+	{{{10, 142, 114}, {2, 143, 115}}, {{19, 74, 46}, {4, 75, 47}}, {{28, 50, 22}, {6, 51, 23}}, {{33, 46, 16}, {4, 47, 17}}},    // 26
+	{{{8, 152, 122}, {4, 153, 123}}, {{22, 73, 45}, {3, 74, 46}}, {{8, 53, 23}, {26, 54, 24}}, {{12, 45, 15}, {28, 46, 16}}},    // 27
+	{{{3, 147, 117}, {10, 148, 118}}, {{3, 73, 45}, {23, 74, 46}}, {{4, 54, 24}, {31, 55, 25}}, {{11, 45, 15}, {31, 46, 16}}},   // 28
+	{{{7, 146, 116}, {7, 147, 117}}, {{21, 73, 45}, {7, 74, 46}}, {{1, 53, 23}, {37, 54, 24}}, {{19, 45, 15}, {26, 46, 16}}},    // 29
+	{{{5, 145, 115}, {10, 146, 116}}, {{19, 75, 47}, {10, 76, 48}}, {{15, 54, 24}, {25, 55, 25}}, {{23, 45, 15}, {25, 46, 16}}}, // 30
+	{{{13, 145, 115}, {3, 146, 116}}, {{2, 74, 46}, {29, 75, 47}}, {{42, 54, 24}, {1, 55, 25}}, {{23, 45, 15}, {28, 46, 16}}},   // 31
+	{{{17, 145, 115}}, {{10, 74, 46}, {23, 75, 47}}, {{10, 54, 24}, {35, 55, 25}}, {{19, 45, 15}, {35, 46, 16}}},                // 32
+	{{{17, 145, 115}, {1, 146, 116}}, {{14, 74, 46}, {21, 75, 47}}, {{29, 54, 24}, {19, 55, 25}}, {{11, 45, 15}, {46, 46, 16}}}, // 33
+	{{{13, 145, 115}, {6, 146, 116}}, {{14, 74, 46}, {23, 75, 47}}, {{44, 54, 24}, {7, 55, 25}}, {{59, 46, 16}, {1, 47, 17}}},   // 34
+	{{{12, 151, 121}, {7, 152, 122}}, {{12, 75, 47}, {26, 76, 48}}, {{39, 54, 24}, {14, 55, 25}}, {{22, 45, 15}, {41, 46, 16}}}, // 35
+	{{{6, 151, 121}, {14, 152, 122}}, {{6, 75, 47}, {34, 76, 48}}, {{46, 54, 24}, {10, 55, 25}}, {{2, 45, 15}, {64, 46, 16}}},   // 36
+	{{{17, 152, 122}, {4, 153, 123}}, {{29, 74, 46}, {14, 75, 47}}, {{49, 54, 24}, {10, 55, 25}}, {{24, 45, 15}, {46, 46, 16}}}, // 37
+	{{{4, 152, 122}, {18, 153, 123}}, {{13, 74, 46}, {32, 75, 47}}, {{48, 54, 24}, {14, 55, 25}}, {{42, 45, 15}, {32, 46, 16}}}, // 38
+	{{{20, 147, 117}, {4, 148, 118}}, {{40, 75, 47}, {7, 76, 48}}, {{43, 54, 24}, {22, 55, 25}}, {{10, 45, 15}, {67, 46, 16}}},  // 39
+	{{{19, 148, 118}, {6, 149, 119}}, {{18, 75, 47}, {31, 76, 48}}, {{34, 54, 24}, {34, 55, 25}}, {{20, 45, 15}, {61, 46, 16}}}, // 40
+}
+
+// formatLevels maps the two error correction bits of the format
+// information to an index into versionBlocks.
+var formatLevels = [4]int{1, 0, 3, 2} // 00 = M, 01 = L, 10 = H, 11 = Q
+
+// Decode reads the content of a QR code from its module matrix, indexed
+// [y][x] with true for dark modules and without the quiet zone. Damaged
+// modules are repaired with the code's error correction where possible.
+// It returns the content and the number of corrected codewords.
+func Decode(matrix [][]bool) (string, int, error) {
+	n := len(matrix)
+	version, err := versionForSize(n)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %w", ErrUnreadable, err)
+	}
+	function, _ := functionModules(n)
+
+	level, mask, err := readFormat(matrix)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Read codewords in the zigzag order they were placed: column pairs
+	// from the right, alternating upwards and downwards, skipping the
+	// vertical timing pattern.
+	var codewords []byte
+	var cur byte
+	nbits := 0
+	for right := n - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for i := 0; i < n; i++ {
+			y := i
+			if upward {
+				y = n - 1 - i
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if function[y][x] {
+					continue
+				}
+				bit := matrix[y][x] != masked(mask, x, y)
+				cur <<= 1
+				if bit {
+					cur |= 1
+				}
+				if nbits++; nbits%8 == 0 {
+					codewords = append(codewords, cur)
+					cur = 0
+				}
+			}
+		}
+	}
+
+	data, corrected, err := correctBlocks(codewords, versionBlocks[version-1][level])
+	if err != nil {
+		return "", 0, err
+	}
+	text, err := parseSegments(data, version)
+	if err != nil {
+		return "", 0, err
+	}
+	return text, corrected, nil
+}
+
+// readFormat reads the error correction level and mask pattern from the
+// two copies of the format information, choosing the valid format word
+// closest to what was read.
+func readFormat(m [][]bool) (level, mask int, err error) {
+	n := len(m)
+	var first, second uint
+	bit := func(v bool, i int) uint {
+		if v {
+			return 1 << i
+		}
+		return 0
+	}
+
+	// Around the top-left finder pattern.
+	for i := 0; i <= 5; i++ {
+		first |= bit(m[i][8], i)
+	}
+	first |= bit(m[7][8], 6) | bit(m[8][8], 7) | bit(m[8][7], 8)
+	for i := 9; i <= 14; i++ {
+		first |= bit(m[8][14-i], i)
+	}
+
+	// Split between the top-right and bottom-left finder patterns.
+	for i := 0; i <= 7; i++ {
+		second |= bit(m[8][n-1-i], i)
+	}
+	for i := 8; i <= 14; i++ {
+		second |= bit(m[n-15+i][8], i)
+	}
+
+	best, bestDistance := -1, 16
+	for value := 0; value < 32; value++ {
+		word := formatWord(value)
+		d := min(bits.OnesCount(first^word), bits.OnesCount(second^word))
+		if d < bestDistance {
+			best, bestDistance = value, d
+		}
+	}
+	if bestDistance > 3 {
+		return 0, 0, fmt.Errorf("%w: format information is damaged", ErrUnreadable)
+	}
+	return formatLevels[best>>3], best & 7, nil
+}
+
+// formatWord returns the masked 15-bit BCH format word for the 5-bit
+// value (error correction level bits followed by the mask pattern).
+func formatWord(value int) uint {
+	word := uint(value) << 10
+	for i := 14; i >= 10; i-- {
+		if word&(1<<i) != 0 {
+			word ^= 0x537 << (i - 10)
+		}
+	}
+	return (uint(value)<<10 | word) ^ 0x5412
+}
+
+// masked reports whether mask pattern mask inverts the module at (x, y).
+func masked(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (y+x)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (y+x)%3 == 0
+	case 4:
+		return (y/2+x/3)%2 == 0
+	case 5:
+		return (y*x)%2+(y*x)%3 == 0
+	case 6:
+		return ((y*x)%2+(y*x)%3)%2 == 0
+	default:
+		return ((y+x)%2+(y*x)%3)%2 == 0
+	}
+}
+
+// correctBlocks de-interleaves the codewords into their blocks, repairs
+// each block and returns the data codewords in order.
+func correctBlocks(codewords []byte, specs []blockSpec) ([]byte, int, error) {
+	var blocks [][]byte
+	var dataLens []int
+	ecLen := specs[0].total - specs[0].data
+	maxData := 0
+	for _, s := range specs {
+		for i := 0; i < s.count; i++ {
+			blocks = append(blocks, make([]byte, 0, s.total))
+			dataLens = append(dataLens, s.data)
+		}
+		maxData = max(maxData, s.data)
+	}
+
+	pos := 0
+	next := func() (byte, error) {
+		if pos >= len(codewords) {
+			return 0, fmt.Errorf("%w: symbol is truncated", ErrUnreadable)
+		}
+		pos++
+		return codewords[pos-1], nil
+	}
+	for i := 0; i < maxData; i++ {
+		for b := range blocks {
+			if i < dataLens[b] {
+				c, err := next()
+				if err != nil {
+					return nil, 0, err
+				}
+				blocks[b] = append(blocks[b], c)
+			}
+		}
+	}
+	for i := 0; i < ecLen; i++ {
+		for b := range blocks {
+			c, err := next()
+			if err != nil {
+				return nil, 0, err
+			}
+			blocks[b] = append(blocks[b], c)
+		}
+	}
+
+	var data []byte
+	corrected := 0
+	for b, block := range blocks {
+		fixed, err := rsCorrect(block, ecLen)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: block %d: %w", ErrUnreadable, b+1, err)
+		}
+		corrected += fixed
+		data = append(data, block[:dataLens[b]]...)
+	}
+	return data, corrected, nil
+}
+
+// bitReader reads big-endian bit fields from a byte slice.
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) remaining() int {
+	return len(r.data)*8 - r.pos
+}
+
+func (r *bitReader) read(n int) (int, error) {
+	if n > r.remaining() {
+		return 0, fmt.Errorf("%w: data ends early", ErrUnreadable)
+	}
+	v := 0
+	for i := 0; i < n; i++ {
+		b := r.data[r.pos/8] >> (7 - r.pos%8) & 1
+		v = v<<1 | int(b)
+		r.pos++
+	}
+	return v, nil
+}
+
+const alphanumericChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// parseSegments decodes the data segments of a symbol. Numeric,
+// alphanumeric and byte segments are supported; ECI designators are
+// skipped and byte segments are taken as UTF-8.
+func parseSegments(data []byte, version int) (string, error) {
+	r := &bitReader{data: data}
+	sizeClass := 0
+	switch {
+	case version >= 27:
+		sizeClass = 2
+	case version >= 10:
+		sizeClass = 1
+	}
+
+	var out []byte
+	for r.remaining() >= 4 {
+		mode, _ := r.read(4)
+		switch mode {
+		case 0: // Terminator
+			return string(out), nil
+
+		case 1: // Numeric: groups of three digits in 10 bits
+			count, err := r.read([]int{10, 12, 14}[sizeClass])
+			if err != nil {
+				return "", err
+			}
+			for count > 0 {
+				digits, width := 3, 10
+				if count == 2 {
+					digits, width = 2, 7
+				} else if count == 1 {
+					digits, width = 1, 4
+				}
+				v, err := r.read(width)
+				if err != nil {
+					return "", err
+				}
+				out = append(out, fmt.Sprintf("%0*d", digits, v)...)
+				count -= digits
+			}
+
+		case 2: // Alphanumeric: pairs in 11 bits
+			count, err := r.read([]int{9, 11, 13}[sizeClass])
+			if err != nil {
+				return "", err
+			}
+			for ; count >= 2; count -= 2 {
+				v, err := r.read(11)
+				if err != nil || v >= 45*45 {
+					return "", fmt.Errorf("%w: invalid alphanumeric data", ErrUnreadable)
+				}
+				out = append(out, alphanumericChars[v/45], alphanumericChars[v%45])
+			}
+			if count == 1 {
+				v, err := r.read(6)
+				if err != nil || v >= 45 {
+					return "", fmt.Errorf("%w: invalid alphanumeric data", ErrUnreadable)
+				}
+				out = append(out, alphanumericChars[v])
+			}
+
+		case 4: // Byte
+			count, err := r.read([]int{8, 16, 16}[sizeClass])
+			if err != nil {
+				return "", err
+			}
+			for ; count > 0; count-- {
+				v, err := r.read(8)
+				if err != nil {
+					return "", err
+				}
+				out = append(out, byte(v))
+			}
+
+		case 7: // ECI designator: 1-3 bytes, length given by the leading bits
+			first, err := r.read(8)
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case first&0x80 == 0:
+			case first&0xC0 == 0x80:
+				_, err = r.read(8)
+			default:
+				_, err = r.read(16)
+			}
+			if err != nil {
+				return "", err
+			}
+
+		default:
+			return "", fmt.Errorf("%w: unsupported segment mode %d", ErrUnreadable, mode)
+		}
+	}
+	return string(out), nil
+}
@@ -0,0 +1,113 @@
+package halftone
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+var decodeContents = []struct {
+	name    string
+	content string
+}{
+	{"numeric", "31415926535897932384"},
+	{"alphanumeric", "HELLO WORLD $%*+-./:"},
+	{"byte", "https://example.com/path?q=1&lang=en"},
+	{"utf8", "Grüße aus Zürich – 日本語"},
+	{"long", strings.Repeat("halftone ", 40)},
+}
+
+var decodeLevels = []struct {
+	name  string
+	level qrcode.RecoveryLevel
+}{
+	{"L", qrcode.Low},
+	{"M", qrcode.Medium},
+	{"Q", qrcode.High},
+	{"H", qrcode.Highest},
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	for _, c := range decodeContents {
+		for _, l := range decodeLevels {
+			t.Run(c.name+"/"+l.name, func(t *testing.T) {
+				matrix := encodeMatrix(t, c.content, l.level)
+				got, corrected, err := Decode(matrix)
+				if err != nil {
+					t.Fatalf("Decode: %v", err)
+				}
+				if got != c.content {
+					t.Errorf("Decode = %q, want %q", got, c.content)
+				}
+				if corrected != 0 {
+					t.Errorf("corrected %d codewords of a clean matrix", corrected)
+				}
+			})
+		}
+	}
+}
+
+func TestDecodeCorrectsFlippedModules(t *testing.T) {
+	for _, c := range decodeContents {
+		for _, l := range decodeLevels {
+			t.Run(c.name+"/"+l.name, func(t *testing.T) {
+				matrix := encodeMatrix(t, c.content, l.level)
+				// The bottom 8 rows of the two rightmost columns hold the
+				// first two codewords, which every block can repair.
+				n := len(matrix)
+				for y := n - 8; y < n; y++ {
+					matrix[y][n-1] = !matrix[y][n-1]
+					matrix[y][n-2] = !matrix[y][n-2]
+				}
+
+				got, corrected, err := Decode(matrix)
+				if err != nil {
+					t.Fatalf("Decode: %v", err)
+				}
+				if got != c.content {
+					t.Errorf("Decode = %q, want %q", got, c.content)
+				}
+				if corrected != 2 {
+					t.Errorf("corrected %d codewords, want 2", corrected)
+				}
+			})
+		}
+	}
+}
+
+func TestDecodeTooManyFlippedModules(t *testing.T) {
+	for _, l := range decodeLevels {
+		t.Run(l.name, func(t *testing.T) {
+			matrix := encodeMatrix(t, "https://example.com", l.level)
+			function, err := functionModules(len(matrix))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for y, row := range matrix {
+				for x := range row {
+					if !function[y][x] {
+						row[x] = !row[x]
+					}
+				}
+			}
+
+			if _, _, err := Decode(matrix); !errors.Is(err, ErrUnreadable) {
+				t.Errorf("Decode error = %v, want ErrUnreadable", err)
+			}
+		})
+	}
+}
+
+// encodeMatrix encodes content and returns its module matrix without the
+// quiet zone.
+func encodeMatrix(t *testing.T, content string, level qrcode.RecoveryLevel) [][]bool {
+	t.Helper()
+	qrc, err := qrcode.New(content, level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qrc.DisableBorder = true
+	return qrc.Bitmap()
+}
@@ -0,0 +1,200 @@
+// Package halftone renders artistic QR codes blended with a background
+// image, and verifies that the result still decodes.
+//
+// Every module is split into 3x3 sub-cells. The center sub-cell keeps the
+// module's true color, which is where scanners sample; the eight
+// surrounding sub-cells are dithered from the background image, so the
+// picture shows through. Function patterns (finders, timing, alignment,
+// format and version information) are drawn as solid modules because
+// scanners rely on them to locate and read the code, and the quiet zone
+// is left plain.
+package halftone
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // Register decoders for background images
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+)
+
+// subCells is the number of sub-cells along each side of a module.
+const subCells = 3
+
+// Options controls halftone rendering.
+type Options struct {
+	Foreground color.RGBA
+	Background color.RGBA
+	Size       int // Output width and height in pixels
+}
+
+// LoadImage reads a PNG, JPEG or GIF background image.
+func LoadImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open background image: %w", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode background image %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// Render draws the module matrix bitmap (indexed [y][x], including the
+// quiet zone) over background as a halftone image.
+func Render(bitmap [][]bool, background image.Image, opts Options) (*image.RGBA, error) {
+	modules := len(bitmap)
+	quiet, function, err := layout(bitmap)
+	if err != nil {
+		return nil, err
+	}
+	cell, offset, err := geometry(opts.Size, modules)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tone of every sub-cell inside the quiet zone, 0 (dark) to 1 (light).
+	inner := (modules - 2*quiet) * subCells
+	tone := sampleTones(background, inner)
+
+	// Sub-cells decided by the code: all of a function module, and the
+	// center of every data module. The rest follow the image.
+	dark := make([][]bool, inner)
+	for sy := range dark {
+		dark[sy] = make([]bool, inner)
+	}
+	for sy := 0; sy < inner; sy++ {
+		for sx := 0; sx < inner; sx++ {
+			mx, my := sx/subCells, sy/subCells
+			module := bitmap[my+quiet][mx+quiet]
+			fixed := function[my][mx] || (sx%subCells == 1 && sy%subCells == 1)
+
+			var value float64
+			if fixed {
+				dark[sy][sx] = module
+				if !module {
+					value = 1
+				}
+			} else {
+				dark[sy][sx] = tone[sy][sx] < 0.5
+				if !dark[sy][sx] {
+					value = 1
+				}
+			}
+
+			// Floyd-Steinberg error diffusion. Fixed sub-cells pass their
+			// error on too, so the image tone is preserved around them.
+			diffuse(tone, sx, sy, tone[sy][sx]-value)
+		}
+	}
+
+	size := opts.Size
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.SetRGBA(x, y, opts.Background)
+		}
+	}
+	origin := offset + quiet*subCells*cell
+	for sy := 0; sy < inner; sy++ {
+		for sx := 0; sx < inner; sx++ {
+			if !dark[sy][sx] {
+				continue
+			}
+			for y := 0; y < cell; y++ {
+				for x := 0; x < cell; x++ {
+					img.SetRGBA(origin+sx*cell+x, origin+sy*cell+y, opts.Foreground)
+				}
+			}
+		}
+	}
+	return img, nil
+}
+
+// layout returns the quiet zone width and function pattern mask of a
+// module matrix that includes its quiet zone.
+func layout(bitmap [][]bool) (quiet int, function [][]bool, err error) {
+	modules := len(bitmap)
+	for quiet = 4; quiet >= 0; quiet-- {
+		if _, err := versionForSize(modules - 2*quiet); err == nil {
+			function, err = functionModules(modules - 2*quiet)
+			return quiet, function, err
+		}
+	}
+	return 0, nil, fmt.Errorf("%d modules is not a valid QR code size", modules)
+}
+
+// geometry returns the sub-cell size in pixels and the offset that
+// centers the code. Unlike go-qrcode's plain images, which map each pixel
+// to the nearest module and fill the canvas, every sub-cell gets the same
+// whole number of pixels so the dither pattern stays even; the remainder
+// becomes a background margin. Every sub-cell needs at least one pixel,
+// so smaller sizes are rejected rather than producing an image larger
+// than requested.
+func geometry(size, modules int) (cell, offset int, err error) {
+	cells := modules * subCells
+	if size < cells {
+		return 0, 0, fmt.Errorf("size %d is too small for a halftone code of %d modules: use at least %d pixels",
+			size, modules, cells)
+	}
+	cell = size / cells
+	return cell, (size - cell*cells) / 2, nil
+}
+
+// sampleTones scales the center square of img to n x n tones between 0
+// (black) and 1 (white) by averaging the source pixels under each cell.
+func sampleTones(img image.Image, n int) [][]float64 {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+
+	sum := make([][]float64, n)
+	count := make([][]int, n)
+	for i := range sum {
+		sum[i] = make([]float64, n)
+		count[i] = make([]int, n)
+	}
+	for y := 0; y < side; y++ {
+		ty := y * n / side
+		for x := 0; x < side; x++ {
+			tx := x * n / side
+			gray := color.GrayModel.Convert(img.At(x0+x, y0+y)).(color.Gray)
+			sum[ty][tx] += float64(gray.Y) / 255
+			count[ty][tx]++
+		}
+	}
+
+	// Upscaled images leave gaps; fill them from the nearest source pixel.
+	for ty := 0; ty < n; ty++ {
+		for tx := 0; tx < n; tx++ {
+			if count[ty][tx] == 0 {
+				gray := color.GrayModel.Convert(img.At(x0+tx*side/n, y0+ty*side/n)).(color.Gray)
+				sum[ty][tx] = float64(gray.Y) / 255
+			} else {
+				sum[ty][tx] /= float64(count[ty][tx])
+			}
+		}
+	}
+	return sum
+}
+
+// diffuse spreads a quantization error to the unvisited neighbours of
+// (x, y) with Floyd-Steinberg weights.
+func diffuse(tone [][]float64, x, y int, err float64) {
+	n := len(tone)
+	add := func(dx, dy int, weight float64) {
+		if nx, ny := x+dx, y+dy; nx >= 0 && nx < n && ny < n {
+			tone[ny][nx] += err * weight
+		}
+	}
+	add(1, 0, 7.0/16)
+	add(-1, 1, 3.0/16)
+	add(0, 1, 5.0/16)
+	add(1, 1, 1.0/16)
+}
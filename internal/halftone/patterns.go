@@ -0,0 +1,78 @@
+package halftone
+
+import "fmt"
+
+// alignmentCenters lists the row/column coordinates of alignment pattern
+// centers for each version (ISO/IEC 18004, Annex E).
+var alignmentCenters = [41][]int{
+	{}, {},
+	{6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34},
+	{6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50}, {6, 30, 54}, {6, 32, 58}, {6, 34, 62},
+	{6, 26, 46, 66}, {6, 26, 48, 70}, {6, 26, 50, 74}, {6, 30, 54, 78}, {6, 30, 56, 82}, {6, 30, 58, 86}, {6, 34, 62, 90},
+	{6, 28, 50, 72, 94}, {6, 26, 50, 74, 98}, {6, 30, 54, 78, 102}, {6, 28, 54, 80, 106}, {6, 32, 58, 84, 110}, {6, 30, 58, 86, 114}, {6, 34, 62, 90, 118},
+	{6, 26, 50, 74, 98, 122}, {6, 30, 54, 78, 102, 126}, {6, 26, 52, 78, 104, 130}, {6, 30, 56, 82, 108, 134}, {6, 34, 60, 86, 112, 138}, {6, 30, 58, 86, 114, 142}, {6, 34, 62, 90, 118, 146},
+	{6, 30, 54, 78, 102, 126, 150}, {6, 24, 50, 76, 102, 128, 154}, {6, 28, 54, 80, 106, 132, 158}, {6, 32, 58, 84, 110, 136, 162}, {6, 26, 54, 82, 110, 138, 166}, {6, 30, 58, 86, 114, 142, 170},
+}
+
+// versionForSize returns the QR version of a symbol n modules wide,
+// excluding the quiet zone.
+func versionForSize(n int) (int, error) {
+	if n < 21 || n > 177 || (n-17)%4 != 0 {
+		return 0, fmt.Errorf("%d modules is not a valid QR code size", n)
+	}
+	return (n - 17) / 4, nil
+}
+
+// functionModules marks the modules of an n x n symbol (excluding the
+// quiet zone) that belong to function patterns: finder patterns and their
+// separators, timing patterns, alignment patterns, format and version
+// information. These carry no data and must stay intact for a scanner to
+// locate and read the code. The result is indexed [y][x].
+func functionModules(n int) ([][]bool, error) {
+	version, err := versionForSize(n)
+	if err != nil {
+		return nil, err
+	}
+
+	f := make([][]bool, n)
+	for y := range f {
+		f[y] = make([]bool, n)
+	}
+	fill := func(x0, y0, w, h int) {
+		for y := y0; y < y0+h; y++ {
+			for x := x0; x < x0+w; x++ {
+				if x >= 0 && y >= 0 && x < n && y < n {
+					f[y][x] = true
+				}
+			}
+		}
+	}
+
+	// Finder patterns with separators, plus the format information strips
+	// next to them (row and column 8) and the dark module.
+	fill(0, 0, 9, 9)
+	fill(n-8, 0, 8, 9)
+	fill(0, n-8, 9, 8)
+
+	// Alignment patterns, except where they would overlap a finder.
+	centers := alignmentCenters[version]
+	for _, cy := range centers {
+		for _, cx := range centers {
+			if !f[cy][cx] {
+				fill(cx-2, cy-2, 5, 5)
+			}
+		}
+	}
+
+	// Timing patterns.
+	fill(6, 0, 1, n)
+	fill(0, 6, n, 1)
+
+	// Version information (versions 7 and up).
+	if version >= 7 {
+		fill(n-11, 0, 3, 6)
+		fill(0, n-11, 6, 3)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,170 @@
+package halftone
+
+import "errors"
+
+// errUncorrectable is returned when a block has more errors than its
+// error correction codewords can repair.
+var errUncorrectable = errors.New("too many errors to correct")
+
+// GF(256) arithmetic with the QR code primitive polynomial
+// x^8 + x^4 + x^3 + x^2 + 1.
+var gfExp, gfLog = func() ([512]byte, [256]byte) {
+	var exp [512]byte
+	var log [256]byte
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	for i := 255; i < 512; i++ {
+		exp[i] = exp[i-255]
+	}
+	return exp, log
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// gfPow returns α^e for any integer e.
+func gfPow(e int) byte {
+	e %= 255
+	if e < 0 {
+		e += 255
+	}
+	return gfExp[e]
+}
+
+// polyEval evaluates a polynomial with coefficients in ascending order
+// of degree at x.
+func polyEval(p []byte, x byte) byte {
+	var y byte
+	for i := len(p) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ p[i]
+	}
+	return y
+}
+
+// rsCorrect repairs a Reed-Solomon block in place. The block holds data
+// codewords followed by ecLen error correction codewords, highest degree
+// first, as they appear in the symbol. It returns the number of corrected
+// codewords.
+func rsCorrect(block []byte, ecLen int) (int, error) {
+	n := len(block)
+
+	// Codeword i is the coefficient of x^(n-1-i).
+	received := make([]byte, n)
+	for i, c := range block {
+		received[n-1-i] = c
+	}
+
+	syndromes := make([]byte, ecLen)
+	clean := true
+	for j := range syndromes {
+		syndromes[j] = polyEval(received, gfPow(j))
+		if syndromes[j] != 0 {
+			clean = false
+		}
+	}
+	if clean {
+		return 0, nil
+	}
+
+	// Berlekamp-Massey: find the error locator polynomial.
+	locator := []byte{1}
+	prev := []byte{1}
+	errs, shift := 0, 1
+	prevDelta := byte(1)
+	for k := 0; k < ecLen; k++ {
+		delta := syndromes[k]
+		for i := 1; i <= errs && i < len(locator); i++ {
+			delta ^= gfMul(locator[i], syndromes[k-i])
+		}
+		if delta == 0 {
+			shift++
+			continue
+		}
+
+		scale := gfDiv(delta, prevDelta)
+		next := make([]byte, max(len(locator), len(prev)+shift))
+		copy(next, locator)
+		for i, c := range prev {
+			next[i+shift] ^= gfMul(scale, c)
+		}
+
+		if 2*errs <= k {
+			prev, prevDelta = locator, delta
+			errs = k + 1 - errs
+			shift = 1
+		} else {
+			shift++
+		}
+		locator = next
+	}
+	for len(locator) > 1 && locator[len(locator)-1] == 0 {
+		locator = locator[:len(locator)-1]
+	}
+	if errs == 0 || len(locator)-1 != errs || 2*errs > ecLen {
+		return 0, errUncorrectable
+	}
+
+	// Chien search: degree k is in error if locator(α^-k) == 0.
+	var positions []int
+	for k := 0; k < n; k++ {
+		if polyEval(locator, gfPow(-k)) == 0 {
+			positions = append(positions, k)
+		}
+	}
+	if len(positions) != errs {
+		return 0, errUncorrectable
+	}
+
+	// Forney: error values from the evaluator omega = S(x)·Λ(x) mod x^ecLen
+	// and the formal derivative of Λ.
+	omega := make([]byte, ecLen)
+	for i, s := range syndromes {
+		for j, l := range locator {
+			if i+j < ecLen {
+				omega[i+j] ^= gfMul(s, l)
+			}
+		}
+	}
+	derivative := make([]byte, len(locator))
+	for i := 1; i < len(locator); i += 2 {
+		derivative[i-1] = locator[i]
+	}
+
+	for _, k := range positions {
+		xInv := gfPow(-k)
+		denom := polyEval(derivative, xInv)
+		if denom == 0 {
+			return 0, errUncorrectable
+		}
+		value := gfMul(gfPow(k), gfDiv(polyEval(omega, xInv), denom))
+		received[k] ^= value
+	}
+
+	for j := 0; j < ecLen; j++ {
+		if polyEval(received, gfPow(j)) != 0 {
+			return 0, errUncorrectable
+		}
+	}
+	for i := range block {
+		block[i] = received[n-1-i]
+	}
+	return errs, nil
+}
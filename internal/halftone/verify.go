@@ -0,0 +1,85 @@
+package halftone
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Report describes the result of Verify.
+type Report struct {
+	Modules   int    // Modules checked (the symbol without its quiet zone)
+	Flipped   int    // Modules that read differently from the encoded matrix
+	Corrected int    // Codewords repaired by error correction while decoding
+	Decoded   string // Content read back from the image
+}
+
+// Verify reads a rendered code back the way a scanner would and checks
+// that it decodes to content. The image is sampled on the module grid of
+// bitmap (the encoded matrix, including the quiet zone): each module's tone
+// is weighted towards its center but includes its surroundings, like a
+// slightly blurred camera image, and compared against the tone halfway
+// between fg and bg. The sampled matrix is then decoded with full error
+// correction.
+func Verify(img image.Image, bitmap [][]bool, content string, fg, bg color.RGBA) (Report, error) {
+	modules := len(bitmap)
+	var report Report
+
+	quiet, _, err := layout(bitmap)
+	if err != nil {
+		return report, err
+	}
+
+	b := img.Bounds()
+	cell, offset, err := geometry(b.Dx(), modules)
+	if err != nil {
+		return report, err
+	}
+	moduleSize := cell * subCells
+	fgTone, bgTone := tone(fg), tone(bg)
+	threshold := (fgTone + bgTone) / 2
+
+	n := modules - 2*quiet
+	report.Modules = n * n
+	matrix := make([][]bool, n)
+	for y := 0; y < n; y++ {
+		matrix[y] = make([]bool, n)
+		for x := 0; x < n; x++ {
+			left := b.Min.X + offset + (x+quiet)*moduleSize
+			top := b.Min.Y + offset + (y+quiet)*moduleSize
+			center := meanTone(img, left+cell, top+cell, cell)
+			whole := meanTone(img, left, top, moduleSize)
+			matrix[y][x] = (0.6*center+0.4*whole < threshold) == (fgTone < bgTone)
+			if matrix[y][x] != bitmap[y+quiet][x+quiet] {
+				report.Flipped++
+			}
+		}
+	}
+
+	decoded, corrected, err := Decode(matrix)
+	report.Decoded, report.Corrected = decoded, corrected
+	if err != nil {
+		return report, err
+	}
+	if decoded != content {
+		return report, fmt.Errorf("%w: decoded content does not match", ErrUnreadable)
+	}
+	return report, nil
+}
+
+// meanTone returns the average tone of a size x size square, from 0
+// (black) to 1 (white).
+func meanTone(img image.Image, left, top, size int) float64 {
+	var sum float64
+	for y := top; y < top+size; y++ {
+		for x := left; x < left+size; x++ {
+			sum += tone(img.At(x, y))
+		}
+	}
+	return sum / float64(size*size)
+}
+
+// tone returns the gray level of c, from 0 (black) to 1 (white).
+func tone(c color.Color) float64 {
+	return float64(color.GrayModel.Convert(c).(color.Gray).Y) / 255
+}
@@ -0,0 +1,52 @@
+package halftone
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+func TestVerifyThreshold(t *testing.T) {
+	tests := []struct {
+		name   string
+		fg, bg color.RGBA
+	}{
+		{"black on white", color.RGBA{A: 255}, color.RGBA{R: 255, G: 255, B: 255, A: 255}},
+		{"black on gray", color.RGBA{A: 255}, color.RGBA{R: 0x70, G: 0x70, B: 0x70, A: 255}},
+		{"gray on white", color.RGBA{R: 0x90, G: 0x90, B: 0x90, A: 255}, color.RGBA{R: 255, G: 255, B: 255, A: 255}},
+		{"white on black", color.RGBA{R: 255, G: 255, B: 255, A: 255}, color.RGBA{A: 255}},
+	}
+
+	const content = "https://example.com/verify"
+	qrc, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bitmap := qrc.Bitmap()
+	white := image.NewGray(image.Rect(0, 0, 16, 16))
+	for i := range white.Pix {
+		white.Pix[i] = 255
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Render(bitmap, white, Options{
+				Foreground: tt.fg,
+				Background: tt.bg,
+				Size:       400,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := Verify(img, bitmap, content, tt.fg, tt.bg)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if report.Flipped != 0 {
+				t.Errorf("%d of %d modules misread", report.Flipped, report.Modules)
+			}
+		})
+	}
+}
@@ -12,6 +12,9 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/DalyChouikh/internal/config"
+	"github.com/DalyChouikh/internal/templates"
 )
 
 const (
@@ -28,8 +31,113 @@ type Entry struct {
 	Size       int       `json:"size"`
 	FgColor    string    `json:"fg_color"`
 	BgColor    string    `json:"bg_color"`
+	Level      string    `json:"level,omitempty"`
+	Renderer   string    `json:"renderer,omitempty"`
+	Invert     bool      `json:"invert,omitempty"`
+	SwissCross bool      `json:"swiss_cross,omitempty"`
 	OutputPath string    `json:"output_path"`
 	CreatedAt  time.Time `json:"created_at"`
+
+	// Set when one generation wrote several formats or sizes. Format, Size
+	// and OutputPath describe the first output.
+	Formats []string `json:"formats,omitempty"`
+	Sizes   []int    `json:"sizes,omitempty"`
+	Outputs []string `json:"outputs,omitempty"`
+
+	DPI int `json:"dpi,omitempty"` // Print resolution stored in the image metadata
+
+	// Halftone is the absolute path of the background image, so that
+	// regen works from any directory.
+	Halftone string `json:"halftone,omitempty"`
+
+	// Image format and alt text of html, datauri and md output.
+	Embed string `json:"embed,omitempty"`
+	Alt   string `json:"alt,omitempty"`
+
+	// Image styling. Gradient is a hex color and Logo an absolute path.
+	Gradient    string `json:"gradient,omitempty"`
+	ModuleStyle string `json:"module_style,omitempty"`
+	Logo        string `json:"logo,omitempty"`
+	Frame       int    `json:"frame,omitempty"`
+
+	// Animation options, so that re-generating an animated GIF does not
+	// produce a still frame. Colors are hex strings.
+	Animation string   `json:"animation,omitempty"`
+	Frames    int      `json:"frames,omitempty"`
+	Delay     int      `json:"delay,omitempty"`
+	Loop      int      `json:"loop,omitempty"`
+	Payloads  []string `json:"payloads,omitempty"`
+	Colors    []string `json:"colors,omitempty"`
+
+	// Set when Content or a payload had a secret (such as an authenticator
+	// key) replaced by a placeholder. The entry cannot be re-generated.
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// NewEntry builds an entry from a generation's configuration and the files
+// it wrote. Outputs is only recorded when there is more than one file.
+func NewEntry(cfg *config.QRConfig, paths []string) Entry {
+	e := Entry{
+		Content:     cfg.Content,
+		Format:      string(cfg.Format),
+		Size:        cfg.Size,
+		FgColor:     config.ColorToHex(cfg.Foreground),
+		BgColor:     config.ColorToHex(cfg.Background),
+		Level:       string(cfg.Level),
+		Renderer:    string(cfg.Renderer),
+		Invert:      cfg.Invert,
+		SwissCross:  cfg.SwissCross,
+		OutputPath:  cfg.OutputPath,
+		Sizes:       cfg.Sizes,
+		DPI:         cfg.DPI,
+		ModuleStyle: string(cfg.ModuleStyle),
+		Frame:       cfg.Frame,
+		Embed:       string(cfg.Embed),
+		Alt:         cfg.Alt,
+	}
+	for _, f := range cfg.Formats {
+		e.Formats = append(e.Formats, string(f))
+	}
+	if a := cfg.Animation; a.Mode != config.AnimationNone {
+		e.Animation = string(a.Mode)
+		e.Frames, e.Delay, e.Loop = a.Frames, a.Delay, a.Loop
+		e.Payloads = append([]string(nil), a.Payloads...)
+		for _, c := range a.Colors {
+			e.Colors = append(e.Colors, config.ColorToHex(c))
+		}
+	}
+	if cfg.Halftone != "" {
+		e.Halftone = absPath(cfg.Halftone)
+	}
+	if cfg.Gradient.A != 0 {
+		e.Gradient = config.ColorToHex(cfg.Gradient)
+	}
+	if cfg.Logo != "" {
+		e.Logo = absPath(cfg.Logo)
+	}
+	if len(paths) == 1 {
+		e.OutputPath = paths[0] // The collision-free path actually written
+	}
+	if len(paths) > 1 {
+		e.Outputs = paths
+	}
+	return e
+}
+
+// absPath returns path made absolute, or path itself if that fails.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// FormatLabel returns the entry's format for display, e.g. "PNG+SVG".
+func (e Entry) FormatLabel() string {
+	if len(e.Formats) == 0 {
+		return strings.ToUpper(e.Format)
+	}
+	return strings.ToUpper(strings.Join(e.Formats, "+"))
 }
 
 // Store manages the history file.
@@ -54,8 +162,18 @@ func NewStore() (*Store, error) {
 	return s, nil
 }
 
-// Add adds a new entry to the history.
+// Add adds a new entry to the history. Secrets in the content and payloads
+// are redacted first, so they are never written to the history file.
 func (s *Store) Add(entry Entry) error {
+	if redacted := templates.RedactSecrets(entry.Content); redacted != entry.Content {
+		entry.Content, entry.Redacted = redacted, true
+	}
+	for i, p := range entry.Payloads {
+		if redacted := templates.RedactSecrets(p); redacted != p {
+			entry.Payloads[i], entry.Redacted = redacted, true
+		}
+	}
+
 	// Assign next ID
 	maxID := 0
 	for _, e := range s.entries {
@@ -111,7 +229,7 @@ func FormatEntry(e Entry) string {
 	return fmt.Sprintf("#%-3d  %s  %s  %dx%d  %s  %s",
 		e.ID,
 		e.CreatedAt.Format("2006-01-02 15:04"),
-		strings.ToUpper(e.Format),
+		e.FormatLabel(),
 		e.Size, e.Size,
 		content,
 		e.OutputPath,
@@ -125,7 +243,7 @@ func (s *Store) FormatTable() string {
 	}
 
 	var b strings.Builder
-	b.WriteString(fmt.Sprintf("%-4s  %-16s  %-4s  %-7s  %-50s  %s\n",
+	b.WriteString(fmt.Sprintf("%-4s  %-16s  %-7s  %-7s  %-50s  %s\n",
 		"ID", "Date", "Fmt", "Size", "Content", "Output"))
 	b.WriteString(strings.Repeat("─", 120) + "\n")
 
@@ -137,10 +255,10 @@ func (s *Store) FormatTable() string {
 		content = strings.ReplaceAll(content, "\r\n", " ")
 		content = strings.ReplaceAll(content, "\n", " ")
 
-		b.WriteString(fmt.Sprintf("#%-3d  %s  %-4s  %dx%-4d  %-50s  %s\n",
+		b.WriteString(fmt.Sprintf("#%-3d  %s  %-7s  %dx%-4d  %-50s  %s\n",
 			e.ID,
 			e.CreatedAt.Format("2006-01-02 15:04"),
-			strings.ToUpper(e.Format),
+			e.FormatLabel(),
 			e.Size, e.Size,
 			content,
 			e.OutputPath,
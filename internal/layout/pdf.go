@@ -0,0 +1,173 @@
+package layout
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// WritePDF writes every sheet as one page of a PDF document.
+//
+// The document uses only PDF 1.4 basics: filled rectangles for modules,
+// stroked lines for crop marks and the standard Helvetica font for
+// captions, so it needs no embedded resources and prints anywhere.
+func WritePDF(w io.Writer, items []Item, opts Options) error {
+	sheets, err := paginate(items, opts)
+	if err != nil {
+		return err
+	}
+
+	t := opts.Template
+	pageW, pageH := mmToPoints(t.Page.Width), mmToPoints(t.Page.Height)
+
+	// Object numbers: 1 catalog, 2 page tree, 3 font, then a page object
+	// and its content stream for every sheet.
+	pdf := &pdfWriter{}
+	pdf.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, len(sheets))
+	for i := range sheets {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	pdf.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	pdf.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(sheets)))
+	pdf.object(3, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+
+	for i, s := range sheets {
+		pageObj, contentObj := 4+2*i, 5+2*i
+		pdf.object(pageObj, fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pageW, pageH, contentObj))
+
+		content := pageContent(s, opts, pageH)
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		if _, err := zw.Write(content); err != nil {
+			return fmt.Errorf("failed to compress page: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to compress page: %w", err)
+		}
+		pdf.stream(contentObj, z.Bytes())
+	}
+
+	pdf.finish()
+	if _, err := w.Write(pdf.buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+// pageContent returns the content stream drawing one sheet. PDF places
+// the origin at the bottom-left, so y positions are flipped.
+func pageContent(s sheet, opts Options, pageH float64) []byte {
+	var b bytes.Buffer
+	fontSize := opts.FontSize
+	if fontSize <= 0 {
+		fontSize = defaultFontSize
+	}
+
+	for _, p := range s.placements {
+		x, top, size := mmToPoints(p.x), pageH-mmToPoints(p.y), mmToPoints(p.size)
+
+		fmt.Fprintf(&b, "%s %.2f %.2f %.2f %.2f re f\n", pdfColor(opts.Background), x, top-size, size, size)
+
+		// Scale so one unit is one module, with y pointing down.
+		module := size / float64(len(p.bitmap))
+		fmt.Fprintf(&b, "q %.4f 0 0 %.4f %.2f %.2f cm %s\n", module, -module, x, top, pdfColor(opts.Foreground))
+		runs(p.bitmap, func(mx, my, n int) {
+			fmt.Fprintf(&b, "%d %d %d 1 re\n", mx, my, n)
+		})
+		b.WriteString("f\n")
+		if p.cross {
+			for _, c := range swissCross(len(p.bitmap)) {
+				col := opts.Background
+				if c.dark {
+					col = opts.Foreground
+				}
+				fmt.Fprintf(&b, "%s %.4f %.4f %.4f %.4f re f\n", pdfColor(col), c.x, c.y, c.w, c.h)
+			}
+		}
+		b.WriteString("Q\n")
+
+		if p.caption != "" {
+			width := mmToPoints(textWidth(p.caption, fontSize))
+			fmt.Fprintf(&b, "BT 0 g /F1 %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+				fontSize, mmToPoints(p.textX)-width/2, pageH-mmToPoints(p.textY), pdfString(p.caption))
+		}
+	}
+
+	if opts.CropMarks {
+		b.WriteString("0 G 0.25 w\n")
+		for _, l := range cropMarks(opts.Template) {
+			fmt.Fprintf(&b, "%.2f %.2f m %.2f %.2f l S\n",
+				mmToPoints(l.x1), pageH-mmToPoints(l.y1), mmToPoints(l.x2), pageH-mmToPoints(l.y2))
+		}
+	}
+
+	return b.Bytes()
+}
+
+// pdfColor returns the fill color operator for c.
+func pdfColor(c color.RGBA) string {
+	return fmt.Sprintf("%.3f %.3f %.3f rg", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+// pdfString escapes s for a PDF literal string in WinAnsi encoding.
+// Characters outside Latin-1 are replaced with '?'.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// pdfWriter assembles objects and the cross-reference table.
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int // offsets[n-1] is the byte offset of object n
+}
+
+func (p *pdfWriter) begin(n int) {
+	for len(p.offsets) < n {
+		p.offsets = append(p.offsets, 0)
+	}
+	p.offsets[n-1] = p.buf.Len()
+	fmt.Fprintf(&p.buf, "%d 0 obj\n", n)
+}
+
+func (p *pdfWriter) object(n int, body string) {
+	p.begin(n)
+	p.buf.WriteString(body)
+	p.buf.WriteString("\nendobj\n")
+}
+
+func (p *pdfWriter) stream(n int, data []byte) {
+	p.begin(n)
+	fmt.Fprintf(&p.buf, "<< /Length %d /Filter /FlateDecode >>\nstream\n", len(data))
+	p.buf.Write(data)
+	p.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func (p *pdfWriter) finish() {
+	xref := p.buf.Len()
+	fmt.Fprintf(&p.buf, "xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, off := range p.offsets {
+		fmt.Fprintf(&p.buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&p.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, xref)
+}
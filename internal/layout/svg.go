@@ -0,0 +1,90 @@
+package layout
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+)
+
+// SVGPages lays out items and returns one SVG document per page. Units are
+// millimetres, so the documents print at the template's physical size.
+func SVGPages(items []Item, opts Options) ([][]byte, error) {
+	sheets, err := paginate(items, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([][]byte, len(sheets))
+	for i, s := range sheets {
+		var b bytes.Buffer
+		if err := writeSVGPage(&b, s, opts); err != nil {
+			return nil, err
+		}
+		pages[i] = b.Bytes()
+	}
+	return pages, nil
+}
+
+func writeSVGPage(w io.Writer, s sheet, opts Options) error {
+	t := opts.Template
+	fontSize := opts.FontSize
+	if fontSize <= 0 {
+		fontSize = defaultFontSize
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%gmm" height="%gmm" viewBox="0 0 %g %g">
+`, t.Page.Width, t.Page.Height, t.Page.Width, t.Page.Height)
+
+	for _, p := range s.placements {
+		fmt.Fprintf(&b, `  <rect x="%.3f" y="%.3f" width="%.3f" height="%.3f" fill="%s"/>
+`, p.x, p.y, p.size, p.size, svgColor(opts.Background))
+
+		module := p.size / float64(len(p.bitmap))
+		fmt.Fprintf(&b, `  <g transform="translate(%.3f %.3f) scale(%.5f)" fill="%s" shape-rendering="crispEdges">
+`, p.x, p.y, module, svgColor(opts.Foreground))
+		runs(p.bitmap, func(x, y, n int) {
+			fmt.Fprintf(&b, `    <rect x="%d" y="%d" width="%d" height="1"/>
+`, x, y, n)
+		})
+		if p.cross {
+			for _, c := range swissCross(len(p.bitmap)) {
+				col := opts.Background
+				if c.dark {
+					col = opts.Foreground
+				}
+				fmt.Fprintf(&b, `    <rect x="%.4f" y="%.4f" width="%.4f" height="%.4f" fill="%s"/>
+`, c.x, c.y, c.w, c.h, svgColor(col))
+			}
+		}
+		b.WriteString("  </g>\n")
+
+		if p.caption != "" {
+			fmt.Fprintf(&b, `  <text x="%.3f" y="%.3f" font-family="Helvetica, Arial, sans-serif" font-size="%.3f" text-anchor="middle">%s</text>
+`, p.textX, p.textY, pointsToMM(fontSize), html.EscapeString(p.caption))
+		}
+	}
+
+	if opts.CropMarks {
+		b.WriteString(`  <g stroke="#000" stroke-width="0.1">` + "\n")
+		for _, l := range cropMarks(t) {
+			fmt.Fprintf(&b, `    <line x1="%.3f" y1="%.3f" x2="%.3f" y2="%.3f"/>
+`, l.x1, l.y1, l.x2, l.y2)
+		}
+		b.WriteString("  </g>\n")
+	}
+
+	b.WriteString("</svg>\n")
+
+	if _, err := w.Write(b.Bytes()); err != nil {
+		return fmt.Errorf("failed to write SVG: %w", err)
+	}
+	return nil
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
@@ -0,0 +1,166 @@
+package layout
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Page is a paper size in millimetres.
+type Page struct {
+	Name   string
+	Width  float64
+	Height float64
+}
+
+// Supported paper sizes.
+var (
+	A4     = Page{Name: "A4", Width: 210, Height: 297}
+	Letter = Page{Name: "Letter", Width: 215.9, Height: 279.4}
+)
+
+// ParsePage returns the paper size for "a4" or "letter".
+func ParsePage(name string) (Page, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "a4":
+		return A4, nil
+	case "letter":
+		return Letter, nil
+	}
+	return Page{}, fmt.Errorf("unknown page size %q (available: a4, letter)", name)
+}
+
+// Template describes a grid of labels on a page. All lengths are in
+// millimetres, measured from the top-left corner of the page.
+type Template struct {
+	Name        string
+	Description string
+	Page        Page
+	Columns     int
+	Rows        int
+	LabelWidth  float64
+	LabelHeight float64
+	MarginTop   float64 // Distance from the page top to the first row
+	MarginLeft  float64 // Distance from the page edge to the first column
+	GutterX     float64 // Horizontal gap between labels
+	GutterY     float64 // Vertical gap between labels
+}
+
+// builtinTemplates are common label sheets. Dimensions follow the
+// manufacturers' published specifications.
+var builtinTemplates = []Template{
+	{
+		Name: "avery-l7160", Description: "A4, 21 labels (3x7), 63.5 x 38.1 mm",
+		Page: A4, Columns: 3, Rows: 7, LabelWidth: 63.5, LabelHeight: 38.1,
+		MarginTop: 15.15, MarginLeft: 7.25, GutterX: 2.5,
+	},
+	{
+		Name: "avery-l7163", Description: "A4, 14 labels (2x7), 99.1 x 38.1 mm",
+		Page: A4, Columns: 2, Rows: 7, LabelWidth: 99.1, LabelHeight: 38.1,
+		MarginTop: 15.15, MarginLeft: 4.65, GutterX: 2.5,
+	},
+	{
+		Name: "avery-l7651", Description: "A4, 65 labels (5x13), 38.1 x 21.2 mm",
+		Page: A4, Columns: 5, Rows: 13, LabelWidth: 38.1, LabelHeight: 21.2,
+		MarginTop: 10.7, MarginLeft: 4.75, GutterX: 2.5,
+	},
+	{
+		Name: "avery-5160", Description: "Letter, 30 labels (3x10), 2.625 x 1 in",
+		Page: Letter, Columns: 3, Rows: 10, LabelWidth: 66.675, LabelHeight: 25.4,
+		MarginTop: 12.7, MarginLeft: 4.7625, GutterX: 3.175,
+	},
+	{
+		Name: "avery-5163", Description: "Letter, 10 labels (2x5), 4 x 2 in",
+		Page: Letter, Columns: 2, Rows: 5, LabelWidth: 101.6, LabelHeight: 50.8,
+		MarginTop: 12.7, MarginLeft: 3.96875, GutterX: 4.7625,
+	},
+}
+
+// Templates returns the built-in label templates sorted by name.
+func Templates() []Template {
+	list := append([]Template(nil), builtinTemplates...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// LookupTemplate returns the built-in template with the given name.
+func LookupTemplate(name string) (Template, error) {
+	for _, t := range builtinTemplates {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+	}
+	names := make([]string, 0, len(builtinTemplates))
+	for _, t := range Templates() {
+		names = append(names, t.Name)
+	}
+	return Template{}, fmt.Errorf("unknown label template %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// GridTemplate builds a custom template that divides the page into
+// columns x rows equal labels inside a uniform margin, separated by gutter.
+func GridTemplate(page Page, columns, rows int, margin, gutter float64) (Template, error) {
+	if columns < 1 || rows < 1 {
+		return Template{}, fmt.Errorf("grid must have at least one column and one row")
+	}
+	t := Template{
+		Name:        fmt.Sprintf("grid-%dx%d", columns, rows),
+		Description: fmt.Sprintf("%s, %d labels (%dx%d)", page.Name, columns*rows, columns, rows),
+		Page:        page,
+		Columns:     columns,
+		Rows:        rows,
+		LabelWidth:  (page.Width - 2*margin - float64(columns-1)*gutter) / float64(columns),
+		LabelHeight: (page.Height - 2*margin - float64(rows-1)*gutter) / float64(rows),
+		MarginTop:   margin,
+		MarginLeft:  margin,
+		GutterX:     gutter,
+		GutterY:     gutter,
+	}
+	return t, t.Validate()
+}
+
+// ParseGrid parses a grid definition such as "4x6".
+func ParseGrid(s string) (columns, rows int, err error) {
+	c, r, ok := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "x")
+	if ok {
+		columns, err = strconv.Atoi(c)
+		if err == nil {
+			rows, err = strconv.Atoi(r)
+		}
+	}
+	if !ok || err != nil {
+		return 0, 0, fmt.Errorf("invalid grid %q: expected COLUMNSxROWS, e.g. 4x6", s)
+	}
+	return columns, rows, nil
+}
+
+// Validate checks that the labels are positive and fit on the page.
+func (t Template) Validate() error {
+	if t.Columns < 1 || t.Rows < 1 {
+		return fmt.Errorf("template %s: grid must have at least one column and one row", t.Name)
+	}
+	if t.LabelWidth < 5 || t.LabelHeight < 5 {
+		return fmt.Errorf("template %s: labels must be at least 5 mm wide and high", t.Name)
+	}
+	right := t.MarginLeft + float64(t.Columns)*t.LabelWidth + float64(t.Columns-1)*t.GutterX
+	bottom := t.MarginTop + float64(t.Rows)*t.LabelHeight + float64(t.Rows-1)*t.GutterY
+	if right > t.Page.Width+0.01 || bottom > t.Page.Height+0.01 {
+		return fmt.Errorf("template %s: labels do not fit on a %s page", t.Name, t.Page.Name)
+	}
+	return nil
+}
+
+// PerPage returns the number of labels on one sheet.
+func (t Template) PerPage() int {
+	return t.Columns * t.Rows
+}
+
+// cell returns the top-left corner of label i on a page, filling rows
+// left to right.
+func (t Template) cell(i int) (x, y float64) {
+	col, row := i%t.Columns, i/t.Columns
+	x = t.MarginLeft + float64(col)*(t.LabelWidth+t.GutterX)
+	y = t.MarginTop + float64(row)*(t.LabelHeight+t.GutterY)
+	return x, y
+}
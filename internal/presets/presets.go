@@ -0,0 +1,322 @@
+// Package presets manages named style presets ("brand kits").
+//
+// Presets are stored as JSON in the qrgen config directory (presets.json)
+// and bundle the style options that are otherwise re-picked for every code:
+// colors, gradient, module style, logo, frame, size, format and error
+// correction. Fields left empty in a preset are not decided by it, so the
+// TUI still asks for them and CLI flags or user defaults fill them in.
+package presets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DalyChouikh/internal/config"
+	"github.com/DalyChouikh/internal/fsutil"
+)
+
+const presetsFile = "presets.json"
+
+// Preset is a named set of style options.
+type Preset struct {
+	Name            string `json:"name"`
+	Format          string `json:"format,omitempty"`
+	Size            int    `json:"size,omitempty"`
+	Foreground      string `json:"foreground,omitempty"`
+	Background      string `json:"background,omitempty"`
+	ErrorCorrection string `json:"error_correction,omitempty"`
+	Gradient        string `json:"gradient,omitempty"`     // End color of a gradient from Foreground
+	ModuleStyle     string `json:"module_style,omitempty"` // square, dots or rounded
+	Logo            string `json:"logo,omitempty"`         // Path of an image drawn in the center
+	Frame           int    `json:"frame,omitempty"`        // Border width in modules
+}
+
+// Validate checks the preset name and every option it sets, normalizing
+// colors and levels to their canonical form.
+func (p *Preset) Validate() error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
+
+	var s config.UserSettings
+	values := map[string]string{
+		"format":           p.Format,
+		"foreground":       p.Foreground,
+		"background":       p.Background,
+		"error_correction": p.ErrorCorrection,
+	}
+	for key, value := range values {
+		if err := s.Set(key, value); err != nil {
+			return fmt.Errorf("preset %q: %w", p.Name, err)
+		}
+	}
+	if p.Size != 0 && (p.Size < 64 || p.Size > 4096) {
+		return fmt.Errorf("preset %q: %w", p.Name, config.ErrInvalidSize)
+	}
+	if p.Gradient != "" {
+		c, err := config.ParseHexColor(p.Gradient)
+		if err != nil {
+			return fmt.Errorf("preset %q: invalid gradient color: %w", p.Name, err)
+		}
+		p.Gradient = config.ColorToHex(c)
+	}
+	if p.ModuleStyle != "" {
+		style, err := config.ParseModuleStyle(p.ModuleStyle)
+		if err != nil {
+			return fmt.Errorf("preset %q: %w", p.Name, err)
+		}
+		p.ModuleStyle = string(style)
+	}
+	p.Logo = strings.TrimSpace(p.Logo)
+	if p.Frame < 0 || p.Frame > config.MaxFrame {
+		return fmt.Errorf("preset %q: %w", p.Name, config.ErrInvalidFrame)
+	}
+
+	p.Format = s.Format
+	p.Foreground = s.Foreground
+	p.Background = s.Background
+	p.ErrorCorrection = s.ErrorCorrection
+	return nil
+}
+
+// Apply copies the options set in the preset onto cfg. The output path
+// extension is updated if the preset changes the format.
+func (p *Preset) Apply(cfg *config.QRConfig) {
+	if p.Format != "" {
+		cfg.SetFormats([]config.OutputFormat{config.OutputFormat(p.Format)})
+		if cfg.OutputPath != "" {
+			cfg.SetOutputPath(cfg.OutputPath)
+		}
+	}
+	if p.Size != 0 {
+		cfg.SetSizes([]int{p.Size})
+	}
+	if c, err := config.ParseHexColor(p.Foreground); err == nil {
+		cfg.Foreground = c
+	}
+	if c, err := config.ParseHexColor(p.Background); err == nil {
+		cfg.Background = c
+	}
+	if p.ErrorCorrection != "" {
+		cfg.Level = config.ErrorCorrection(p.ErrorCorrection)
+	}
+	if c, err := config.ParseHexColor(p.Gradient); err == nil {
+		cfg.Gradient = c
+	}
+	if p.ModuleStyle != "" {
+		cfg.ModuleStyle = config.ModuleStyle(p.ModuleStyle)
+	}
+	if p.Logo != "" {
+		cfg.Logo = config.ExpandHome(p.Logo)
+		// The logo hides the center modules; see the -logo flag.
+		if p.ErrorCorrection == "" {
+			cfg.Level = config.ECHigh
+		}
+	}
+	if p.Frame != 0 {
+		cfg.Frame = p.Frame
+	}
+}
+
+// Summary returns a short description of the options the preset sets.
+func (p *Preset) Summary() string {
+	var parts []string
+	if p.Format != "" {
+		parts = append(parts, strings.ToUpper(p.Format))
+	}
+	if p.Size != 0 {
+		parts = append(parts, fmt.Sprintf("%dpx", p.Size))
+	}
+	if p.Foreground != "" || p.Background != "" {
+		fg, bg := p.Foreground, p.Background
+		if fg == "" {
+			fg = "default"
+		}
+		if bg == "" {
+			bg = "default"
+		}
+		parts = append(parts, fg+" on "+bg)
+	}
+	if p.Gradient != "" {
+		parts = append(parts, "gradient to "+p.Gradient)
+	}
+	if p.ModuleStyle != "" && p.ModuleStyle != string(config.ModuleSquare) {
+		parts = append(parts, p.ModuleStyle+" modules")
+	}
+	if p.Logo != "" {
+		parts = append(parts, "logo "+filepath.Base(p.Logo))
+	}
+	if p.Frame != 0 {
+		parts = append(parts, fmt.Sprintf("%d-module frame", p.Frame))
+	}
+	if p.ErrorCorrection != "" {
+		parts = append(parts, "EC "+p.ErrorCorrection)
+	}
+	if len(parts) == 0 {
+		return "no options"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ValidateName checks that name is usable as a preset name: letters,
+// digits, '-', '_' and '.' only.
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("preset name cannot be empty")
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.':
+		default:
+			return fmt.Errorf("invalid preset name %q: use letters, digits, '-', '_' or '.'", name)
+		}
+	}
+	return nil
+}
+
+// Store manages the presets file.
+type Store struct {
+	path    string
+	presets []Preset
+}
+
+// NewStore opens the presets file in the qrgen config directory.
+func NewStore() (*Store, error) {
+	dir, err := config.AppConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine presets path: %w", err)
+	}
+
+	s := &Store{path: filepath.Join(dir, presetsFile)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Path returns the location of the presets file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// List returns all presets sorted by name.
+func (s *Store) List() []Preset {
+	return s.presets
+}
+
+// Get returns the preset with the given name.
+func (s *Store) Get(name string) (*Preset, error) {
+	for _, p := range s.presets {
+		if p.Name == name {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("preset %q not found", name)
+}
+
+// Save validates and stores p, replacing any preset with the same name.
+func (s *Store) Save(p Preset) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range s.presets {
+		if s.presets[i].Name == p.Name {
+			s.presets[i] = p
+			replaced = true
+		}
+	}
+	if !replaced {
+		s.presets = append(s.presets, p)
+	}
+	return s.save()
+}
+
+// Delete removes the preset with the given name.
+func (s *Store) Delete(name string) error {
+	for i, p := range s.presets {
+		if p.Name == name {
+			s.presets = append(s.presets[:i], s.presets[i+1:]...)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("preset %q not found", name)
+}
+
+// Decode reads presets from exported JSON, accepting either a single
+// preset object or an array of presets. Every preset is validated.
+func Decode(data []byte) ([]Preset, error) {
+	data = []byte(strings.TrimSpace(string(data)))
+
+	var list []Preset
+	if strings.HasPrefix(string(data), "[") {
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("invalid presets JSON: %w", err)
+		}
+	} else {
+		var p Preset
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("invalid presets JSON: %w", err)
+		}
+		list = []Preset{p}
+	}
+
+	for i := range list {
+		if err := list[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// Encode returns presets as indented JSON suitable for sharing.
+func Encode(list []Preset) ([]byte, error) {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal presets: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+func (s *Store) load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.presets = []Preset{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read presets: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.presets); err != nil {
+		return fmt.Errorf("%s: invalid JSON: %w", s.path, err)
+	}
+	s.sort()
+	return nil
+}
+
+func (s *Store) save() error {
+	s.sort()
+	data, err := Encode(s.presets)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(s.path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func (s *Store) sort() {
+	sort.Slice(s.presets, func(i, j int) bool {
+		return s.presets[i].Name < s.presets[j].Name
+	})
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/DalyChouikh/internal/config"
+)
+
+// cache is a fixed-size LRU cache of rendered QR codes keyed by config hash.
+type cache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // Front is most recently used
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key  string
+	data []byte
+}
+
+func newCache(max int) *cache {
+	return &cache{
+		max:     max,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *cache) get(key string) ([]byte, bool) {
+	if c.max <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).data, true
+}
+
+func (c *cache) put(key string, data []byte) {
+	if c.max <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, data: data})
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// cacheKey hashes every option that affects the rendered output.
+func cacheKey(cfg *config.QRConfig) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%d\x00%s\x00%s\x00%s\x00%s\x00%t\x00%d\x00%s\x00%s",
+		cfg.Content,
+		cfg.Format,
+		cfg.Size,
+		config.ColorToHex(cfg.Foreground),
+		config.ColorToHex(cfg.Background),
+		cfg.Level,
+		cfg.Renderer,
+		cfg.Invert,
+		cfg.DPI,
+		cfg.Embed,
+		cfg.Alt,
+	)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
@@ -0,0 +1,362 @@
+// Package server exposes QR code generation over HTTP.
+//
+// Endpoints:
+//
+//	GET  /qr?content=...&format=svg&size=512   Generate from query parameters
+//	POST /qr                                   Generate from a JSON body
+//	GET  /healthz                              Liveness check
+//
+// Both /qr variants accept the same options as the CLI (format, size, fg,
+// bg, ec, renderer, invert, dpi, embed, alt) and an optional template type
+// with its fields, e.g. type=wifi&ssid=Office&password=secret. Requests are
+// validated with the same rules as the CLI, bounded by configurable content
+// and size limits, and identical requests are served from an in-memory
+// cache. Codes that carry credentials (WiFi networks and OTP enrollments)
+// are never cached, on the server or by clients, and are sent with
+// "Cache-Control: no-store".
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DalyChouikh/internal/config"
+	"github.com/DalyChouikh/internal/generator"
+	"github.com/DalyChouikh/internal/templates"
+)
+
+// Options controls server limits and caching.
+type Options struct {
+	Defaults         config.QRConfig // Base configuration for every request
+	MaxContentLength int             // Maximum encoded content length in bytes
+	MaxSize          int             // Maximum image size in pixels
+	MaxBodyBytes     int64           // Maximum POST body size in bytes
+	CacheEntries     int             // Number of rendered codes kept in memory (0 disables caching)
+}
+
+// DefaultOptions returns conservative limits suitable for internal tools.
+func DefaultOptions() Options {
+	return Options{
+		Defaults:         *config.DefaultConfig(),
+		MaxContentLength: 2048,
+		MaxSize:          2048,
+		MaxBodyBytes:     64 << 10,
+		CacheEntries:     512,
+	}
+}
+
+// Server serves QR codes over HTTP.
+type Server struct {
+	opts  Options
+	cache *cache
+	mux   *http.ServeMux
+}
+
+// New creates a Server with the given options.
+func New(opts Options) *Server {
+	s := &Server{
+		opts:  opts,
+		cache: newCache(opts.CacheEntries),
+		mux:   http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/qr", s.handleQR)
+	s.mux.HandleFunc("/healthz", s.handleHealth)
+	return s
+}
+
+// Handler returns the HTTP handler for the server's routes.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// ListenAndServe serves on addr until ctx is cancelled, then shuts down
+// gracefully, giving in-flight requests up to 10 seconds to complete.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// qrRequest is the JSON body accepted by POST /qr.
+type qrRequest struct {
+	Content  string            `json:"content"`
+	Type     string            `json:"type"`
+	Fields   map[string]string `json:"fields"`
+	Format   string            `json:"format"`
+	Size     int               `json:"size"`
+	FG       string            `json:"fg"`
+	BG       string            `json:"bg"`
+	EC       string            `json:"ec"`
+	Renderer string            `json:"renderer"`
+	Invert   bool              `json:"invert"`
+	DPI      int               `json:"dpi"`
+	Embed    string            `json:"embed"`
+	Alt      string            `json:"alt"`
+}
+
+func (s *Server) handleQR(w http.ResponseWriter, r *http.Request) {
+	var req qrRequest
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		var err error
+		if req, err = parseQuery(r); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	case http.MethodPost:
+		body := http.MaxBytesReader(w, r.Body, s.opts.MaxBodyBytes)
+		dec := json.NewDecoder(body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", s.opts.MaxBodyBytes))
+				return
+			}
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	cfg, status, err := s.buildConfig(req)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	// Secrets skip both caches, and the ETag, which is a hash of the content.
+	secret := templates.ContainsSecrets(templates.DetectType(cfg.Content))
+	key := cacheKey(cfg)
+	w.Header().Set("Content-Type", contentType(cfg.Format))
+	if secret {
+		w.Header().Set("Cache-Control", "private, no-store")
+	} else {
+		etag := `"` + key + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "public, max-age=86400, immutable")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	var data []byte
+	var ok bool
+	if !secret {
+		data, ok = s.cache.get(key)
+	}
+	if !ok {
+		var buf bytes.Buffer
+		if err := generator.New(cfg).Render(&buf); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, generator.ErrEncoding) {
+				status = http.StatusUnprocessableEntity
+			}
+			writeError(w, status, err)
+			return
+		}
+		data = buf.Bytes()
+		if !secret {
+			s.cache.put(key, data)
+		}
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(data)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = io.WriteString(w, `{"status":"ok"}`)
+}
+
+// parseQuery converts GET query parameters into a request. Parameters that
+// are not options are passed through as template fields.
+func parseQuery(r *http.Request) (qrRequest, error) {
+	q := r.URL.Query()
+	req := qrRequest{
+		Content:  q.Get("content"),
+		Type:     q.Get("type"),
+		Format:   q.Get("format"),
+		FG:       q.Get("fg"),
+		BG:       q.Get("bg"),
+		EC:       q.Get("ec"),
+		Renderer: q.Get("renderer"),
+		Embed:    q.Get("embed"),
+		Alt:      q.Get("alt"),
+		Fields:   make(map[string]string),
+	}
+
+	if v := q.Get("size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return req, fmt.Errorf("invalid size %q", v)
+		}
+		req.Size = size
+	}
+	if v := q.Get("invert"); v != "" {
+		invert, err := strconv.ParseBool(v)
+		if err != nil {
+			return req, fmt.Errorf("invalid invert value %q", v)
+		}
+		req.Invert = invert
+	}
+	if v := q.Get("dpi"); v != "" {
+		dpi, err := strconv.Atoi(v)
+		if err != nil {
+			return req, fmt.Errorf("invalid dpi %q", v)
+		}
+		req.DPI = dpi
+	}
+
+	for key := range q {
+		switch key {
+		case "content", "type", "format", "size", "fg", "bg", "ec", "renderer", "invert", "dpi", "embed", "alt":
+			continue
+		}
+		req.Fields[key] = q.Get(key)
+	}
+
+	return req, nil
+}
+
+// buildConfig validates a request and converts it to a QRConfig. It returns
+// the HTTP status to use when the request is rejected.
+func (s *Server) buildConfig(req qrRequest) (*config.QRConfig, int, error) {
+	base := s.opts.Defaults
+	cfg := &base
+
+	content := req.Content
+	if req.Type != "" {
+		ct, err := templates.ParseContentType(req.Type)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		fields := make(map[string]string, len(req.Fields)+1)
+		for k, v := range req.Fields {
+			fields[strings.ToLower(k)] = v
+		}
+		if _, ok := fields["content"]; !ok {
+			fields["content"] = req.Content
+		}
+		if content, err = templates.FromFields(ct, fields); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	if len(content) > s.opts.MaxContentLength {
+		return nil, http.StatusRequestEntityTooLarge,
+			fmt.Errorf("content exceeds %d bytes", s.opts.MaxContentLength)
+	}
+	cfg.Content = content
+
+	if req.Format != "" {
+		cfg.Format = config.ParseFormat(req.Format)
+	}
+	if req.Size != 0 {
+		if req.Size > s.opts.MaxSize {
+			return nil, http.StatusBadRequest, fmt.Errorf("size exceeds the server limit of %d pixels", s.opts.MaxSize)
+		}
+		cfg.Size = req.Size
+	}
+	if req.FG != "" {
+		c, err := config.ParseHexColor(req.FG)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid foreground color: %w", err)
+		}
+		cfg.Foreground = c
+	}
+	if req.BG != "" {
+		c, err := config.ParseHexColor(req.BG)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid background color: %w", err)
+		}
+		cfg.Background = c
+	}
+	if req.EC != "" {
+		level, err := config.ParseErrorCorrection(req.EC)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		cfg.Level = level
+	}
+	ct := templates.DetectType(content)
+	required := templates.RequiredErrorCorrection(ct)
+	if err := cfg.RequireLevel(config.ErrorCorrection(required), req.EC != ""); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	cfg.SwissCross = templates.RequiresSwissCross(ct)
+	if req.Renderer != "" {
+		cfg.Renderer = config.TextRenderer(strings.ToLower(req.Renderer))
+	}
+	cfg.Invert = req.Invert
+	if req.DPI != 0 {
+		cfg.DPI = req.DPI
+	}
+	if req.Embed != "" {
+		cfg.Embed = config.ParseFormat(req.Embed)
+	}
+	if req.Alt != "" {
+		cfg.Alt = req.Alt
+	}
+
+	if err := cfg.ValidateOptions(); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	return cfg, http.StatusOK, nil
+}
+
+// contentType returns the MIME type for an output format.
+func contentType(f config.OutputFormat) string {
+	return f.MIMEType()
+}
+
+// writeError writes a JSON error response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Del("ETag")
+	w.Header().Del("Cache-Control")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newTestServer() *Server {
+	opts := DefaultOptions()
+	opts.MaxContentLength = 64
+	opts.MaxSize = 512
+	opts.MaxBodyBytes = 256
+	return New(opts)
+}
+
+func do(t *testing.T, s *Server, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func get(t *testing.T, s *Server, query url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	return do(t, s, httptest.NewRequest(http.MethodGet, "/qr?"+query.Encode(), nil))
+}
+
+func TestHealth(t *testing.T) {
+	rec := do(t, newTestServer(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := rec.Body.String(); got != `{"status":"ok"}` {
+		t.Errorf("body = %s", got)
+	}
+}
+
+func TestQRLimits(t *testing.T) {
+	s := newTestServer()
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{
+			name: "content too long",
+			req:  httptest.NewRequest(http.MethodGet, "/qr?content="+strings.Repeat("a", 65), nil),
+			want: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "size too large",
+			req:  httptest.NewRequest(http.MethodGet, "/qr?content=hi&size=1024", nil),
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "body too large",
+			req: httptest.NewRequest(http.MethodPost, "/qr",
+				strings.NewReader(`{"content":"`+strings.Repeat("a", 300)+`"}`)),
+			want: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, s, tt.req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body)
+			}
+			if rec.Header().Get("Cache-Control") != "" {
+				t.Error("error response has a Cache-Control header")
+			}
+		})
+	}
+}
+
+func TestQRValidationErrors(t *testing.T) {
+	s := newTestServer()
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"missing content", httptest.NewRequest(http.MethodGet, "/qr", nil), http.StatusBadRequest},
+		{"invalid size", httptest.NewRequest(http.MethodGet, "/qr?content=hi&size=big", nil), http.StatusBadRequest},
+		{"invalid color", httptest.NewRequest(http.MethodGet, "/qr?content=hi&fg=nope", nil), http.StatusBadRequest},
+		{"invalid level", httptest.NewRequest(http.MethodGet, "/qr?content=hi&ec=X", nil), http.StatusBadRequest},
+		{"unknown type", httptest.NewRequest(http.MethodGet, "/qr?type=fax", nil), http.StatusBadRequest},
+		{"invalid template", httptest.NewRequest(http.MethodGet, "/qr?type=wifi", nil), http.StatusBadRequest},
+		{"unknown JSON field", httptest.NewRequest(http.MethodPost, "/qr", strings.NewReader(`{"colour":"red"}`)), http.StatusBadRequest},
+		{"method", httptest.NewRequest(http.MethodDelete, "/qr?content=hi", nil), http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, s, tt.req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			if !strings.Contains(rec.Body.String(), `"error"`) {
+				t.Errorf("body = %s, want a JSON error", rec.Body)
+			}
+		})
+	}
+}
+
+func TestQRCacheHit(t *testing.T) {
+	s := newTestServer()
+	query := url.Values{"content": {"https://example.com"}, "format": {"svg"}}
+
+	first := get(t, s, query)
+	if first.Code != http.StatusOK {
+		t.Fatalf("status = %d (%s)", first.Code, first.Body)
+	}
+	if cc := first.Header().Get("Cache-Control"); cc != "public, max-age=86400, immutable" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+	if _, ok := s.cache.get(strings.Trim(etag, `"`)); !ok {
+		t.Error("rendered code was not cached")
+	}
+
+	second := get(t, s, query)
+	if second.Header().Get("ETag") != etag || second.Body.String() != first.Body.String() {
+		t.Error("identical request returned a different response")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/qr?"+query.Encode(), nil)
+	req.Header.Set("If-None-Match", etag)
+	if rec := do(t, s, req); rec.Code != http.StatusNotModified {
+		t.Errorf("conditional request status = %d, want 304", rec.Code)
+	}
+}
+
+func TestQRSecretsNotCached(t *testing.T) {
+	tests := []struct {
+		name  string
+		query url.Values
+	}{
+		{"wifi", url.Values{"type": {"wifi"}, "ssid": {"Office"}, "password": {"secret123"}}},
+		{"otp", url.Values{"type": {"otp"}, "issuer": {"Example"}, "account": {"me@example.com"}, "secret": {"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(DefaultOptions())
+			rec := get(t, s, tt.query)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d (%s)", rec.Code, rec.Body)
+			}
+			if cc := rec.Header().Get("Cache-Control"); cc != "private, no-store" {
+				t.Errorf("Cache-Control = %q, want private, no-store", cc)
+			}
+			if etag := rec.Header().Get("ETag"); etag != "" {
+				t.Errorf("ETag = %q, want none", etag)
+			}
+			if n := s.cache.order.Len(); n != 0 {
+				t.Errorf("server cached %d secret codes", n)
+			}
+		})
+	}
+}
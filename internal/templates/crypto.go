@@ -0,0 +1,429 @@
+package templates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// CryptoNetwork selects the payment URI scheme of a CryptoData.
+type CryptoNetwork string
+
+const (
+	CryptoBitcoin   CryptoNetwork = "bitcoin"   // BIP21 bitcoin: URI
+	CryptoEthereum  CryptoNetwork = "ethereum"  // EIP-681 ethereum: URI
+	CryptoLightning CryptoNetwork = "lightning" // BOLT11 invoice
+)
+
+// CryptoNetworks returns the available networks in display order.
+func CryptoNetworks() []struct {
+	Network CryptoNetwork
+	Name    string
+} {
+	return []struct {
+		Network CryptoNetwork
+		Name    string
+	}{
+		{CryptoBitcoin, "Bitcoin"},
+		{CryptoEthereum, "Ethereum"},
+		{CryptoLightning, "Lightning"},
+	}
+}
+
+// Decimal places of the smallest unit: satoshis and wei.
+const (
+	bitcoinDecimals  = 8
+	ethereumDecimals = 18
+)
+
+// CryptoData holds a cryptocurrency payment request.
+type CryptoData struct {
+	Network CryptoNetwork
+	Address string // Bitcoin or Ethereum address, or the Lightning invoice
+	Amount  string // Decimal BTC or ETH, e.g. "0.0015"; empty lets the payer choose
+	Label   string // Bitcoin only: payee name shown by the wallet
+	Message string // Bitcoin only: description of the payment
+	ChainID int64  // Ethereum only: EIP-155 chain ID; 0 means mainnet
+}
+
+// Validate checks the address checksum and the fields allowed for the
+// network.
+func (c *CryptoData) Validate() error {
+	switch c.Network {
+	case CryptoBitcoin:
+		if err := ValidateBitcoinAddress(c.Address); err != nil {
+			return err
+		}
+		if c.Amount != "" {
+			if _, err := parseCoinAmount(c.Amount, bitcoinDecimals); err != nil {
+				return err
+			}
+		}
+		if c.ChainID != 0 {
+			return fmt.Errorf("chain ID only applies to Ethereum")
+		}
+	case CryptoEthereum:
+		if _, err := EthereumChecksumAddress(c.Address); err != nil {
+			return err
+		}
+		if c.Amount != "" {
+			if _, err := parseCoinAmount(c.Amount, ethereumDecimals); err != nil {
+				return err
+			}
+		}
+		if c.ChainID < 0 {
+			return fmt.Errorf("chain ID must be positive")
+		}
+		if c.Label != "" || c.Message != "" {
+			return fmt.Errorf("Ethereum payment requests have no label or message")
+		}
+	case CryptoLightning:
+		if err := validateLightningInvoice(c.Address); err != nil {
+			return err
+		}
+		if c.Amount != "" || c.Label != "" || c.Message != "" || c.ChainID != 0 {
+			return fmt.Errorf("Lightning invoices carry their own amount and description")
+		}
+	default:
+		return fmt.Errorf("invalid network %q (expected bitcoin, ethereum or lightning)", c.Network)
+	}
+	return nil
+}
+
+// Encode generates the payment URI. Call Validate first: an invalid amount
+// or Ethereum address is left out.
+func (c *CryptoData) Encode() string {
+	address := strings.TrimSpace(c.Address)
+
+	switch c.Network {
+	case CryptoEthereum:
+		// Format: ethereum:<checksummed address>[@<chain id>][?value=<wei>]
+		if sum, err := EthereumChecksumAddress(address); err == nil {
+			address = sum
+		}
+		s := "ethereum:" + address
+		if c.ChainID > 0 {
+			s += "@" + strconv.FormatInt(c.ChainID, 10)
+		}
+		if wei, err := parseCoinAmount(c.Amount, ethereumDecimals); err == nil && c.Amount != "" {
+			s += "?value=" + wei.String()
+		}
+		return s
+
+	case CryptoLightning:
+		// Upper case keeps the whole URI in the QR alphanumeric set, which
+		// takes 5.5 bits per character instead of 8.
+		return "LIGHTNING:" + strings.ToUpper(trimScheme(address, "lightning:"))
+	}
+
+	// Format: bitcoin:<address>[?amount=<btc>][&label=<label>][&message=<message>]
+	var params []string
+	if sats, err := parseCoinAmount(c.Amount, bitcoinDecimals); err == nil && c.Amount != "" {
+		params = append(params, "amount="+formatCoinAmount(sats, bitcoinDecimals))
+	}
+	if c.Label != "" {
+		params = append(params, "label="+paramEscape(c.Label))
+	}
+	if c.Message != "" {
+		params = append(params, "message="+paramEscape(c.Message))
+	}
+	s := "bitcoin:" + trimScheme(address, "bitcoin:")
+	if len(params) > 0 {
+		s += "?" + strings.Join(params, "&")
+	}
+	return s
+}
+
+// trimScheme removes a URI scheme the user pasted along with an address.
+func trimScheme(s, scheme string) string {
+	if len(s) >= len(scheme) && strings.EqualFold(s[:len(scheme)], scheme) {
+		return s[len(scheme):]
+	}
+	return s
+}
+
+// parseCoinAmount parses a positive decimal amount such as "0.0015" into
+// the smallest unit (satoshis or wei) with the given number of decimals.
+func parseCoinAmount(s string, decimals int) (*big.Int, error) {
+	v := strings.TrimSpace(s)
+	whole, frac, hasFrac := strings.Cut(v, ".")
+	if whole == "" || (hasFrac && (frac == "" || len(frac) > decimals)) ||
+		strings.Trim(whole+frac, "0123456789") != "" {
+		return nil, fmt.Errorf("invalid amount %q (expected a decimal with up to %d places)", s, decimals)
+	}
+	n, _ := new(big.Int).SetString(whole+frac+strings.Repeat("0", decimals-len(frac)), 10)
+	if n.Sign() == 0 {
+		return nil, fmt.Errorf("amount must be positive (leave it empty to let the payer choose)")
+	}
+	return n, nil
+}
+
+// formatCoinAmount formats smallest units as a decimal without trailing
+// zeros, e.g. 150000 satoshis as "0.0015".
+func formatCoinAmount(n *big.Int, decimals int) string {
+	s := fmt.Sprintf("%0*s", decimals+1, n.String())
+	whole, frac := s[:len(s)-decimals], strings.TrimRight(s[len(s)-decimals:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
+
+// ValidateBitcoinAddress checks a mainnet, testnet or regtest Bitcoin
+// address: base58check (P2PKH, P2SH) with its double SHA-256 checksum, or
+// segwit bech32 (version 0) and bech32m (version 1 and later).
+func ValidateBitcoinAddress(address string) error {
+	a := trimScheme(strings.TrimSpace(address), "bitcoin:")
+	if a == "" {
+		return fmt.Errorf("Bitcoin address is required")
+	}
+	lower := strings.ToLower(a)
+	if strings.HasPrefix(lower, "bc1") || strings.HasPrefix(lower, "tb1") || strings.HasPrefix(lower, "bcrt1") {
+		return validateSegwitAddress(a)
+	}
+
+	payload, err := base58Decode(a)
+	if err != nil || len(payload) != 25 {
+		return fmt.Errorf("invalid Bitcoin address %q", address)
+	}
+	switch payload[0] {
+	case 0x00, 0x05, 0x6F, 0xC4: // P2PKH and P2SH, mainnet and testnet
+	default:
+		return fmt.Errorf("invalid Bitcoin address %q: unknown version byte %#02x", address, payload[0])
+	}
+	first := sha256.Sum256(payload[:21])
+	second := sha256.Sum256(first[:])
+	if string(second[:4]) != string(payload[21:]) {
+		return fmt.Errorf("invalid Bitcoin address %q: checksum does not match", address)
+	}
+	return nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Decode decodes a Bitcoin base58 string; each leading "1" is a zero
+// byte.
+func base58Decode(s string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, r := range s {
+		i := strings.IndexRune(base58Alphabet, r)
+		if i < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", r)
+		}
+		n.Mul(n, radix).Add(n, big.NewInt(int64(i)))
+	}
+	zeros := len(s) - len(strings.TrimLeft(s, "1"))
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// validateSegwitAddress checks a BIP173/BIP350 segwit address.
+func validateSegwitAddress(address string) error {
+	if len(address) > 90 {
+		return fmt.Errorf("invalid Bitcoin address %q: longer than 90 characters", address)
+	}
+	hrp, data, variant, err := bech32Decode(address)
+	if err != nil {
+		return fmt.Errorf("invalid Bitcoin address %q: %w", address, err)
+	}
+	if hrp != "bc" && hrp != "tb" && hrp != "bcrt" {
+		return fmt.Errorf("invalid Bitcoin address %q: unknown prefix %s", address, hrp)
+	}
+	if len(data) == 0 || data[0] > 16 {
+		return fmt.Errorf("invalid Bitcoin address %q: bad witness version", address)
+	}
+	version := data[0]
+	if version == 0 && variant != bech32Const {
+		return fmt.Errorf("invalid Bitcoin address %q: witness version 0 must use bech32", address)
+	}
+	if version > 0 && variant != bech32mConst {
+		return fmt.Errorf("invalid Bitcoin address %q: witness version %d must use bech32m", address, version)
+	}
+	program, ok := convertBits(data[1:], 5, 8)
+	if !ok || len(program) < 2 || len(program) > 40 || (version == 0 && len(program) != 20 && len(program) != 32) {
+		return fmt.Errorf("invalid Bitcoin address %q: bad witness program", address)
+	}
+	return nil
+}
+
+// validateLightningInvoice checks the bech32 checksum and "ln" prefix of a
+// BOLT11 invoice. The invoice itself is passed through untouched.
+func validateLightningInvoice(invoice string) error {
+	v := trimScheme(strings.TrimSpace(invoice), "lightning:")
+	if v == "" {
+		return fmt.Errorf("Lightning invoice is required")
+	}
+	hrp, _, variant, err := bech32Decode(v)
+	if err != nil {
+		return fmt.Errorf("invalid Lightning invoice: %w", err)
+	}
+	if !strings.HasPrefix(hrp, "ln") || variant != bech32Const {
+		return fmt.Errorf("invalid Lightning invoice: expected a BOLT11 invoice starting with lnbc or lntb")
+	}
+	return nil
+}
+
+// Bech32 checksum constants (BIP173 and BIP350).
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2BC830A3
+	bech32Chars  = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// bech32Decode splits a bech32 or bech32m string into its human-readable
+// part and 5-bit data (without the checksum) and reports which checksum
+// constant matched.
+func bech32Decode(s string) (hrp string, data []byte, variant uint32, err error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, 0, fmt.Errorf("mixed case")
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || len(s)-sep-1 < 6 {
+		return "", nil, 0, fmt.Errorf("missing separator or checksum")
+	}
+	hrp = s[:sep]
+	for _, r := range hrp {
+		if r < 33 || r > 126 {
+			return "", nil, 0, fmt.Errorf("invalid character in prefix")
+		}
+	}
+	for _, r := range s[sep+1:] {
+		i := strings.IndexRune(bech32Chars, r)
+		if i < 0 {
+			return "", nil, 0, fmt.Errorf("invalid character %q", r)
+		}
+		data = append(data, byte(i))
+	}
+
+	values := make([]byte, 0, 2*len(hrp)+1+len(data))
+	for _, r := range hrp {
+		values = append(values, byte(r)>>5)
+	}
+	values = append(values, 0)
+	for _, r := range hrp {
+		values = append(values, byte(r)&31)
+	}
+	values = append(values, data...)
+	switch variant = bech32Polymod(values); variant {
+	case bech32Const, bech32mConst:
+		return hrp, data[:len(data)-6], variant, nil
+	}
+	return "", nil, 0, fmt.Errorf("checksum does not match")
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3B6A57B2, 0x26508E6D, 0x1EA119FA, 0x3D4233DD, 0x2A1462B3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1FFFFFF)<<5 ^ uint32(v)
+		for i, g := range generator {
+			if (top>>i)&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+// convertBits regroups bits without padding, as segwit programs require.
+func convertBits(data []byte, from, to uint) ([]byte, bool) {
+	var acc uint32
+	var n uint
+	var out []byte
+	for _, v := range data {
+		acc = acc<<from | uint32(v)
+		n += from
+		for n >= to {
+			n -= to
+			out = append(out, byte(acc>>n&(1<<to-1)))
+		}
+	}
+	if n >= from || acc&(1<<n-1) != 0 {
+		return nil, false
+	}
+	return out, true
+}
+
+// EthereumChecksumAddress validates an Ethereum address and returns it with
+// its EIP-55 mixed-case checksum. An all-lower or all-upper case address
+// carries no checksum and is accepted; a mixed-case one must match.
+func EthereumChecksumAddress(address string) (string, error) {
+	a := trimScheme(strings.TrimSpace(address), "ethereum:")
+	if a == "" {
+		return "", fmt.Errorf("Ethereum address is required")
+	}
+	digits := strings.TrimPrefix(strings.TrimPrefix(a, "0x"), "0X")
+	if len(digits) != 40 {
+		return "", fmt.Errorf("invalid Ethereum address %q (expected 0x and 40 hex digits)", address)
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return "", fmt.Errorf("invalid Ethereum address %q (expected 0x and 40 hex digits)", address)
+	}
+
+	lower := strings.ToLower(digits)
+	hash := keccak256([]byte(lower))
+	sum := []byte(lower)
+	for i, c := range sum {
+		// Upper-case a letter when its nibble of the hash is 8 or more.
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0F
+		}
+		if c >= 'a' && nibble >= 8 {
+			sum[i] = c - 'a' + 'A'
+		}
+	}
+
+	if digits != lower && digits != strings.ToUpper(digits) && digits != string(sum) {
+		return "", fmt.Errorf("invalid Ethereum address %q: EIP-55 checksum does not match", address)
+	}
+	return "0x" + string(sum), nil
+}
+
+// parseCryptoNetwork accepts the network names and a few symbols. There is
+// no default.
+func parseCryptoNetwork(s string) (CryptoNetwork, error) {
+	switch strings.ToLower(s) {
+	case "bitcoin", "btc":
+		return CryptoBitcoin, nil
+	case "ethereum", "eth":
+		return CryptoEthereum, nil
+	case "lightning", "ln":
+		return CryptoLightning, nil
+	}
+	return "", fmt.Errorf("invalid network %q (expected bitcoin, ethereum or lightning)", s)
+}
+
+// cryptoFromFields builds a payment request from form or manifest fields.
+// "network" is bitcoin, ethereum or lightning, "address" the invoice for
+// Lightning, and "amount" is in BTC or ETH.
+func cryptoFromFields(get func(string) string) (*CryptoData, error) {
+	network, err := parseCryptoNetwork(get("network"))
+	if err != nil {
+		return nil, err
+	}
+	c := &CryptoData{
+		Network: network,
+		Address: get("address"),
+		Amount:  get("amount"),
+		Label:   get("label"),
+		Message: get("message"),
+	}
+	if network == CryptoLightning && c.Address == "" {
+		c.Address = get("invoice")
+	}
+	if c.Address == "" {
+		return nil, fmt.Errorf("field 'address' is required")
+	}
+	if id := get("chain_id"); id != "" {
+		if c.ChainID, err = strconv.ParseInt(id, 10, 64); err != nil || c.ChainID <= 0 {
+			return nil, fmt.Errorf("field 'chain_id' must be a positive number")
+		}
+	}
+	return c, c.Validate()
+}
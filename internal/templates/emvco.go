@@ -0,0 +1,192 @@
+package templates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// EMVCo merchant-presented mode (MPM) data object IDs used by the builder.
+const (
+	EMVPayloadFormat    = "00" // Always "01"
+	EMVInitiationMethod = "01" // "11" static (reusable), "12" dynamic (one payment)
+	EMVMerchantCategory = "52" // ISO 18245 merchant category code
+	EMVCurrency         = "53" // ISO 4217 numeric currency code
+	EMVAmount           = "54"
+	EMVCountry          = "58" // ISO 3166-1 alpha-2 country code
+	EMVMerchantName     = "59"
+	EMVMerchantCity     = "60"
+	EMVPostalCode       = "61"
+	EMVAdditionalData   = "62"
+	EMVCRC              = "63"
+)
+
+// EMVField is one TLV data object of an EMVCo QR code payload. A field with
+// Fields is a template whose value is the encoding of its nested objects.
+type EMVField struct {
+	ID     string // Two digits, "00" to "99"
+	Value  string
+	Fields EMVFields
+}
+
+// EMVFields is a list of data objects in payload order.
+type EMVFields []EMVField
+
+// Get returns the first data object with the given ID.
+func (fs EMVFields) Get(id string) (EMVField, bool) {
+	for _, f := range fs {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return EMVField{}, false
+}
+
+// Value returns the value of the data object at path, descending into
+// templates: Value("26", "01") reads ID 01 inside template 26.
+func (fs EMVFields) Value(path ...string) string {
+	for i, id := range path {
+		f, ok := fs.Get(id)
+		if !ok {
+			return ""
+		}
+		if i == len(path)-1 {
+			return f.Value
+		}
+		fs = f.Fields
+	}
+	return ""
+}
+
+// encode writes the fields as ID, two-digit length and value.
+func (fs EMVFields) encode() (string, error) {
+	var b strings.Builder
+	for _, f := range fs {
+		if len(f.ID) != 2 || strings.Trim(f.ID, "0123456789") != "" {
+			return "", fmt.Errorf("invalid EMV data object ID %q (expected 2 digits)", f.ID)
+		}
+		value := f.Value
+		if len(f.Fields) > 0 {
+			var err error
+			if value, err = f.Fields.encode(); err != nil {
+				return "", fmt.Errorf("template %s: %w", f.ID, err)
+			}
+		}
+		// Lengths count characters; payloads are mostly ASCII, where that is
+		// the same as bytes.
+		n := utf8.RuneCountInString(value)
+		if n == 0 {
+			continue
+		}
+		if n > 99 {
+			return "", fmt.Errorf("EMV data object %s is %d characters, more than 99", f.ID, n)
+		}
+		fmt.Fprintf(&b, "%s%02d%s", f.ID, n, value)
+	}
+	return b.String(), nil
+}
+
+// BuildEMV encodes data objects as an EMVCo MPM payload. The payload
+// format indicator (ID 00) is added first when missing, and the CRC
+// (ID 63) is computed and appended last. Empty values are skipped.
+func BuildEMV(fields EMVFields) (string, error) {
+	if _, ok := fields.Get(EMVCRC); ok {
+		return "", fmt.Errorf("the CRC (ID 63) is computed and must not be set")
+	}
+	if len(fields) == 0 || fields[0].ID != EMVPayloadFormat {
+		fields = append(EMVFields{{ID: EMVPayloadFormat, Value: "01"}}, fields...)
+	}
+	body, err := fields.encode()
+	if err != nil {
+		return "", err
+	}
+	body += EMVCRC + "04"
+	return body + fmt.Sprintf("%04X", CRC16CCITT([]byte(body))), nil
+}
+
+// CRC16CCITT computes the CRC-16/CCITT-FALSE checksum (polynomial 0x1021,
+// initial value 0xFFFF) that EMVCo payloads end with.
+func CRC16CCITT(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// ParseEMV reads an EMVCo MPM payload into its data objects, checking the
+// payload format indicator and the CRC. Merchant account information
+// (IDs 26-51), additional data (62), language (64) and unreserved (80-99)
+// templates are parsed into nested Fields.
+func ParseEMV(s string) (EMVFields, error) {
+	fields, err := parseTLV(s, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 || fields[0].ID != EMVPayloadFormat || fields[0].Value != "01" {
+		return nil, fmt.Errorf("not an EMVCo payload: it must start with 000201")
+	}
+	last := fields[len(fields)-1]
+	if last.ID != EMVCRC || len(last.Value) != 4 {
+		return nil, fmt.Errorf("EMVCo payload has no CRC (ID 63) at the end")
+	}
+	want := fmt.Sprintf("%04X", CRC16CCITT([]byte(s[:len(s)-4])))
+	if !strings.EqualFold(last.Value, want) {
+		return nil, fmt.Errorf("EMVCo CRC mismatch: payload says %s, computed %s", last.Value, want)
+	}
+	return fields, nil
+}
+
+// parseTLV splits s into data objects, descending into known templates
+// when top is true.
+func parseTLV(s string, top bool) (EMVFields, error) {
+	var fields EMVFields
+	rest := s
+	for rest != "" {
+		if len(rest) < 4 {
+			return nil, fmt.Errorf("truncated EMV data object %q", rest)
+		}
+		id, length := rest[:2], rest[2:4]
+		n, err := strconv.Atoi(length)
+		if err != nil || strings.Trim(id, "0123456789") != "" {
+			return nil, fmt.Errorf("invalid EMV data object header %q", rest[:4])
+		}
+		// Lengths count characters, so walk runes rather than bytes.
+		value, i := rest[4:], 0
+		for count := 0; count < n; count++ {
+			if i >= len(value) {
+				return nil, fmt.Errorf("EMV data object %s is shorter than its length %d", id, n)
+			}
+			_, size := utf8.DecodeRuneInString(value[i:])
+			i += size
+		}
+		f := EMVField{ID: id, Value: value[:i]}
+		if top && isEMVTemplate(id) {
+			if f.Fields, err = parseTLV(f.Value, false); err != nil {
+				return nil, fmt.Errorf("template %s: %w", id, err)
+			}
+		}
+		fields = append(fields, f)
+		rest = value[i:]
+	}
+	return fields, nil
+}
+
+// isEMVTemplate reports whether a top-level ID holds nested data objects.
+func isEMVTemplate(id string) bool {
+	n, _ := strconv.Atoi(id)
+	return (n >= 26 && n <= 51) || n == 62 || n == 64 || n >= 80
+}
+
+// formatEMVAmount formats cents as an EMV amount such as "10.50".
+func formatEMVAmount(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
@@ -0,0 +1,388 @@
+package templates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventData holds a calendar event.
+//
+// The time zone of Start and End decides how times are written: UTC times
+// get a "Z" suffix, times in a named IANA zone (from time.LoadLocation) get
+// a TZID and a matching VTIMEZONE, and times in time.Local are written as
+// floating times that every calendar shows at the same wall-clock time.
+type EventData struct {
+	Summary     string
+	Location    string
+	Start       time.Time
+	End         time.Time // Zero means one hour after Start (or the same day when AllDay)
+	AllDay      bool      // Only the dates of Start and End are used; End is the last day, inclusive
+	Description string
+	URL         string
+}
+
+// Encode generates an iCalendar (RFC 5545) VCALENDAR containing a single
+// VEVENT. The UID and DTSTAMP are derived from the event itself, so the
+// same event always encodes to the same QR code.
+func (e *EventData) Encode() string {
+	start, end := e.Start, e.End
+	if end.IsZero() {
+		if e.AllDay {
+			end = start
+		} else {
+			end = start.Add(time.Hour)
+		}
+	}
+	// A single VTIMEZONE offset cannot describe both ends of an event that
+	// spans a daylight saving change, so such events are written in UTC.
+	if !e.AllDay && isNamedZone(start.Location()) {
+		_, startOffset := start.Zone()
+		_, endOffset := end.In(start.Location()).Zone()
+		if startOffset != endOffset {
+			start, end = start.UTC(), end.UTC()
+		}
+	}
+
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(foldLine(s))
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//qrgen//Event//EN")
+	if !e.AllDay && isNamedZone(start.Location()) {
+		name, offset := start.Zone()
+		line("BEGIN:VTIMEZONE")
+		line("TZID:" + start.Location().String())
+		line("BEGIN:STANDARD")
+		line("DTSTART:19700101T000000")
+		line("TZOFFSETFROM:" + formatUTCOffset(offset))
+		line("TZOFFSETTO:" + formatUTCOffset(offset))
+		line("TZNAME:" + name)
+		line("END:STANDARD")
+		line("END:VTIMEZONE")
+	}
+
+	line("BEGIN:VEVENT")
+	line("UID:" + e.uid() + "@qrgen")
+	line("DTSTAMP:" + start.UTC().Format(icalUTC))
+	if e.AllDay {
+		line("DTSTART;VALUE=DATE:" + start.Format(icalDate))
+		line("DTEND;VALUE=DATE:" + end.AddDate(0, 0, 1).Format(icalDate))
+	} else {
+		line("DTSTART" + icalTime(start))
+		line("DTEND" + icalTime(end.In(start.Location())))
+	}
+	line("SUMMARY:" + escapeICalText(e.Summary))
+	if e.Location != "" {
+		line("LOCATION:" + escapeICalText(e.Location))
+	}
+	if e.Description != "" {
+		line("DESCRIPTION:" + escapeICalText(e.Description))
+	}
+	if e.URL != "" {
+		line("URL:" + e.URL)
+	}
+	line("END:VEVENT")
+	line("END:VCALENDAR")
+
+	return b.String()
+}
+
+// uid derives a stable unique identifier from the event's content.
+func (e *EventData) uid() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		e.Summary, e.Location, e.Start.Format(time.RFC3339), e.End.Format(time.RFC3339),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// iCalendar date and date-time layouts.
+const (
+	icalDate     = "20060102"
+	icalDateTime = "20060102T150405"
+	icalUTC      = "20060102T150405Z"
+)
+
+// icalTime formats the parameters and value of a DTSTART or DTEND
+// property, starting with ";" or ":".
+func icalTime(t time.Time) string {
+	switch {
+	case t.Location() == time.UTC:
+		return ":" + t.Format(icalUTC)
+	case t.Location() == time.Local:
+		return ":" + t.Format(icalDateTime)
+	case isNamedZone(t.Location()):
+		return ";TZID=" + t.Location().String() + ":" + t.Format(icalDateTime)
+	}
+	// Fixed offsets such as "+02:00" have no TZID to refer to.
+	return ":" + t.UTC().Format(icalUTC)
+}
+
+// isNamedZone reports whether loc is an IANA time zone that calendars can
+// refer to by TZID.
+func isNamedZone(loc *time.Location) bool {
+	if loc == time.UTC || loc == time.Local {
+		return false
+	}
+	name := loc.String()
+	if name == "" || name == "UTC" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// formatUTCOffset formats a zone offset in seconds as +HHMM.
+func formatUTCOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// icalEscaper escapes TEXT values (RFC 5545, section 3.3.11).
+var icalEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICalText(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+// icalUnescaper reverses escapeICalText.
+var icalUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\,`, `,`, `\n`, "\n", `\N`, "\n")
+
+// foldLine terminates a content line with CRLF, folding it so that no line
+// exceeds 75 octets (RFC 5545, section 3.1). Continuation lines start with
+// a space, and multi-byte UTF-8 characters are never split.
+func foldLine(s string) string {
+	const limit = 75
+
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		n := len(string(r))
+		if width+n > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// unfoldLines splits content into logical lines, joining folded
+// continuation lines.
+func unfoldLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	var lines []string
+	for _, l := range strings.Split(s, "\n") {
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += l[1:]
+			continue
+		}
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// ParseEvent reads the first VEVENT of an iCalendar document, reversing
+// EventData.Encode. Floating times are returned in time.Local.
+func ParseEvent(s string) (EventData, error) {
+	var e EventData
+	var inEvent, found bool
+	var nested int
+	var endExclusive time.Time
+
+	for _, l := range unfoldLines(s) {
+		name, value, ok := strings.Cut(l, ":")
+		if !ok {
+			continue
+		}
+		prop, paramList, _ := strings.Cut(name, ";")
+		params := make(map[string]string)
+		for _, p := range strings.Split(paramList, ";") {
+			if k, v, ok := strings.Cut(p, "="); ok {
+				params[strings.ToUpper(k)] = v
+			}
+		}
+
+		// Components nested in the event, such as VALARM, have properties
+		// of their own that must not override the event's.
+		switch strings.ToUpper(prop) {
+		case "BEGIN":
+			if inEvent {
+				nested++
+			} else if strings.EqualFold(value, "VEVENT") {
+				inEvent, found = true, true
+			}
+			continue
+		case "END":
+			if nested > 0 {
+				nested--
+				continue
+			}
+			if inEvent && strings.EqualFold(value, "VEVENT") {
+				if endExclusive.IsZero() {
+					return e, nil
+				}
+				if e.AllDay {
+					e.End = endExclusive.AddDate(0, 0, -1)
+				} else {
+					e.End = endExclusive
+				}
+				return e, nil
+			}
+		}
+		if !inEvent || nested > 0 {
+			continue
+		}
+
+		switch strings.ToUpper(prop) {
+		case "SUMMARY":
+			e.Summary = icalUnescaper.Replace(value)
+		case "LOCATION":
+			e.Location = icalUnescaper.Replace(value)
+		case "DESCRIPTION":
+			e.Description = icalUnescaper.Replace(value)
+		case "URL":
+			e.URL = value
+		case "DTSTART", "DTEND":
+			t, allDay, err := parseICalTime(value, params)
+			if err != nil {
+				return e, fmt.Errorf("invalid %s: %w", strings.ToUpper(prop), err)
+			}
+			if strings.EqualFold(prop, "DTSTART") {
+				e.Start, e.AllDay = t, allDay
+			} else {
+				endExclusive = t
+			}
+		}
+	}
+
+	if !found {
+		return e, fmt.Errorf("no VEVENT found")
+	}
+	return e, fmt.Errorf("VEVENT is not terminated")
+}
+
+// parseICalTime parses a DTSTART or DTEND value with its parameters.
+func parseICalTime(value string, params map[string]string) (t time.Time, allDay bool, err error) {
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len(icalDate) {
+		t, err = time.ParseInLocation(icalDate, value, time.Local)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(icalUTC, value)
+		return t, false, err
+	}
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if loc, err = time.LoadLocation(strings.Trim(tzid, `"`)); err != nil {
+			return t, false, err
+		}
+	}
+	t, err = time.ParseInLocation(icalDateTime, value, loc)
+	return t, false, err
+}
+
+// eventTimeLayouts are the layouts accepted for event start and end
+// times in forms and manifests.
+var eventTimeLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// ParseEventTime parses a date ("2026-05-14") or date and time
+// ("2026-05-14 09:30") in loc, or an RFC 3339 timestamp with its own
+// offset. dateOnly reports whether no time of day was given. A nil loc
+// means floating time (time.Local).
+func ParseEventTime(s string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	s = strings.TrimSpace(s)
+	if loc == nil {
+		loc = time.Local
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return t, true, nil
+	}
+	for _, layout := range eventTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, false, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date/time %q (expected YYYY-MM-DD or YYYY-MM-DD HH:MM)", s)
+}
+
+// ParseTimeZone loads an IANA time zone such as "Europe/Paris". An empty
+// name means floating time (time.Local), and "UTC" or "Z" means UTC.
+func ParseTimeZone(name string) (*time.Location, error) {
+	switch strings.TrimSpace(name) {
+	case "":
+		return time.Local, nil
+	case "UTC", "Z", "utc":
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(strings.TrimSpace(name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q (use an IANA name such as Europe/Paris)", name)
+	}
+	return loc, nil
+}
+
+// eventFromFields builds an event from form or manifest fields and
+// validates it: start is required, end defaults to an hour later (or the
+// same day for all-day events) and may not be before start. A start date
+// without a time of day makes the event all-day. "start" and "end" are
+// YYYY-MM-DD or YYYY-MM-DD HH:MM in the IANA "timezone" (empty means
+// floating time).
+func eventFromFields(get func(string) string) (*EventData, error) {
+	if get("summary") == "" {
+		return nil, fmt.Errorf("field 'summary' is required")
+	}
+	if get("start") == "" {
+		return nil, fmt.Errorf("field 'start' is required")
+	}
+	allDay, err := parseBoolField("all_day", get("all_day"))
+	if err != nil {
+		return nil, err
+	}
+	loc, err := ParseTimeZone(get("timezone"))
+	if err != nil {
+		return nil, err
+	}
+
+	e := &EventData{
+		Summary:     get("summary"),
+		Location:    get("location"),
+		Description: get("description"),
+		URL:         get("url"),
+	}
+	var dateOnly bool
+	if e.Start, dateOnly, err = ParseEventTime(get("start"), loc); err != nil {
+		return nil, fmt.Errorf("start: %w", err)
+	}
+	e.AllDay = allDay || dateOnly
+	if end := get("end"); end != "" {
+		if e.End, _, err = ParseEventTime(end, loc); err != nil {
+			return nil, fmt.Errorf("end: %w", err)
+		}
+		if (e.AllDay && e.End.Before(e.Start)) || (!e.AllDay && !e.End.After(e.Start)) {
+			return nil, fmt.Errorf("end must be after start")
+		}
+	}
+	return e, nil
+}
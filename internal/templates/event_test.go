@@ -0,0 +1,155 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // Europe/Paris without relying on the system database
+)
+
+func TestParseEventRoundTrip(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		event EventData
+	}{
+		{
+			name: "floating",
+			event: EventData{
+				Summary: "Standup",
+				Start:   time.Date(2026, 5, 14, 9, 30, 0, 0, time.Local),
+				End:     time.Date(2026, 5, 14, 9, 45, 0, 0, time.Local),
+			},
+		},
+		{
+			name: "utc",
+			event: EventData{
+				Summary:  "Release",
+				Location: "Online",
+				Start:    time.Date(2026, 6, 1, 16, 0, 0, 0, time.UTC),
+				End:      time.Date(2026, 6, 1, 17, 30, 0, 0, time.UTC),
+				URL:      "https://example.com/release",
+			},
+		},
+		{
+			name: "time zone",
+			event: EventData{
+				Summary:  "Meetup",
+				Location: "Station F",
+				Start:    time.Date(2026, 7, 2, 19, 0, 0, 0, paris),
+				End:      time.Date(2026, 7, 2, 22, 0, 0, 0, paris),
+			},
+		},
+		{
+			name: "other time zone",
+			event: EventData{
+				Summary: "Board meeting",
+				Start:   time.Date(2026, 1, 20, 8, 0, 0, 0, newYork),
+				End:     time.Date(2026, 1, 20, 10, 0, 0, 0, newYork),
+			},
+		},
+		{
+			name: "all day",
+			event: EventData{
+				Summary: "Conference",
+				Start:   time.Date(2026, 9, 10, 0, 0, 0, 0, time.Local),
+				End:     time.Date(2026, 9, 12, 0, 0, 0, 0, time.Local),
+				AllDay:  true,
+			},
+		},
+		{
+			name: "single all-day",
+			event: EventData{
+				Summary: "Holiday",
+				Start:   time.Date(2026, 12, 25, 0, 0, 0, 0, time.Local),
+				End:     time.Date(2026, 12, 25, 0, 0, 0, 0, time.Local),
+				AllDay:  true,
+			},
+		},
+		{
+			name: "folded line",
+			event: EventData{
+				Summary:     "Workshop",
+				Start:       time.Date(2026, 3, 3, 14, 0, 0, 0, time.UTC),
+				End:         time.Date(2026, 3, 3, 17, 0, 0, 0, time.UTC),
+				Description: strings.Repeat("Bring your laptop, charger and café notes. ", 4),
+			},
+		},
+		{
+			name: "escaped text",
+			event: EventData{
+				Summary:     `Lunch, drinks; and a \ backslash`,
+				Location:    "Room 4; floor 2, east wing",
+				Start:       time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC),
+				End:         time.Date(2026, 4, 1, 13, 0, 0, 0, time.UTC),
+				Description: "Line one\nLine two\n\nLine four",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := tt.event.Encode()
+			for _, l := range strings.Split(strings.TrimSuffix(encoded, "\r\n"), "\r\n") {
+				if len(l) > 75 {
+					t.Errorf("line longer than 75 octets: %q", l)
+				}
+			}
+
+			got, err := ParseEvent(encoded)
+			if err != nil {
+				t.Fatalf("ParseEvent: %v", err)
+			}
+			assertEventEqual(t, got, tt.event)
+		})
+	}
+}
+
+func TestEncodeEventFolds(t *testing.T) {
+	e := EventData{
+		Summary:     "Workshop",
+		Start:       time.Date(2026, 3, 3, 14, 0, 0, 0, time.UTC),
+		Description: strings.Repeat("x", 200),
+	}
+	if !strings.Contains(e.Encode(), "\r\n ") {
+		t.Error("long DESCRIPTION was not folded")
+	}
+}
+
+func assertEventEqual(t *testing.T, got, want EventData) {
+	t.Helper()
+	if got.Summary != want.Summary {
+		t.Errorf("Summary = %q, want %q", got.Summary, want.Summary)
+	}
+	if got.Location != want.Location {
+		t.Errorf("Location = %q, want %q", got.Location, want.Location)
+	}
+	if got.Description != want.Description {
+		t.Errorf("Description = %q, want %q", got.Description, want.Description)
+	}
+	if got.URL != want.URL {
+		t.Errorf("URL = %q, want %q", got.URL, want.URL)
+	}
+	if got.AllDay != want.AllDay {
+		t.Errorf("AllDay = %v, want %v", got.AllDay, want.AllDay)
+	}
+	for _, tm := range []struct {
+		name      string
+		got, want time.Time
+	}{{"Start", got.Start, want.Start}, {"End", got.End, want.End}} {
+		if !tm.got.Equal(tm.want) {
+			t.Errorf("%s = %v, want %v", tm.name, tm.got, tm.want)
+		}
+		if tm.got.Location().String() != tm.want.Location().String() {
+			t.Errorf("%s location = %s, want %s", tm.name, tm.got.Location(), tm.want.Location())
+		}
+	}
+}
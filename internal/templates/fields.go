@@ -0,0 +1,322 @@
+package templates
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// contentTypeNames maps the names accepted in manifests and on the command
+// line to content types.
+var contentTypeNames = map[string]ContentType{
+	"url":      ContentURL,
+	"wifi":     ContentWiFi,
+	"vcard":    ContentVCard,
+	"contact":  ContentVCard,
+	"email":    ContentEmail,
+	"sms":      ContentSMS,
+	"text":     ContentText,
+	"event":    ContentEvent,
+	"ical":     ContentEvent,
+	"geo":      ContentGeo,
+	"location": ContentGeo,
+	"sepa":     ContentSEPA,
+	"epc":      ContentSEPA,
+	"girocode": ContentSEPA,
+	"swissqr":  ContentSwissQR,
+	"qrbill":   ContentSwissQR,
+	"qr-bill":  ContentSwissQR,
+	"merchant": ContentMerchant,
+	"emv":      ContentMerchant,
+	"crypto":   ContentCrypto,
+	"otp":      ContentOTP,
+	"otpauth":  ContentOTP,
+}
+
+// ParseContentType returns the content type for a name such as "wifi" or
+// "vcard". Matching is case-insensitive.
+func ParseContentType(name string) (ContentType, error) {
+	ct, ok := contentTypeNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		names := make([]string, 0, len(contentTypeNames))
+		for n := range contentTypeNames {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return 0, fmt.Errorf("unknown content type %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	return ct, nil
+}
+
+// TypeName returns the canonical name of a content type, as accepted by
+// ParseContentType and used in {type} filename placeholders.
+func TypeName(ct ContentType) string {
+	switch ct {
+	case ContentURL:
+		return "url"
+	case ContentWiFi:
+		return "wifi"
+	case ContentVCard:
+		return "vcard"
+	case ContentEmail:
+		return "email"
+	case ContentSMS:
+		return "sms"
+	case ContentEvent:
+		return "event"
+	case ContentGeo:
+		return "geo"
+	case ContentSEPA:
+		return "sepa"
+	case ContentSwissQR:
+		return "swissqr"
+	case ContentMerchant:
+		return "merchant"
+	case ContentCrypto:
+		return "crypto"
+	case ContentOTP:
+		return "otp"
+	}
+	return "text"
+}
+
+// DetectType guesses the content type of already encoded content from its
+// prefix. Anything unrecognized is reported as text.
+func DetectType(content string) ContentType {
+	upper := strings.ToUpper(content)
+	switch {
+	case strings.HasPrefix(upper, "WIFI:"):
+		return ContentWiFi
+	case strings.HasPrefix(upper, "BEGIN:VCARD"), strings.HasPrefix(upper, "MECARD:"):
+		return ContentVCard
+	case strings.HasPrefix(upper, "BEGIN:VCALENDAR"), strings.HasPrefix(upper, "BEGIN:VEVENT"):
+		return ContentEvent
+	case strings.HasPrefix(upper, "GEO:"):
+		return ContentGeo
+	case strings.HasPrefix(upper, "BCD\n"), strings.HasPrefix(upper, "BCD\r\n"):
+		return ContentSEPA
+	case strings.HasPrefix(upper, "SPC\r\n"), strings.HasPrefix(upper, "SPC\n"):
+		return ContentSwissQR
+	case strings.HasPrefix(upper, "000201"), strings.HasPrefix(upper, "UPI://PAY"):
+		return ContentMerchant
+	case strings.HasPrefix(upper, "BITCOIN:"), strings.HasPrefix(upper, "ETHEREUM:"), strings.HasPrefix(upper, "LIGHTNING:"):
+		return ContentCrypto
+	case strings.HasPrefix(upper, "OTPAUTH://"):
+		return ContentOTP
+	case strings.HasPrefix(upper, "MAILTO:"):
+		return ContentEmail
+	case strings.HasPrefix(upper, "SMSTO:"), strings.HasPrefix(upper, "SMS:"):
+		return ContentSMS
+	case strings.HasPrefix(upper, "HTTP://"), strings.HasPrefix(upper, "HTTPS://"):
+		return ContentURL
+	}
+	return ContentText
+}
+
+// RequiredErrorCorrection returns the error correction level a content type
+// mandates, or "" when any level may be used. EPC payment codes and Swiss
+// QR-bills must use M.
+func RequiredErrorCorrection(ct ContentType) string {
+	switch ct {
+	case ContentSEPA:
+		return SEPAErrorLevel
+	case ContentSwissQR:
+		return SwissQRErrorLevel
+	}
+	return ""
+}
+
+// RequiresSwissCross reports whether codes of a content type must carry the
+// Swiss cross in their center, as Swiss QR-bills do.
+func RequiresSwissCross(ct ContentType) bool {
+	return ct == ContentSwissQR
+}
+
+// ContainsSecrets reports whether content of a type carries credentials,
+// such as WiFi passwords or OTP seeds, that must not be stored in shared
+// caches or logs.
+func ContainsSecrets(ct ContentType) bool {
+	return ct == ContentWiFi || ct == ContentOTP
+}
+
+// FromFields builds the encoded content for a template from a flat map of
+// field names to values, as found in batch manifests or CLI flags. Field
+// names are lower-case with underscores, e.g. "ssid", "first_name"; the
+// *FromFields helpers document the fields each type reads.
+func FromFields(ct ContentType, fields map[string]string) (string, error) {
+	get := func(key string) string {
+		return strings.TrimSpace(fields[key])
+	}
+
+	switch ct {
+	case ContentURL, ContentText:
+		// The "content" field is used verbatim.
+		content := fields["content"]
+		if content == "" {
+			return "", fmt.Errorf("field 'content' is required")
+		}
+		return content, nil
+
+	case ContentWiFi:
+		// "encryption" is WPA, SAE, WPA2-EAP, WEP or nopass. Enterprise
+		// networks also read "eap_method", "phase2_method", "identity"
+		// and "anonymous_identity".
+		ssid := get("ssid")
+		if ssid == "" {
+			return "", fmt.Errorf("field 'ssid' is required")
+		}
+		enc, err := parseWiFiEncryption(get("encryption"))
+		if err != nil {
+			return "", err
+		}
+		hidden, err := parseBoolField("hidden", get("hidden"))
+		if err != nil {
+			return "", err
+		}
+		transitionDisable, err := parseBoolField("transition_disable", get("transition_disable"))
+		if err != nil {
+			return "", err
+		}
+		data := &WiFiData{
+			SSID:              ssid,
+			Password:          fields["password"],
+			Encryption:        enc,
+			Hidden:            hidden,
+			TransitionDisable: transitionDisable,
+			EAPMethod:         get("eap_method"),
+			Phase2Method:      get("phase2_method"),
+			Identity:          get("identity"),
+			AnonymousIdentity: get("anonymous_identity"),
+		}
+		if enc == WiFiEAP && data.EAPMethod == "" {
+			data.EAPMethod = WiFiEAPMethods()[0]
+		}
+		if err := data.Validate(); err != nil {
+			return "", err
+		}
+		return data.Encode(), nil
+
+	case ContentVCard:
+		// "format" is vcard or mecard; see vcardFromFields for the rest.
+		meCard, err := parseContactFormat(get("format"))
+		if err != nil {
+			return "", err
+		}
+		data, err := vcardFromFields(get)
+		if err != nil {
+			return "", err
+		}
+		if meCard {
+			if len(data.Photo) > 0 {
+				return "", fmt.Errorf("a MeCard cannot hold a photo (use format=vcard)")
+			}
+			return data.EncodeMeCard(), nil
+		}
+		return data.Encode(), nil
+
+	case ContentEmail:
+		addr := get("address")
+		if addr == "" {
+			return "", fmt.Errorf("field 'address' is required")
+		}
+		data := &EmailData{
+			Address: addr,
+			Subject: get("subject"),
+			Body:    get("body"),
+		}
+		return data.Encode(), nil
+
+	case ContentSMS:
+		phone := get("phone")
+		if phone == "" {
+			return "", fmt.Errorf("field 'phone' is required")
+		}
+		data := &SMSData{
+			Phone:   phone,
+			Message: get("message"),
+		}
+		return data.Encode(), nil
+
+	case ContentEvent:
+		data, err := eventFromFields(get)
+		if err != nil {
+			return "", err
+		}
+		return data.Encode(), nil
+
+	case ContentGeo:
+		data, err := geoFromFields(get)
+		if err != nil {
+			return "", err
+		}
+		return data.Encode(), nil
+
+	case ContentSEPA:
+		data, err := sepaFromFields(get)
+		if err != nil {
+			return "", err
+		}
+		return data.Encode(), nil
+
+	case ContentSwissQR:
+		data, err := swissQRFromFields(get)
+		if err != nil {
+			return "", err
+		}
+		return data.Encode(), nil
+
+	case ContentMerchant:
+		data, err := merchantFromFields(get)
+		if err != nil {
+			return "", err
+		}
+		return data.Encode(), nil
+
+	case ContentCrypto:
+		data, err := cryptoFromFields(get)
+		if err != nil {
+			return "", err
+		}
+		return data.Encode(), nil
+
+	case ContentOTP:
+		data, err := otpFromFields(get)
+		if err != nil {
+			return "", err
+		}
+		return data.Encode(), nil
+	}
+
+	return "", fmt.Errorf("unsupported content type: %d", ct)
+}
+
+// parseWiFiEncryption accepts the WIFI: type names (WPA, WEP, nopass) and
+// a few common aliases. An empty value defaults to WPA.
+func parseWiFiEncryption(s string) (WiFiEncryption, error) {
+	switch strings.ToLower(s) {
+	case "", "wpa", "wpa2", "wpa3":
+		return WiFiWPA, nil
+	case "sae", "wpa3-sae":
+		return WiFiSAE, nil
+	case "wpa2-eap", "wpa-eap", "eap", "enterprise":
+		return WiFiEAP, nil
+	case "wep":
+		return WiFiWEP, nil
+	case "nopass", "none", "open":
+		return WiFiNone, nil
+	}
+	return "", fmt.Errorf("invalid encryption %q (expected WPA, SAE, WPA2-EAP, WEP or nopass)", s)
+}
+
+// parseBoolField parses an optional boolean field. Empty means false.
+func parseBoolField(name, value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("field '%s' must be true or false", name)
+	}
+	return b, nil
+}
@@ -0,0 +1,217 @@
+package templates
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GeoProvider selects how a location is encoded.
+type GeoProvider string
+
+const (
+	GeoURI    GeoProvider = "geo"    // geo: URI (RFC 5870), opened by the default maps app
+	GeoGoogle GeoProvider = "google" // Google Maps link
+	GeoApple  GeoProvider = "apple"  // Apple Maps link
+	GeoOSM    GeoProvider = "osm"    // OpenStreetMap link
+)
+
+// geoOSMZoom is the OpenStreetMap zoom level for links (street level).
+const geoOSMZoom = 16
+
+// GeoProviders returns the available location encodings in display order.
+func GeoProviders() []struct {
+	Provider GeoProvider
+	Name     string
+} {
+	return []struct {
+		Provider GeoProvider
+		Name     string
+	}{
+		{GeoURI, "geo: URI"},
+		{GeoGoogle, "Google Maps"},
+		{GeoApple, "Apple Maps"},
+		{GeoOSM, "OpenStreetMap"},
+	}
+}
+
+// GeoData holds a map location.
+type GeoData struct {
+	Latitude    float64 // Decimal degrees, -90 to 90 (north is positive)
+	Longitude   float64 // Decimal degrees, -180 to 180 (east is positive)
+	Altitude    float64 // Meters above sea level (geo: URIs only)
+	HasAltitude bool
+	Label       string      // Shown on the pin where the encoding supports it
+	Provider    GeoProvider // Empty means GeoURI
+}
+
+// Validate checks that the coordinates are in range.
+func (g *GeoData) Validate() error {
+	if math.IsNaN(g.Latitude) || g.Latitude < -90 || g.Latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if math.IsNaN(g.Longitude) || g.Longitude < -180 || g.Longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// Encode generates a geo: URI or a map link, depending on Provider.
+// Coordinates are rounded to 6 decimal places (about 10 cm).
+func (g *GeoData) Encode() string {
+	lat, lon := formatDegrees(g.Latitude), formatDegrees(g.Longitude)
+
+	switch g.Provider {
+	case GeoGoogle:
+		return "https://www.google.com/maps/search/?api=1&query=" + lat + "," + lon
+	case GeoApple:
+		link := "https://maps.apple.com/?ll=" + lat + "," + lon
+		if g.Label != "" {
+			link += "&q=" + url.QueryEscape(g.Label)
+		}
+		return link
+	case GeoOSM:
+		return fmt.Sprintf("https://www.openstreetmap.org/?mlat=%s&mlon=%s#map=%d/%s/%s",
+			lat, lon, geoOSMZoom, lat, lon)
+	}
+
+	// Format: geo:<lat>,<lon>[,<alt>][?q=<lat>,<lon>(<label>)]
+	uri := "geo:" + lat + "," + lon
+	if g.HasAltitude {
+		uri += "," + strconv.FormatFloat(g.Altitude, 'f', -1, 64)
+	}
+	if g.Label != "" {
+		// The q parameter is the de facto way to label a pin (Android).
+		uri += "?q=" + lat + "," + lon + "(" + paramEscape(g.Label) + ")"
+	}
+	return uri
+}
+
+// formatDegrees formats a coordinate with at most 6 decimal places.
+func formatDegrees(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 6, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// ParseCoordinate parses a latitude (lat true) or longitude in decimal
+// degrees ("48.8584", "-2.2945", "48.8584 N") or degrees, minutes and
+// seconds ("48°51'29.6\"N", "2 17 40.2 E", "48°51.493'N"). A hemisphere
+// letter (N/S for latitude, E/W for longitude) may lead or trail; S and W
+// make the value negative. The result is range checked.
+func ParseCoordinate(s string, lat bool) (float64, error) {
+	axis, limit, positive, negative := "longitude", 180.0, "E", "W"
+	if lat {
+		axis, limit, positive, negative = "latitude", 90.0, "N", "S"
+	}
+	invalid := fmt.Errorf("invalid %s %q (expected decimal degrees or degrees, minutes and seconds)", axis, s)
+
+	v := strings.ToUpper(strings.TrimSpace(s))
+	if v == "" {
+		return 0, fmt.Errorf("%s is required", axis)
+	}
+
+	// Hemisphere letter.
+	sign := 1.0
+	hemisphere := ""
+	if last := v[len(v)-1:]; last == positive || last == negative {
+		hemisphere, v = last, v[:len(v)-1]
+	} else if first := v[:1]; first == positive || first == negative {
+		hemisphere, v = first, v[1:]
+	}
+	if hemisphere == negative {
+		sign = -1
+	}
+
+	// Degrees, minutes and seconds separated by symbols or spaces.
+	v = dmsReplacer.Replace(v)
+	parts := strings.Fields(v)
+	if len(parts) == 0 || len(parts) > 3 {
+		return 0, invalid
+	}
+	var values []float64
+	for _, p := range parts {
+		f, err := strconv.ParseFloat(p, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, invalid
+		}
+		values = append(values, f)
+	}
+
+	degrees := values[0]
+	if degrees < 0 || strings.HasPrefix(parts[0], "-") {
+		if hemisphere != "" {
+			return 0, fmt.Errorf("invalid %s %q: use either a minus sign or %s, not both", axis, s, negative)
+		}
+		sign, degrees = -1, -degrees
+	}
+	for i, f := range values[1:] {
+		if f < 0 || f >= 60 {
+			return 0, fmt.Errorf("invalid %s %q: minutes and seconds must be between 0 and 60", axis, s)
+		}
+		if i == 0 {
+			degrees += f / 60
+		} else {
+			degrees += f / 3600
+		}
+	}
+	if len(values) > 1 && values[0] != math.Trunc(values[0]) {
+		return 0, invalid
+	}
+
+	degrees *= sign
+	if degrees < -limit || degrees > limit {
+		return 0, fmt.Errorf("%s must be between %g and %g", axis, -limit, limit)
+	}
+	return degrees, nil
+}
+
+// dmsReplacer turns degree, minute and second symbols into spaces.
+var dmsReplacer = strings.NewReplacer(
+	"°", " ", "º", " ", "'", " ", "′", " ", "’", " ",
+	`"`, " ", "″", " ", "”", " ",
+)
+
+// parseGeoProvider accepts the provider names and a few aliases. An empty
+// value means a geo: URI.
+func parseGeoProvider(s string) (GeoProvider, error) {
+	switch strings.ToLower(s) {
+	case "", "geo", "uri":
+		return GeoURI, nil
+	case "google", "gmaps", "google-maps":
+		return GeoGoogle, nil
+	case "apple", "apple-maps":
+		return GeoApple, nil
+	case "osm", "openstreetmap":
+		return GeoOSM, nil
+	}
+	return "", fmt.Errorf("invalid provider %q (expected geo, google, apple or osm)", s)
+}
+
+// geoFromFields builds a location from form or manifest fields. "latitude"
+// and "longitude" are decimal degrees or degrees, minutes and seconds.
+func geoFromFields(get func(string) string) (*GeoData, error) {
+	provider, err := parseGeoProvider(get("provider"))
+	if err != nil {
+		return nil, err
+	}
+	g := &GeoData{Label: get("label"), Provider: provider}
+	if g.Latitude, err = ParseCoordinate(get("latitude"), true); err != nil {
+		return nil, err
+	}
+	if g.Longitude, err = ParseCoordinate(get("longitude"), false); err != nil {
+		return nil, err
+	}
+	if alt := strings.TrimSuffix(strings.TrimSpace(get("altitude")), "m"); alt != "" {
+		if g.Altitude, err = strconv.ParseFloat(strings.TrimSpace(alt), 64); err != nil {
+			return nil, fmt.Errorf("invalid altitude %q (expected meters)", get("altitude"))
+		}
+		g.HasAltitude = true
+	}
+	return g, g.Validate()
+}
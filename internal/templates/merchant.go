@@ -0,0 +1,345 @@
+package templates
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// PaymentScheme selects the merchant payment network of a MerchantData.
+type PaymentScheme string
+
+const (
+	SchemePIX    PaymentScheme = "pix"    // Brazil PIX (EMVCo)
+	SchemeUPI    PaymentScheme = "upi"    // India UPI (upi://pay URI)
+	SchemePayNow PaymentScheme = "paynow" // Singapore PayNow (EMVCo)
+)
+
+// PaymentSchemes returns the available merchant payment schemes in display
+// order.
+func PaymentSchemes() []struct {
+	Scheme PaymentScheme
+	Name   string
+} {
+	return []struct {
+		Scheme PaymentScheme
+		Name   string
+	}{
+		{SchemePIX, "PIX (Brazil)"},
+		{SchemeUPI, "UPI (India)"},
+		{SchemePayNow, "PayNow (Singapore)"},
+	}
+}
+
+// EMVCo limits for the merchant name and city.
+const (
+	emvMaxName = 25
+	emvMaxCity = 15
+)
+
+// MerchantData holds a merchant-presented payment request.
+type MerchantData struct {
+	Scheme      PaymentScheme
+	Account     string // PIX key, UPI VPA (name@bank), or PayNow mobile number (+65...) or UEN
+	Name        string // Merchant or payee name
+	City        string // Merchant city (PIX; PayNow defaults to Singapore)
+	Amount      int64  // In cents (centavos, paise); 0 lets the payer enter the amount
+	Reference   string // Transaction ID (PIX txid, UPI tr, PayNow bill number)
+	Description string // Shown to the payer (PIX and UPI)
+}
+
+var (
+	pixPhone   = regexp.MustCompile(`^\+55[0-9]{10,11}$`)
+	pixEVP     = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	pixTxID    = regexp.MustCompile(`^[A-Za-z0-9]{1,25}$`)
+	upiVPA     = regexp.MustCompile(`^[A-Za-z0-9.\-_]{2,256}@[A-Za-z][A-Za-z0-9]{1,63}$`)
+	payNowUEN  = regexp.MustCompile(`^[0-9]{8}[A-Z]$|^[0-9]{9}[A-Z]$|^[ST][0-9]{2}[A-Z]{2}[0-9]{4}[A-Z]$`)
+	payNowSGHP = regexp.MustCompile(`^\+65[89][0-9]{7}$`)
+)
+
+// Validate checks the fields against the rules of the selected scheme.
+func (m *MerchantData) Validate() error {
+	if m.Amount < 0 || m.Amount > 999999999999 {
+		return fmt.Errorf("amount out of range")
+	}
+	switch m.Scheme {
+	case SchemePIX:
+		if _, err := NormalizePIXKey(m.Account); err != nil {
+			return err
+		}
+		if err := checkEMVText("merchant name", m.Name, emvMaxName, true); err != nil {
+			return err
+		}
+		if err := checkEMVText("city", m.City, emvMaxCity, true); err != nil {
+			return err
+		}
+		if m.Reference != "" && !pixTxID.MatchString(m.Reference) {
+			return fmt.Errorf("invalid PIX transaction ID %q (up to 25 letters or digits)", m.Reference)
+		}
+	case SchemeUPI:
+		if !upiVPA.MatchString(m.Account) {
+			return fmt.Errorf("invalid UPI address %q (expected name@bank)", m.Account)
+		}
+		if m.Name == "" {
+			return fmt.Errorf("payee name is required")
+		}
+		if utf8.RuneCountInString(m.Reference) > 35 {
+			return fmt.Errorf("UPI transaction reference must be at most 35 characters")
+		}
+	case SchemePayNow:
+		if _, err := payNowProxy(m.Account); err != nil {
+			return err
+		}
+		if err := checkEMVText("merchant name", m.Name, emvMaxName, true); err != nil {
+			return err
+		}
+		if err := checkEMVText("city", m.City, emvMaxCity, false); err != nil {
+			return err
+		}
+		if utf8.RuneCountInString(m.Reference) > 25 {
+			return fmt.Errorf("PayNow reference must be at most 25 characters")
+		}
+	default:
+		return fmt.Errorf("invalid payment scheme %q (expected pix, upi or paynow)", m.Scheme)
+	}
+	_, err := m.encode()
+	return err
+}
+
+// checkEMVText checks a printable ASCII EMVCo text field.
+func checkEMVText(name, value string, max int, required bool) error {
+	if required && value == "" {
+		return fmt.Errorf("%s is required", name)
+	}
+	if len(value) > max {
+		return fmt.Errorf("%s must be at most %d characters", name, max)
+	}
+	for _, r := range value {
+		if r < 0x20 || r > 0x7E {
+			return fmt.Errorf("%s must use plain ASCII letters (found %q)", name, r)
+		}
+	}
+	return nil
+}
+
+// Encode generates the payment payload: an EMVCo MPM string for PIX and
+// PayNow, or a upi://pay URI for UPI. Call Validate first: invalid fields
+// encode as an empty string.
+func (m *MerchantData) Encode() string {
+	s, _ := m.encode()
+	return s
+}
+
+func (m *MerchantData) encode() (string, error) {
+	amount := ""
+	if m.Amount > 0 {
+		amount = formatEMVAmount(m.Amount)
+	}
+
+	switch m.Scheme {
+	case SchemeUPI:
+		// Format: upi://pay?pa=<vpa>&pn=<name>[&am=<amount>&cu=INR][&tr=<ref>][&tn=<note>]
+		params := []string{"pa=" + paramEscape(m.Account), "pn=" + paramEscape(m.Name)}
+		if amount != "" {
+			params = append(params, "am="+amount, "cu=INR")
+		}
+		if m.Reference != "" {
+			params = append(params, "tr="+paramEscape(m.Reference))
+		}
+		if m.Description != "" {
+			params = append(params, "tn="+paramEscape(m.Description))
+		}
+		return "upi://pay?" + strings.Join(params, "&"), nil
+
+	case SchemePIX:
+		key, err := NormalizePIXKey(m.Account)
+		if err != nil {
+			return "", err
+		}
+		txid := m.Reference
+		if txid == "" {
+			txid = "***" // No transaction ID
+		}
+		return BuildEMV(EMVFields{
+			{ID: "26", Fields: EMVFields{
+				{ID: "00", Value: "br.gov.bcb.pix"},
+				{ID: "01", Value: key},
+				{ID: "02", Value: m.Description},
+			}},
+			{ID: EMVMerchantCategory, Value: "0000"},
+			{ID: EMVCurrency, Value: "986"}, // BRL
+			{ID: EMVAmount, Value: amount},
+			{ID: EMVCountry, Value: "BR"},
+			{ID: EMVMerchantName, Value: m.Name},
+			{ID: EMVMerchantCity, Value: m.City},
+			{ID: EMVAdditionalData, Fields: EMVFields{{ID: "05", Value: txid}}},
+		})
+
+	case SchemePayNow:
+		proxyType, err := payNowProxy(m.Account)
+		if err != nil {
+			return "", err
+		}
+		// A code without an amount is static and lets the payer edit it.
+		method, editable := "11", "1"
+		if amount != "" {
+			method, editable = "12", "0"
+		}
+		city := m.City
+		if city == "" {
+			city = "Singapore"
+		}
+		return BuildEMV(EMVFields{
+			{ID: EMVInitiationMethod, Value: method},
+			{ID: "26", Fields: EMVFields{
+				{ID: "00", Value: "SG.PAYNOW"},
+				{ID: "01", Value: proxyType},
+				{ID: "02", Value: strings.ToUpper(compactSpaces(m.Account))},
+				{ID: "03", Value: editable},
+			}},
+			{ID: EMVMerchantCategory, Value: "0000"},
+			{ID: EMVCurrency, Value: "702"}, // SGD
+			{ID: EMVAmount, Value: amount},
+			{ID: EMVCountry, Value: "SG"},
+			{ID: EMVMerchantName, Value: m.Name},
+			{ID: EMVMerchantCity, Value: city},
+			{ID: EMVAdditionalData, Fields: EMVFields{{ID: "01", Value: m.Reference}}},
+		})
+	}
+	return "", fmt.Errorf("invalid payment scheme %q (expected pix, upi or paynow)", m.Scheme)
+}
+
+// paramEscape percent-encodes a URI query parameter, with %20 for spaces
+// since several wallet and map apps show a literal "+", and a plain @ in
+// addresses.
+func paramEscape(s string) string {
+	return strings.NewReplacer("+", "%20", "%40", "@").Replace(url.QueryEscape(s))
+}
+
+// NormalizePIXKey validates a PIX key and returns it in the form the
+// directory uses: CPF and CNPJ numbers as bare digits (their check digits
+// are verified), phone numbers as +55 followed by the area code and number,
+// emails in lower case, and random keys (EVP) as lower-case UUIDs.
+func NormalizePIXKey(key string) (string, error) {
+	k := strings.TrimSpace(key)
+	switch {
+	case k == "":
+		return "", fmt.Errorf("PIX key is required")
+	case strings.Contains(k, "@"):
+		if len(k) > 77 || strings.ContainsAny(k, " ") {
+			return "", fmt.Errorf("invalid PIX email key %q", key)
+		}
+		return strings.ToLower(k), nil
+	case strings.HasPrefix(k, "+"):
+		phone := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(k)
+		if !pixPhone.MatchString(phone) {
+			return "", fmt.Errorf("invalid PIX phone key %q (expected +55, area code and number)", key)
+		}
+		return phone, nil
+	case pixEVP.MatchString(strings.ToLower(k)):
+		return strings.ToLower(k), nil
+	}
+
+	digits := strings.NewReplacer(".", "", "-", "", "/", "", " ", "").Replace(k)
+	if strings.Trim(digits, "0123456789") != "" {
+		return "", fmt.Errorf("invalid PIX key %q (expected a CPF, CNPJ, +55 phone, email or random key)", key)
+	}
+	switch len(digits) {
+	case 11:
+		if !validCPF(digits) {
+			return "", fmt.Errorf("invalid CPF %q: check digits do not match", key)
+		}
+	case 14:
+		if !validCNPJ(digits) {
+			return "", fmt.Errorf("invalid CNPJ %q: check digits do not match", key)
+		}
+	default:
+		return "", fmt.Errorf("invalid PIX key %q (a CPF has 11 digits and a CNPJ 14)", key)
+	}
+	return digits, nil
+}
+
+// validCPF checks the two modulo 11 check digits of a Brazilian CPF.
+func validCPF(d string) bool {
+	if strings.Count(d, d[:1]) == len(d) {
+		return false // 000.000.000-00 and the like pass the checksum but are invalid
+	}
+	return mod11Digit(d[:9], []int{10, 9, 8, 7, 6, 5, 4, 3, 2}) == d[9] &&
+		mod11Digit(d[:10], []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2}) == d[10]
+}
+
+// validCNPJ checks the two modulo 11 check digits of a Brazilian CNPJ.
+func validCNPJ(d string) bool {
+	if strings.Count(d, d[:1]) == len(d) {
+		return false
+	}
+	return mod11Digit(d[:12], []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == d[12] &&
+		mod11Digit(d[:13], []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == d[13]
+}
+
+// mod11Digit computes a CPF/CNPJ check digit with the given weights.
+func mod11Digit(digits string, weights []int) byte {
+	sum := 0
+	for i, w := range weights {
+		sum += int(digits[i]-'0') * w
+	}
+	r := sum % 11
+	if r < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - r)
+}
+
+// payNowProxy returns the PayNow proxy type of an account: "0" for a
+// Singapore mobile number (+65...) or "2" for a UEN.
+func payNowProxy(account string) (string, error) {
+	v := strings.ToUpper(compactSpaces(account))
+	switch {
+	case payNowSGHP.MatchString(v):
+		return "0", nil
+	case payNowUEN.MatchString(v):
+		return "2", nil
+	}
+	return "", fmt.Errorf("invalid PayNow account %q (expected a +65 mobile number or a UEN)", account)
+}
+
+// parsePaymentScheme accepts the scheme names. There is no default.
+func parsePaymentScheme(s string) (PaymentScheme, error) {
+	switch strings.ToLower(s) {
+	case "pix":
+		return SchemePIX, nil
+	case "upi":
+		return SchemeUPI, nil
+	case "paynow":
+		return SchemePayNow, nil
+	}
+	return "", fmt.Errorf("invalid payment scheme %q (expected pix, upi or paynow)", s)
+}
+
+// merchantFromFields builds a payment request from form or manifest fields.
+// "scheme" is pix, upi or paynow and "account" the payee for that scheme.
+func merchantFromFields(get func(string) string) (*MerchantData, error) {
+	scheme, err := parsePaymentScheme(get("scheme"))
+	if err != nil {
+		return nil, err
+	}
+	m := &MerchantData{
+		Scheme:      scheme,
+		Account:     get("account"),
+		Name:        get("name"),
+		City:        get("city"),
+		Reference:   get("reference"),
+		Description: get("description"),
+	}
+	if m.Account == "" {
+		return nil, fmt.Errorf("field 'account' is required")
+	}
+	if amount := get("amount"); amount != "" {
+		if m.Amount, err = ParseAmount(amount); err != nil {
+			return nil, err
+		}
+	}
+	return m, m.Validate()
+}
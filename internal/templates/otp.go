@@ -0,0 +1,259 @@
+package templates
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// OTPType is the kind of one-time password of an authenticator enrollment.
+type OTPType string
+
+const (
+	OTPTime    OTPType = "totp" // Time-based (RFC 6238)
+	OTPCounter OTPType = "hotp" // Counter-based (RFC 4226)
+)
+
+// OTPAlgorithms returns the HMAC algorithms of the Key URI format, default
+// first.
+func OTPAlgorithms() []string {
+	return []string{"SHA1", "SHA256", "SHA512"}
+}
+
+// OTP defaults: authenticator apps assume the digits and period when the
+// parameter is absent.
+const (
+	OTPDefaultDigits = 6
+	OTPDefaultPeriod = 30
+	OTPSecretBytes   = 20 // Generated secrets: 160 bits, as RFC 4226 recommends
+)
+
+const (
+	otpauthScheme       = "otpauth://"
+	otpSecretParam      = "secret"
+	otpRedactedSecret   = "REDACTED"
+	otpDefaultAlgorithm = "SHA1"
+	otpMinSecretBytes   = 16 // 128 bits, the RFC 4226 minimum
+)
+
+// OTPData holds an authenticator enrollment in the otpauth:// Key URI
+// format.
+type OTPData struct {
+	Type      OTPType
+	Issuer    string // Service or company, e.g. "ACME Corp" (recommended)
+	Account   string // User account, e.g. "alice@example.com" (required)
+	Secret    string // Base32 shared secret; spaces and padding are ignored
+	Algorithm string // "SHA1" (default), "SHA256" or "SHA512"
+	Digits    int    // 6 (default) or 8
+	Period    int    // TOTP step in seconds; 0 means 30
+	Counter   uint64 // HOTP initial counter
+}
+
+// Validate checks the enrollment against the Key URI format.
+func (o *OTPData) Validate() error {
+	if o.Type != OTPTime && o.Type != OTPCounter {
+		return fmt.Errorf("invalid OTP type %q (expected totp or hotp)", o.Type)
+	}
+	if o.Account == "" {
+		return fmt.Errorf("account name is required")
+	}
+	if strings.Contains(o.Issuer, ":") || strings.Contains(o.Account, ":") {
+		return fmt.Errorf("issuer and account name must not contain a colon")
+	}
+	key, err := decodeOTPSecret(o.Secret)
+	if err != nil {
+		return err
+	}
+	if len(key) < otpMinSecretBytes {
+		return fmt.Errorf("secret is %d bits; at least %d are required", len(key)*8, otpMinSecretBytes*8)
+	}
+	if !isOTPAlgorithm(o.algorithm()) {
+		return fmt.Errorf("invalid algorithm %q (expected SHA1, SHA256 or SHA512)", o.Algorithm)
+	}
+	if d := o.digits(); d != 6 && d != 8 {
+		return fmt.Errorf("digits must be 6 or 8")
+	}
+	if o.Period < 0 {
+		return fmt.Errorf("period must be a positive number of seconds")
+	}
+	if o.Type == OTPCounter && o.Period != 0 {
+		return fmt.Errorf("period only applies to TOTP")
+	}
+	if o.Type == OTPTime && o.Counter != 0 {
+		return fmt.Errorf("counter only applies to HOTP")
+	}
+	return nil
+}
+
+func (o *OTPData) algorithm() string {
+	if o.Algorithm == "" {
+		return otpDefaultAlgorithm
+	}
+	return strings.ToUpper(o.Algorithm)
+}
+
+func (o *OTPData) digits() int {
+	if o.Digits == 0 {
+		return OTPDefaultDigits
+	}
+	return o.Digits
+}
+
+// Encode generates the Key URI. Parameters at their default value are left
+// out, since some authenticator apps reject any they do not support.
+// Format: otpauth://<type>/<issuer>:<account>?secret=<base32>&issuer=<issuer>[&...]
+func (o *OTPData) Encode() string {
+	label := url.PathEscape(o.Account)
+	if o.Issuer != "" {
+		label = url.PathEscape(o.Issuer) + ":" + label
+	}
+
+	params := []string{otpSecretParam + "=" + normalizeOTPSecret(o.Secret)}
+	if o.Issuer != "" {
+		params = append(params, "issuer="+paramEscape(o.Issuer))
+	}
+	if a := o.algorithm(); a != otpDefaultAlgorithm {
+		params = append(params, "algorithm="+a)
+	}
+	if d := o.digits(); d != OTPDefaultDigits {
+		params = append(params, "digits="+strconv.Itoa(d))
+	}
+	if o.Type == OTPCounter {
+		params = append(params, "counter="+strconv.FormatUint(o.Counter, 10))
+	} else if o.Period != 0 && o.Period != OTPDefaultPeriod {
+		params = append(params, "period="+strconv.Itoa(o.Period))
+	}
+	return otpauthScheme + string(o.Type) + "/" + label + "?" + strings.Join(params, "&")
+}
+
+// GenerateOTPSecret returns a random 160-bit secret in unpadded base32.
+func GenerateOTPSecret() (string, error) {
+	key := make([]byte, OTPSecretBytes)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key), nil
+}
+
+// normalizeOTPSecret upper-cases a base32 secret and drops spaces and
+// padding, which several authenticator apps choke on.
+func normalizeOTPSecret(s string) string {
+	return strings.TrimRight(strings.ToUpper(strings.ReplaceAll(s, " ", "")), "=")
+}
+
+func decodeOTPSecret(s string) ([]byte, error) {
+	v := normalizeOTPSecret(s)
+	if v == "" {
+		return nil, fmt.Errorf("secret is required")
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("secret must be base32 (letters A-Z and digits 2-7)")
+	}
+	return key, nil
+}
+
+func isOTPAlgorithm(s string) bool {
+	for _, a := range OTPAlgorithms() {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
+
+// OTPSecret returns the secret of an otpauth:// URI, or "" when content is
+// not one.
+func OTPSecret(content string) string {
+	if !strings.HasPrefix(strings.ToLower(content), otpauthScheme) {
+		return ""
+	}
+	u, err := url.Parse(content)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get(otpSecretParam)
+}
+
+// RedactSecrets replaces the secret of an otpauth:// URI with a
+// placeholder so the content can be stored or shown safely. Other content
+// is returned unchanged.
+func RedactSecrets(content string) string {
+	if !strings.HasPrefix(strings.ToLower(content), otpauthScheme) {
+		return content
+	}
+	base, query, _ := strings.Cut(content, "?")
+	params := strings.Split(query, "&")
+	for i, p := range params {
+		if key, _, _ := strings.Cut(p, "="); strings.EqualFold(key, otpSecretParam) {
+			params[i] = key + "=" + otpRedactedSecret
+		}
+	}
+	return base + "?" + strings.Join(params, "&")
+}
+
+// parseOTPType accepts totp and hotp. An empty value defaults to totp.
+func parseOTPType(s string) (OTPType, error) {
+	switch strings.ToLower(s) {
+	case "", "totp":
+		return OTPTime, nil
+	case "hotp":
+		return OTPCounter, nil
+	}
+	return "", fmt.Errorf("invalid OTP type %q (expected totp or hotp)", s)
+}
+
+// otpFromFields builds an enrollment from form or manifest fields:
+// "otp_type" (totp or hotp), "issuer", "account" and a base32 "secret", or
+// "generate_secret" set to true for a random one.
+func otpFromFields(get func(string) string) (*OTPData, error) {
+	otpType, err := parseOTPType(get("otp_type"))
+	if err != nil {
+		return nil, err
+	}
+	o := &OTPData{
+		Type:      otpType,
+		Issuer:    get("issuer"),
+		Account:   get("account"),
+		Secret:    get("secret"),
+		Algorithm: get("algorithm"),
+	}
+	if o.Account == "" {
+		return nil, fmt.Errorf("field 'account' is required")
+	}
+	generate, err := parseBoolField("generate_secret", get("generate_secret"))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case generate && o.Secret != "":
+		return nil, fmt.Errorf("use either field 'secret' or 'generate_secret', not both")
+	case generate:
+		if o.Secret, err = GenerateOTPSecret(); err != nil {
+			return nil, err
+		}
+	case o.Secret == "":
+		return nil, fmt.Errorf("field 'secret' is required (or set generate_secret=true)")
+	}
+	for _, f := range []struct {
+		name string
+		dst  *int
+	}{{"digits", &o.Digits}, {"period", &o.Period}} {
+		if v := get(f.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("field '%s' must be a positive number", f.name)
+			}
+			*f.dst = n
+		}
+	}
+	if v := get("counter"); v != "" {
+		if o.Counter, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("field 'counter' must be a non-negative number")
+		}
+	}
+	return o, o.Validate()
+}
@@ -0,0 +1,267 @@
+package templates
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// EPC069-12 limits. The payload must fit a version 13 QR code at error
+// correction level M, which holds 331 bytes.
+const (
+	SEPAMaxBytes       = 331
+	SEPAErrorLevel     = "M"
+	sepaMaxName        = 70
+	sepaMaxText        = 140
+	sepaMaxInfo        = 70
+	sepaMaxAmountCents = 99999999999 // EUR 999999999.99
+)
+
+// sepaIBANLengths lists the IBAN length of every country in the SEPA
+// schemes. Overseas territories use their parent country's IBAN.
+var sepaIBANLengths = map[string]int{
+	"AD": 24, "AT": 20, "BE": 16, "BG": 22, "CH": 21, "CY": 28, "CZ": 24,
+	"DE": 22, "DK": 18, "EE": 20, "ES": 24, "FI": 18, "FR": 27, "GB": 22,
+	"GI": 23, "GR": 27, "HR": 21, "HU": 28, "IE": 22, "IS": 26, "IT": 27,
+	"LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MT": 31, "NL": 18,
+	"NO": 15, "PL": 28, "PT": 25, "RO": 24, "SE": 24, "SI": 19, "SK": 24,
+	"SM": 27, "VA": 22,
+}
+
+// SEPAData holds a SEPA credit transfer for an EPC QR code (GiroCode).
+type SEPAData struct {
+	Name      string // Beneficiary name (required, up to 70 characters)
+	IBAN      string // Beneficiary account (required); spaces are ignored
+	BIC       string // Beneficiary bank; optional within the EEA
+	Amount    int64  // In euro cents; 0 lets the payer enter the amount
+	Purpose   string // ISO 20022 purpose code such as "CHAR" (optional)
+	Reference string // RF creditor reference (ISO 11649), e.g. "RF18 5390 0754 7034"
+	Text      string // Unstructured remittance text; not allowed with Reference
+	Info      string // Beneficiary to originator information (optional)
+}
+
+// Validate checks every field against EPC069-12 and the size of the
+// encoded payload.
+func (d *SEPAData) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("beneficiary name is required")
+	}
+	if utf8.RuneCountInString(d.Name) > sepaMaxName {
+		return fmt.Errorf("beneficiary name must be at most %d characters", sepaMaxName)
+	}
+	if err := ValidateIBAN(d.IBAN); err != nil {
+		return err
+	}
+	if d.BIC != "" {
+		if err := ValidateBIC(d.BIC); err != nil {
+			return err
+		}
+	}
+	if d.Amount < 0 || d.Amount > sepaMaxAmountCents {
+		return fmt.Errorf("amount must be between 0.01 and 999999999.99 EUR")
+	}
+	if d.Purpose != "" && !isSEPAPurpose(d.Purpose) {
+		return fmt.Errorf("invalid purpose %q (expected a 4-letter code such as CHAR or GDDS)", d.Purpose)
+	}
+	if d.Reference != "" && d.Text != "" {
+		return fmt.Errorf("use either a creditor reference or remittance text, not both")
+	}
+	if d.Reference != "" {
+		if err := ValidateCreditorReference(d.Reference); err != nil {
+			return err
+		}
+	}
+	if utf8.RuneCountInString(d.Text) > sepaMaxText {
+		return fmt.Errorf("remittance text must be at most %d characters", sepaMaxText)
+	}
+	if utf8.RuneCountInString(d.Info) > sepaMaxInfo {
+		return fmt.Errorf("beneficiary information must be at most %d characters", sepaMaxInfo)
+	}
+	if strings.ContainsAny(d.Name+d.Text+d.Info, "\r\n") {
+		return fmt.Errorf("fields must not contain line breaks")
+	}
+	if n := len(d.Encode()); n > SEPAMaxBytes {
+		return fmt.Errorf("payment data is %d bytes, more than the %d an EPC QR code allows", n, SEPAMaxBytes)
+	}
+	return nil
+}
+
+// Encode generates the EPC069-12 "BCD" payload (version 002, UTF-8).
+// Call Validate first: Encode does not check the fields.
+func (d *SEPAData) Encode() string {
+	amount := ""
+	if d.Amount > 0 {
+		amount = fmt.Sprintf("EUR%d.%02d", d.Amount/100, d.Amount%100)
+	}
+
+	// Format: one element per line; trailing empty elements are omitted.
+	lines := []string{
+		"BCD",
+		"002", // Version: BIC optional
+		"1",   // Character set: UTF-8
+		"SCT", // SEPA credit transfer
+		strings.ToUpper(compactSpaces(d.BIC)),
+		d.Name,
+		strings.ToUpper(compactSpaces(d.IBAN)),
+		amount,
+		strings.ToUpper(d.Purpose),
+		strings.ToUpper(compactSpaces(d.Reference)),
+		d.Text,
+		d.Info,
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// compactSpaces removes the spaces people use to group IBANs and references.
+func compactSpaces(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+}
+
+// ValidateIBAN checks the country, length and mod-97 check digits of an
+// IBAN from a SEPA country. Spaces are ignored.
+func ValidateIBAN(iban string) error {
+	v := strings.ToUpper(compactSpaces(iban))
+	if v == "" {
+		return fmt.Errorf("IBAN is required")
+	}
+	if len(v) < 4 || !isAlnum(v) {
+		return fmt.Errorf("invalid IBAN %q", iban)
+	}
+	length, ok := sepaIBANLengths[v[:2]]
+	if !ok {
+		return fmt.Errorf("invalid IBAN %q: %s is not a SEPA country", iban, v[:2])
+	}
+	if len(v) != length {
+		return fmt.Errorf("invalid IBAN %q: %s IBANs have %d characters, not %d", iban, v[:2], length, len(v))
+	}
+	if !mod97Valid(v) {
+		return fmt.Errorf("invalid IBAN %q: check digits do not match", iban)
+	}
+	return nil
+}
+
+// ValidateBIC checks the shape of a BIC (ISO 9362): a 4-letter bank code,
+// a 2-letter country code, a 2-character location and an optional
+// 3-character branch.
+func ValidateBIC(bic string) error {
+	v := strings.ToUpper(compactSpaces(bic))
+	if (len(v) != 8 && len(v) != 11) || !isAlnum(v) || !isLetters(v[:6]) {
+		return fmt.Errorf("invalid BIC %q (expected 8 or 11 characters such as DEUTDEFF)", bic)
+	}
+	return nil
+}
+
+// ValidateCreditorReference checks an RF creditor reference (ISO 11649):
+// "RF", two check digits and up to 21 letters or digits. Spaces are ignored.
+func ValidateCreditorReference(ref string) error {
+	v := strings.ToUpper(compactSpaces(ref))
+	if len(v) < 5 || len(v) > 25 || !strings.HasPrefix(v, "RF") || !isAlnum(v) {
+		return fmt.Errorf("invalid creditor reference %q (expected RF, 2 check digits and up to 21 characters)", ref)
+	}
+	if !mod97Valid(v) {
+		return fmt.Errorf("invalid creditor reference %q: check digits do not match", ref)
+	}
+	return nil
+}
+
+// mod97Valid applies the ISO 7064 MOD 97-10 check shared by IBANs and RF
+// references: move the first four characters to the end, replace letters
+// with 10-35 and test that the number leaves a remainder of 1.
+func mod97Valid(s string) bool {
+	if s[2] < '0' || s[2] > '9' || s[3] < '0' || s[3] > '9' {
+		return false
+	}
+	var digits strings.Builder
+	for _, r := range s[4:] + s[:4] {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func isAlnum(s string) bool {
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isSEPAPurpose(s string) bool {
+	return len(s) == 4 && isLetters(strings.ToUpper(s))
+}
+
+// ParseAmount parses an amount such as "12.30", "1234,5", "EUR 99" or
+// "CHF 20" into cents. A currency code or sign (EUR, CHF, BRL, INR, SGD,
+// €, R$, ₹, S$) is ignored and at most two decimals are allowed.
+func ParseAmount(s string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	for _, currency := range []string{"EUR", "CHF", "BRL", "INR", "SGD", "€", "R$", "₹", "S$"} {
+		v = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(v, currency), currency))
+	}
+	whole, frac, hasFrac := strings.Cut(strings.Replace(v, ",", ".", 1), ".")
+	invalid := fmt.Errorf("invalid amount %q (expected up to 2 decimals, e.g. 12.50)", s)
+	if whole == "" || len(whole) > 9 || (hasFrac && (frac == "" || len(frac) > 2)) ||
+		strings.Trim(whole+frac, "0123456789") != "" {
+		return 0, invalid
+	}
+	euros, _ := strconv.ParseInt(whole, 10, 64)
+	var cents int64
+	if hasFrac {
+		cents, _ = strconv.ParseInt(frac, 10, 64)
+		if len(frac) == 1 {
+			cents *= 10
+		}
+	}
+	total := euros*100 + cents
+	if total == 0 {
+		return 0, fmt.Errorf("amount must be at least 0.01 (leave it empty to let the payer choose)")
+	}
+	return total, nil
+}
+
+// sepaFromFields builds a credit transfer from form or manifest fields.
+// "amount" is in euros, e.g. 12.50.
+func sepaFromFields(get func(string) string) (*SEPAData, error) {
+	d := &SEPAData{
+		Name:      get("name"),
+		IBAN:      get("iban"),
+		BIC:       get("bic"),
+		Purpose:   get("purpose"),
+		Reference: get("reference"),
+		Text:      get("text"),
+		Info:      get("info"),
+	}
+	if d.Name == "" {
+		return nil, fmt.Errorf("field 'name' is required")
+	}
+	if d.IBAN == "" {
+		return nil, fmt.Errorf("field 'iban' is required")
+	}
+	if amount := get("amount"); amount != "" {
+		var err error
+		if d.Amount, err = ParseAmount(amount); err != nil {
+			return nil, err
+		}
+	}
+	return d, d.Validate()
+}
@@ -0,0 +1,306 @@
+package templates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Swiss Implementation Guidelines for the QR-bill (version 2.3) limits.
+// Like EPC codes, QR-bills are encoded at error correction level M.
+const (
+	SwissQRMaxChars   = 997
+	SwissQRErrorLevel = "M"
+	swissMaxMessage   = 140 // Unstructured message and billing information together
+)
+
+// SwissReferenceType is the kind of payment reference on a QR-bill.
+type SwissReferenceType string
+
+const (
+	SwissRefQRR  SwissReferenceType = "QRR"  // 27-digit QR reference; requires a QR-IBAN
+	SwissRefSCOR SwissReferenceType = "SCOR" // RF creditor reference (ISO 11649)
+	SwissRefNone SwissReferenceType = "NON"  // No reference
+)
+
+// SwissCurrencies returns the currencies a QR-bill may use, default first.
+func SwissCurrencies() []string {
+	return []string{"CHF", "EUR"}
+}
+
+// SwissAddress is a structured (type S) QR-bill address.
+type SwissAddress struct {
+	Name           string // Up to 70 characters
+	Street         string // Up to 70 characters (optional)
+	BuildingNumber string // Up to 16 characters (optional)
+	PostalCode     string // Up to 16 characters
+	Town           string // Up to 35 characters
+	Country        string // ISO 3166-1 alpha-2 code such as "CH"
+}
+
+// IsZero reports whether no field of the address is set.
+func (a SwissAddress) IsZero() bool {
+	return a == SwissAddress{}
+}
+
+// SwissQRData holds the payment part of a Swiss QR-bill.
+type SwissQRData struct {
+	IBAN          string       // CH or LI IBAN or QR-IBAN; spaces are ignored
+	Creditor      SwissAddress // Who gets paid (required)
+	Amount        int64        // In cents (Rappen); 0 lets the payer enter the amount
+	Currency      string       // "CHF" or "EUR"; empty means CHF
+	Debtor        SwissAddress // Who pays (optional)
+	ReferenceType SwissReferenceType
+	Reference     string // QR reference or RF creditor reference; spaces are ignored
+	Message       string // Unstructured message shown to the payer
+	BillInfo      string // Structured billing information (e.g. swico S1 syntax)
+}
+
+// Validate checks the payment data against the QR-bill field rules.
+func (d *SwissQRData) Validate() error {
+	if err := ValidateIBAN(d.IBAN); err != nil {
+		return err
+	}
+	iban := strings.ToUpper(compactSpaces(d.IBAN))
+	if country := iban[:2]; country != "CH" && country != "LI" {
+		return fmt.Errorf("QR-bills require a CH or LI IBAN, not %s", country)
+	}
+	if err := d.Creditor.validate("creditor"); err != nil {
+		return err
+	}
+	if !d.Debtor.IsZero() {
+		if err := d.Debtor.validate("debtor"); err != nil {
+			return err
+		}
+	}
+	if d.Amount < 0 || d.Amount > sepaMaxAmountCents {
+		return fmt.Errorf("amount must be between 0.01 and 999999999.99")
+	}
+	if c := d.currency(); c != "CHF" && c != "EUR" {
+		return fmt.Errorf("invalid currency %q (expected CHF or EUR)", d.Currency)
+	}
+
+	ref := strings.ToUpper(compactSpaces(d.Reference))
+	switch d.ReferenceType {
+	case SwissRefQRR:
+		if !IsQRIBAN(iban) {
+			return fmt.Errorf("a QR reference requires a QR-IBAN (institution ID 30000-31999)")
+		}
+		if err := ValidateQRReference(ref); err != nil {
+			return err
+		}
+	case SwissRefSCOR:
+		if IsQRIBAN(iban) {
+			return fmt.Errorf("a QR-IBAN requires a QR reference (QRR)")
+		}
+		if err := ValidateCreditorReference(ref); err != nil {
+			return err
+		}
+	case SwissRefNone:
+		if IsQRIBAN(iban) {
+			return fmt.Errorf("a QR-IBAN requires a QR reference (QRR)")
+		}
+		if ref != "" {
+			return fmt.Errorf("reference type NON must not have a reference")
+		}
+	default:
+		return fmt.Errorf("invalid reference type %q (expected QRR, SCOR or NON)", d.ReferenceType)
+	}
+
+	if utf8.RuneCountInString(d.Message)+utf8.RuneCountInString(d.BillInfo) > swissMaxMessage {
+		return fmt.Errorf("message and billing information must be at most %d characters together", swissMaxMessage)
+	}
+	for _, s := range []string{d.Message, d.BillInfo} {
+		if err := checkSwissCharset(s); err != nil {
+			return err
+		}
+	}
+	if n := utf8.RuneCountInString(d.Encode()); n > SwissQRMaxChars {
+		return fmt.Errorf("QR-bill data is %d characters, more than the %d allowed", n, SwissQRMaxChars)
+	}
+	return nil
+}
+
+// validate checks a structured address; role names it in errors.
+func (a SwissAddress) validate(role string) error {
+	for _, f := range []struct {
+		name, value string
+		max         int
+		required    bool
+	}{
+		{"name", a.Name, 70, true},
+		{"street", a.Street, 70, false},
+		{"building number", a.BuildingNumber, 16, false},
+		{"postal code", a.PostalCode, 16, true},
+		{"town", a.Town, 35, true},
+	} {
+		if f.required && f.value == "" {
+			return fmt.Errorf("%s %s is required", role, f.name)
+		}
+		if utf8.RuneCountInString(f.value) > f.max {
+			return fmt.Errorf("%s %s must be at most %d characters", role, f.name, f.max)
+		}
+		if err := checkSwissCharset(f.value); err != nil {
+			return fmt.Errorf("%s %s: %w", role, f.name, err)
+		}
+	}
+	if len(a.Country) != 2 || !isLetters(strings.ToUpper(a.Country)) {
+		return fmt.Errorf("%s country must be a 2-letter code such as CH", role)
+	}
+	return nil
+}
+
+// checkSwissCharset reports characters outside the Latin subset QR-bills
+// allow: printable Basic Latin, Latin-1 Supplement, Latin Extended-A, the
+// Romanian Ș, ș, Ț, ț and the euro sign.
+func checkSwissCharset(s string) error {
+	for _, r := range s {
+		switch {
+		case r >= 0x20 && r <= 0x7E, r >= 0xA0 && r <= 0x17F:
+		case r == 'Ș', r == 'ș', r == 'Ț', r == 'ț', r == '€':
+		default:
+			return fmt.Errorf("character %q is not allowed in QR-bills", r)
+		}
+	}
+	return nil
+}
+
+func (d *SwissQRData) currency() string {
+	if d.Currency == "" {
+		return "CHF"
+	}
+	return strings.ToUpper(d.Currency)
+}
+
+// Encode generates the "SPC" payload (version 0200, UTF-8) with CR+LF line
+// endings. Call Validate first: Encode does not check the fields.
+func (d *SwissQRData) Encode() string {
+	amount := ""
+	if d.Amount > 0 {
+		amount = fmt.Sprintf("%d.%02d", d.Amount/100, d.Amount%100)
+	}
+
+	lines := []string{"SPC", "0200", "1", strings.ToUpper(compactSpaces(d.IBAN))}
+	lines = append(lines, d.Creditor.lines()...)
+	lines = append(lines, SwissAddress{}.lines()...) // Ultimate creditor: reserved, always empty
+	lines = append(lines, amount, d.currency())
+	lines = append(lines, d.Debtor.lines()...)
+	lines = append(lines,
+		string(d.ReferenceType),
+		strings.ToUpper(compactSpaces(d.Reference)),
+		d.Message,
+		"EPD", // End of payment data
+	)
+	if d.BillInfo != "" {
+		lines = append(lines, d.BillInfo)
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// lines returns the seven address elements; a zero address is all empty.
+func (a SwissAddress) lines() []string {
+	if a.IsZero() {
+		return make([]string, 7)
+	}
+	return []string{"S", a.Name, a.Street, a.BuildingNumber, a.PostalCode, a.Town, strings.ToUpper(a.Country)}
+}
+
+// IsQRIBAN reports whether iban is a QR-IBAN, whose institution ID
+// (characters 5-9) is between 30000 and 31999. Spaces are ignored.
+func IsQRIBAN(iban string) bool {
+	v := compactSpaces(iban)
+	if len(v) < 9 {
+		return false
+	}
+	iid, err := strconv.Atoi(v[4:9])
+	return err == nil && iid >= 30000 && iid <= 31999
+}
+
+// ValidateQRReference checks a 27-digit QR reference, whose last digit is
+// a recursive modulo 10 check digit over the first 26. Spaces are ignored.
+func ValidateQRReference(ref string) error {
+	v := compactSpaces(ref)
+	if len(v) != 27 || strings.Trim(v, "0123456789") != "" {
+		return fmt.Errorf("invalid QR reference %q (expected 27 digits)", ref)
+	}
+	if qrReferenceCheckDigit(v[:26]) != v[26] {
+		return fmt.Errorf("invalid QR reference %q: check digit does not match", ref)
+	}
+	return nil
+}
+
+// qrReferenceCheckDigit computes the recursive modulo 10 check digit used
+// by QR references (and ISR references before them).
+func qrReferenceCheckDigit(digits string) byte {
+	table := [10]int{0, 9, 4, 6, 8, 2, 7, 1, 3, 5}
+	carry := 0
+	for _, r := range digits {
+		carry = table[(carry+int(r-'0'))%10]
+	}
+	return byte('0' + (10-carry)%10)
+}
+
+// parseSwissReferenceType accepts QRR, SCOR and NON. An empty value is
+// inferred from the reference: none means NON, RF... means SCOR and
+// anything else QRR.
+func parseSwissReferenceType(s, ref string) (SwissReferenceType, error) {
+	switch strings.ToUpper(s) {
+	case "QRR":
+		return SwissRefQRR, nil
+	case "SCOR":
+		return SwissRefSCOR, nil
+	case "NON", "NONE":
+		return SwissRefNone, nil
+	case "":
+		switch {
+		case ref == "":
+			return SwissRefNone, nil
+		case strings.HasPrefix(strings.ToUpper(ref), "RF"):
+			return SwissRefSCOR, nil
+		}
+		return SwissRefQRR, nil
+	}
+	return "", fmt.Errorf("invalid reference type %q (expected QRR, SCOR or NON)", s)
+}
+
+// swissAddressFromFields reads the <prefix>_name, _street, _building,
+// _postal_code, _town and _country fields.
+func swissAddressFromFields(get func(string) string, prefix string) SwissAddress {
+	return SwissAddress{
+		Name:           get(prefix + "_name"),
+		Street:         get(prefix + "_street"),
+		BuildingNumber: get(prefix + "_building"),
+		PostalCode:     get(prefix + "_postal_code"),
+		Town:           get(prefix + "_town"),
+		Country:        get(prefix + "_country"),
+	}
+}
+
+// swissQRFromFields builds a QR-bill from form or manifest fields. The
+// creditor and optional debtor addresses use the "creditor_" and "debtor_"
+// fields read by swissAddressFromFields.
+func swissQRFromFields(get func(string) string) (*SwissQRData, error) {
+	if get("iban") == "" {
+		return nil, fmt.Errorf("field 'iban' is required")
+	}
+	d := &SwissQRData{
+		IBAN:      get("iban"),
+		Creditor:  swissAddressFromFields(get, "creditor"),
+		Currency:  get("currency"),
+		Debtor:    swissAddressFromFields(get, "debtor"),
+		Reference: get("reference"),
+		Message:   get("message"),
+		BillInfo:  get("bill_info"),
+	}
+	var err error
+	if d.ReferenceType, err = parseSwissReferenceType(get("reference_type"), d.Reference); err != nil {
+		return nil, err
+	}
+	if amount := get("amount"); amount != "" {
+		if d.Amount, err = ParseAmount(amount); err != nil {
+			return nil, err
+		}
+	}
+	return d, d.Validate()
+}
@@ -20,6 +20,13 @@ const (
 	ContentEmail
 	ContentSMS
 	ContentText
+	ContentEvent
+	ContentGeo
+	ContentSEPA
+	ContentSwissQR
+	ContentMerchant
+	ContentCrypto
+	ContentOTP
 )
 
 // ContentTypeInfo holds display metadata for a content type.
@@ -38,6 +45,13 @@ func AvailableTypes() []ContentTypeInfo {
 		{ContentVCard, "Contact", "👤", "Contact card (vCard)"},
 		{ContentEmail, "Email", "✉️ ", "Email with subject & body"},
 		{ContentSMS, "SMS", "💬", "Text message"},
+		{ContentEvent, "Event", "📅", "Calendar event (iCalendar)"},
+		{ContentGeo, "Location", "📍", "Map location (geo: or map link)"},
+		{ContentSEPA, "SEPA Payment", "💶", "EPC credit transfer (GiroCode)"},
+		{ContentSwissQR, "Swiss QR-bill", "🧾", "Swiss QR-bill payment part"},
+		{ContentMerchant, "Merchant Payment", "🏪", "PIX, UPI or PayNow payment"},
+		{ContentCrypto, "Crypto Payment", "🪙", "Bitcoin, Ethereum or Lightning"},
+		{ContentOTP, "Authenticator", "🔐", "2FA enrollment (otpauth://)"},
 		{ContentText, "Text", "📝", "Plain text"},
 	}
 }
@@ -46,84 +60,121 @@ func AvailableTypes() []ContentTypeInfo {
 type WiFiEncryption string
 
 const (
-	WiFiWPA  WiFiEncryption = "WPA"
+	WiFiWPA  WiFiEncryption = "WPA"      // WPA/WPA2-Personal, or WPA3 in transition mode
+	WiFiSAE  WiFiEncryption = "SAE"      // WPA3-Personal only
+	WiFiEAP  WiFiEncryption = "WPA2-EAP" // WPA2/WPA3-Enterprise (802.1X)
 	WiFiWEP  WiFiEncryption = "WEP"
 	WiFiNone WiFiEncryption = "nopass"
 )
 
-// WiFiData holds WiFi network information.
+// WiFiEAPMethods returns the EAP methods an enterprise network can be
+// joined with from a QR code, default first. EAP-TLS is missing: it needs
+// a client certificate.
+func WiFiEAPMethods() []string {
+	return []string{"PEAP", "TTLS", "PWD"}
+}
+
+// WiFiPhase2Methods returns the inner (phase 2) authentication methods of
+// PEAP and TTLS, default first.
+func WiFiPhase2Methods() []string {
+	return []string{"MSCHAPV2", "GTC", "PAP"}
+}
+
+// WiFiData holds WiFi network information. The EAP fields apply to
+// WiFiEAP networks only, TransitionDisable to WiFiWPA and WiFiSAE.
 type WiFiData struct {
 	SSID       string
 	Password   string
 	Encryption WiFiEncryption
 	Hidden     bool
-}
 
-// Encode generates the QR code content string for WiFi.
-// Format: WIFI:T:<encryption>;S:<ssid>;P:<password>;H:<hidden>;;
-func (w *WiFiData) Encode() string {
-	hidden := ""
-	if w.Hidden {
-		hidden = "H:true;"
-	}
+	// TransitionDisable tells WPA3-capable devices to never fall back to
+	// WPA2 on this network once they have joined it.
+	TransitionDisable bool
 
-	password := ""
-	if w.Encryption != WiFiNone {
-		password = fmt.Sprintf("P:%s;", escapeWiFiField(w.Password))
-	}
-
-	return fmt.Sprintf("WIFI:T:%s;S:%s;%s%s;",
-		w.Encryption,
-		escapeWiFiField(w.SSID),
-		password,
-		hidden,
-	)
+	EAPMethod         string // PEAP, TTLS or PWD
+	Phase2Method      string // MSCHAPV2, GTC or PAP; PEAP and TTLS only
+	Identity          string // User name
+	AnonymousIdentity string // Outer identity sent before the tunnel is set up (optional)
 }
 
-// VCardData holds contact information.
-type VCardData struct {
-	FirstName    string
-	LastName     string
-	Phone        string
-	Email        string
-	Organization string
-	Title        string
-	URL          string
-}
-
-// Encode generates the QR code content string for vCard 3.0.
-func (v *VCardData) Encode() string {
-	var b strings.Builder
-
-	b.WriteString("BEGIN:VCARD\r\n")
-	b.WriteString("VERSION:3.0\r\n")
-
-	fullName := strings.TrimSpace(v.FirstName + " " + v.LastName)
-	if fullName != "" {
-		b.WriteString(fmt.Sprintf("FN:%s\r\n", fullName))
-		b.WriteString(fmt.Sprintf("N:%s;%s;;;\r\n", v.LastName, v.FirstName))
+// Validate checks that the fields match the encryption type.
+func (w *WiFiData) Validate() error {
+	if w.SSID == "" {
+		return fmt.Errorf("SSID is required")
 	}
-	if v.Organization != "" {
-		b.WriteString(fmt.Sprintf("ORG:%s\r\n", v.Organization))
+	switch w.Encryption {
+	case WiFiWPA, WiFiSAE, WiFiEAP, WiFiWEP, WiFiNone:
+	default:
+		return fmt.Errorf("invalid encryption %q (expected WPA, SAE, WPA2-EAP, WEP or nopass)", w.Encryption)
 	}
-	if v.Title != "" {
-		b.WriteString(fmt.Sprintf("TITLE:%s\r\n", v.Title))
+	if w.TransitionDisable && w.Encryption != WiFiWPA && w.Encryption != WiFiSAE {
+		return fmt.Errorf("transition disable only applies to WPA and SAE networks")
 	}
-	if v.Phone != "" {
-		b.WriteString(fmt.Sprintf("TEL;TYPE=CELL:%s\r\n", v.Phone))
+	if w.Encryption != WiFiEAP {
+		if w.EAPMethod != "" || w.Phase2Method != "" || w.Identity != "" || w.AnonymousIdentity != "" {
+			return fmt.Errorf("EAP fields only apply to WPA2-EAP networks")
+		}
+		return nil
 	}
-	if v.Email != "" {
-		b.WriteString(fmt.Sprintf("EMAIL:%s\r\n", v.Email))
+	if !containsFold(WiFiEAPMethods(), w.EAPMethod) {
+		return fmt.Errorf("invalid EAP method %q (expected %s)", w.EAPMethod, strings.Join(WiFiEAPMethods(), ", "))
 	}
-	if v.URL != "" {
-		b.WriteString(fmt.Sprintf("URL:%s\r\n", v.URL))
+	if w.Phase2Method != "" {
+		if strings.EqualFold(w.EAPMethod, "PWD") {
+			return fmt.Errorf("EAP-PWD has no phase 2 method")
+		}
+		if !containsFold(WiFiPhase2Methods(), w.Phase2Method) {
+			return fmt.Errorf("invalid phase 2 method %q (expected %s)", w.Phase2Method, strings.Join(WiFiPhase2Methods(), ", "))
+		}
 	}
+	if w.Identity == "" {
+		return fmt.Errorf("identity is required for WPA2-EAP networks")
+	}
+	return nil
+}
 
-	b.WriteString("END:VCARD\r\n")
-
+// Encode generates the QR code content string for WiFi. Enterprise fields
+// use the E/PH2/A/I keys read by Android and ZXing; R:1 is the WPA3
+// transition disable flag.
+// Format: WIFI:T:<encryption>;[R:1;]S:<ssid>;[E:<eap>;PH2:<phase2>;A:<anon>;I:<identity>;]P:<password>;H:<hidden>;;
+func (w *WiFiData) Encode() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "WIFI:T:%s;", w.Encryption)
+	if w.TransitionDisable {
+		b.WriteString("R:1;")
+	}
+	fmt.Fprintf(&b, "S:%s;", escapeWiFiField(w.SSID))
+
+	if w.Encryption == WiFiEAP {
+		fmt.Fprintf(&b, "E:%s;", strings.ToUpper(w.EAPMethod))
+		if w.Phase2Method != "" {
+			fmt.Fprintf(&b, "PH2:%s;", strings.ToUpper(w.Phase2Method))
+		}
+		if w.AnonymousIdentity != "" {
+			fmt.Fprintf(&b, "A:%s;", escapeWiFiField(w.AnonymousIdentity))
+		}
+		fmt.Fprintf(&b, "I:%s;", escapeWiFiField(w.Identity))
+	}
+	if w.Encryption != WiFiNone {
+		fmt.Fprintf(&b, "P:%s;", escapeWiFiField(w.Password))
+	}
+	if w.Hidden {
+		b.WriteString("H:true;")
+	}
+	b.WriteString(";")
 	return b.String()
 }
 
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
 // EmailData holds email composition data.
 type EmailData struct {
 	Address string
@@ -169,6 +220,7 @@ func (s *SMSData) Encode() string {
 func escapeWiFiField(s string) string {
 	s = strings.ReplaceAll(s, `\`, `\\`)
 	s = strings.ReplaceAll(s, `;`, `\;`)
+	s = strings.ReplaceAll(s, `,`, `\,`)
 	s = strings.ReplaceAll(s, `:`, `\:`)
 	s = strings.ReplaceAll(s, `"`, `\"`)
 	return s
@@ -195,6 +247,8 @@ func WiFiEncryptionTypes() []struct {
 		Name string
 	}{
 		{WiFiWPA, "WPA/WPA2/WPA3"},
+		{WiFiSAE, "WPA3 only (SAE)"},
+		{WiFiEAP, "Enterprise (EAP)"},
 		{WiFiWEP, "WEP"},
 		{WiFiNone, "None (Open)"},
 	}
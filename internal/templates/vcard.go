@@ -0,0 +1,400 @@
+package templates
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VCardVersion selects the vCard specification a contact is encoded with.
+type VCardVersion string
+
+const (
+	VCard30 VCardVersion = "3.0" // RFC 2426; read by virtually every phone
+	VCard40 VCardVersion = "4.0" // RFC 6350
+)
+
+// ContactFormats returns the contact encodings: both vCard versions and
+// the more compact MeCard.
+func ContactFormats() []struct {
+	Name    string
+	MeCard  bool
+	Version VCardVersion
+} {
+	return []struct {
+		Name    string
+		MeCard  bool
+		Version VCardVersion
+	}{
+		{"vCard 3.0", false, VCard30},
+		{"vCard 4.0", false, VCard40},
+		{"MeCard", true, ""},
+	}
+}
+
+// VCardMaxPhotoBytes limits embedded photos: base64 grows them by a third
+// and a QR code holds at most 2953 bytes.
+const VCardMaxPhotoBytes = 1536
+
+// VCardData holds contact information. Phone and Email are the primary
+// mobile number and email address; Phones and Emails add more, each with
+// an optional type such as "work" or "home".
+type VCardData struct {
+	Version      VCardVersion // Empty means 3.0
+	FirstName    string
+	LastName     string
+	Phone        string
+	Email        string
+	Phones       []VCardPhone
+	Emails       []VCardEmail
+	Addresses    []VCardAddress
+	Organization string
+	Title        string
+	URL          string
+	Birthday     string // YYYY-MM-DD
+	Note         string
+	Photo        []byte // Small JPEG, PNG or GIF, at most VCardMaxPhotoBytes
+}
+
+// VCardPhone is a typed telephone number, e.g. {"work", "+41 44 668 18 00"}.
+type VCardPhone struct {
+	Type   string // cell, work, home, voice, fax, text... (optional)
+	Number string
+}
+
+// VCardEmail is a typed email address.
+type VCardEmail struct {
+	Type    string // work, home... (optional)
+	Address string
+}
+
+// VCardAddress is a postal address.
+type VCardAddress struct {
+	Type       string // work, home... (optional)
+	Street     string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// IsZero reports whether no field of the address is set.
+func (a VCardAddress) IsZero() bool {
+	return a == VCardAddress{}
+}
+
+// Validate checks the version, birthday and photo.
+func (v *VCardData) Validate() error {
+	if v.Version != "" && v.Version != VCard30 && v.Version != VCard40 {
+		return fmt.Errorf("invalid vCard version %q (expected 3.0 or 4.0)", v.Version)
+	}
+	if v.FirstName == "" && v.LastName == "" && v.Organization == "" {
+		return fmt.Errorf("a name or organization is required")
+	}
+	if v.Birthday != "" {
+		if _, err := time.Parse("2006-01-02", v.Birthday); err != nil {
+			return fmt.Errorf("invalid birthday %q (expected YYYY-MM-DD)", v.Birthday)
+		}
+	}
+	if len(v.Photo) > 0 {
+		if len(v.Photo) > VCardMaxPhotoBytes {
+			return fmt.Errorf("photo is %d bytes; at most %d fit in a QR code", len(v.Photo), VCardMaxPhotoBytes)
+		}
+		if photoMediaType(v.Photo) == "" {
+			return fmt.Errorf("photo must be a JPEG, PNG or GIF image")
+		}
+	}
+	return nil
+}
+
+// Encode generates the vCard, with text values escaped and lines folded at
+// 75 octets. Call Validate first: Encode does not check the fields.
+func (v *VCardData) Encode() string {
+	v4 := v.Version == VCard40
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(foldLine(s))
+	}
+
+	version := VCard30
+	if v4 {
+		version = VCard40
+	}
+	line("BEGIN:VCARD")
+	line("VERSION:" + string(version))
+
+	fullName := strings.TrimSpace(v.FirstName + " " + v.LastName)
+	if fullName != "" {
+		line("FN:" + escapeVCardText(fullName))
+		line(fmt.Sprintf("N:%s;%s;;;", escapeVCardText(v.LastName), escapeVCardText(v.FirstName)))
+	} else if v.Organization != "" {
+		line("FN:" + escapeVCardText(v.Organization)) // FN is required
+	}
+	if v.Organization != "" {
+		line("ORG:" + escapeVCardText(v.Organization))
+	}
+	if v.Title != "" {
+		line("TITLE:" + escapeVCardText(v.Title))
+	}
+
+	phones := v.Phones
+	if v.Phone != "" {
+		phones = append([]VCardPhone{{Type: "cell", Number: v.Phone}}, phones...)
+	}
+	for _, p := range phones {
+		if p.Number == "" {
+			continue
+		}
+		if v4 {
+			// Format: TEL;VALUE=uri;TYPE=cell:tel:+1-555-0100
+			line("TEL;VALUE=uri" + vcardType(p.Type, v4) + ":tel:" + telURIReplacer.Replace(p.Number))
+		} else {
+			line("TEL" + vcardType(p.Type, v4) + ":" + p.Number)
+		}
+	}
+
+	emails := v.Emails
+	if v.Email != "" {
+		emails = append([]VCardEmail{{Address: v.Email}}, emails...)
+	}
+	for _, e := range emails {
+		if e.Address != "" {
+			line("EMAIL" + vcardType(e.Type, v4) + ":" + e.Address)
+		}
+	}
+
+	for _, a := range v.Addresses {
+		if a.IsZero() {
+			continue
+		}
+		// Components: PO box; extended address; street; locality; region; postal code; country
+		line(fmt.Sprintf("ADR%s:;;%s;%s;%s;%s;%s", vcardType(a.Type, v4),
+			escapeVCardText(a.Street), escapeVCardText(a.City), escapeVCardText(a.Region),
+			escapeVCardText(a.PostalCode), escapeVCardText(a.Country)))
+	}
+
+	if v.URL != "" {
+		line("URL:" + v.URL)
+	}
+	if v.Birthday != "" {
+		if v4 {
+			line("BDAY:" + strings.ReplaceAll(v.Birthday, "-", ""))
+		} else {
+			line("BDAY:" + v.Birthday)
+		}
+	}
+	if v.Note != "" {
+		line("NOTE:" + escapeVCardText(v.Note))
+	}
+	if len(v.Photo) > 0 {
+		data := base64.StdEncoding.EncodeToString(v.Photo)
+		mediaType := photoMediaType(v.Photo)
+		if v4 {
+			line("PHOTO:data:" + mediaType + ";base64," + data)
+		} else {
+			line("PHOTO;ENCODING=b;TYPE=" + strings.ToUpper(strings.TrimPrefix(mediaType, "image/")) + ":" + data)
+		}
+	}
+
+	line("END:VCARD")
+	return b.String()
+}
+
+// EncodeMeCard generates the contact in the MeCard format, which is far
+// more compact than a vCard and read by most phone cameras. MeCard has no
+// job title or photo, so those are left out, and only the first address
+// is kept.
+// Format: MECARD:N:<last>,<first>;TEL:<phone>;EMAIL:<email>;...;;
+func (v *VCardData) EncodeMeCard() string {
+	var b strings.Builder
+	b.WriteString("MECARD:")
+	prop := func(name, value string) {
+		b.WriteString(name + ":" + value + ";")
+	}
+
+	switch {
+	case v.LastName != "" && v.FirstName != "":
+		prop("N", escapeMeCardField(v.LastName)+","+escapeMeCardField(v.FirstName))
+	case v.FirstName != "" || v.LastName != "":
+		prop("N", escapeMeCardField(v.FirstName+v.LastName))
+	default:
+		prop("N", escapeMeCardField(v.Organization)) // N is required
+	}
+	if v.Organization != "" {
+		prop("ORG", escapeMeCardField(v.Organization))
+	}
+	if v.Phone != "" {
+		prop("TEL", escapeMeCardField(v.Phone))
+	}
+	for _, p := range v.Phones {
+		if p.Number != "" {
+			prop("TEL", escapeMeCardField(p.Number))
+		}
+	}
+	if v.Email != "" {
+		prop("EMAIL", escapeMeCardField(v.Email))
+	}
+	for _, e := range v.Emails {
+		if e.Address != "" {
+			prop("EMAIL", escapeMeCardField(e.Address))
+		}
+	}
+	for _, a := range v.Addresses {
+		if a.IsZero() {
+			continue
+		}
+		// Components: PO box, extended address, street, locality, region, postal code, country
+		prop("ADR", strings.Join([]string{"", "",
+			escapeMeCardField(a.Street), escapeMeCardField(a.City), escapeMeCardField(a.Region),
+			escapeMeCardField(a.PostalCode), escapeMeCardField(a.Country)}, ","))
+		break
+	}
+	if v.URL != "" {
+		prop("URL", escapeMeCardField(v.URL))
+	}
+	if v.Birthday != "" {
+		prop("BDAY", strings.ReplaceAll(v.Birthday, "-", ""))
+	}
+	if v.Note != "" {
+		prop("NOTE", escapeMeCardField(v.Note))
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+// escapeMeCardField escapes the characters that delimit MeCard values.
+func escapeMeCardField(s string) string {
+	return meCardEscaper.Replace(s)
+}
+
+var meCardEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, ":", `\:`, "\r\n", " ", "\n", " ")
+
+// escapeVCardText escapes TEXT values, which follow the same rules as
+// iCalendar (RFC 6350, section 3.4).
+func escapeVCardText(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+// telURIReplacer turns a phone number into a tel: URI number, which has no
+// spaces or parentheses.
+var telURIReplacer = strings.NewReplacer(" ", "-", "(", "", ")", "")
+
+// vcardType returns the TYPE parameter for a property, upper case in vCard
+// 3.0 and lower case in 4.0, or "" when typ is empty.
+func vcardType(typ string, v4 bool) string {
+	if typ == "" {
+		return ""
+	}
+	if v4 {
+		return ";TYPE=" + strings.ToLower(typ)
+	}
+	return ";TYPE=" + strings.ToUpper(typ)
+}
+
+// photoMediaType returns the media type of a JPEG, PNG or GIF image, or ""
+// for anything else.
+func photoMediaType(data []byte) string {
+	switch t := http.DetectContentType(data); t {
+	case "image/jpeg", "image/png", "image/gif":
+		return t
+	}
+	return ""
+}
+
+// PhotoDataURI encodes an image as a data: URI, the form the "photo" field
+// of FromFields accepts.
+func PhotoDataURI(data []byte) string {
+	return "data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// decodePhoto reads a photo given as a data: URI or plain base64.
+func decodePhoto(s string) ([]byte, error) {
+	if strings.HasPrefix(s, "data:") {
+		_, data, ok := strings.Cut(s, ";base64,")
+		if !ok {
+			return nil, fmt.Errorf("photo data URI must be base64-encoded")
+		}
+		s = data
+	}
+	photo, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("photo must be a data: URI or base64")
+	}
+	return photo, nil
+}
+
+// parseVCardVersion accepts 3.0 and 4.0 (or 3 and 4). An empty value
+// defaults to 3.0.
+func parseVCardVersion(s string) (VCardVersion, error) {
+	switch s {
+	case "", "3", "3.0":
+		return VCard30, nil
+	case "4", "4.0":
+		return VCard40, nil
+	}
+	return "", fmt.Errorf("invalid vCard version %q (expected 3.0 or 4.0)", s)
+}
+
+// parseContactFormat accepts vcard and mecard and reports whether the
+// contact is a MeCard. An empty value defaults to vcard.
+func parseContactFormat(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "", "vcard":
+		return false, nil
+	case "mecard":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid contact format %q (expected vcard or mecard)", s)
+}
+
+// vcardFromFields builds a contact from form or manifest fields. "phone"
+// and "email" are the primary number and address; "phone_work",
+// "phone_home", "email_work" and "email_home" add typed ones, and the
+// "address_" fields describe one postal address. "version" is 3.0 or 4.0
+// and "photo" a data: URI or base64 image.
+func vcardFromFields(get func(string) string) (*VCardData, error) {
+	v := &VCardData{
+		FirstName:    get("first_name"),
+		LastName:     get("last_name"),
+		Phone:        get("phone"),
+		Email:        get("email"),
+		Organization: get("organization"),
+		Title:        get("title"),
+		URL:          get("url"),
+		Birthday:     get("birthday"),
+		Note:         get("note"),
+	}
+	if v.FirstName == "" && v.LastName == "" {
+		return nil, fmt.Errorf("field 'first_name' or 'last_name' is required")
+	}
+	var err error
+	if v.Version, err = parseVCardVersion(get("version")); err != nil {
+		return nil, err
+	}
+	for _, typ := range []string{"work", "home"} {
+		if n := get("phone_" + typ); n != "" {
+			v.Phones = append(v.Phones, VCardPhone{Type: typ, Number: n})
+		}
+		if e := get("email_" + typ); e != "" {
+			v.Emails = append(v.Emails, VCardEmail{Type: typ, Address: e})
+		}
+	}
+	if a := (VCardAddress{
+		Type:       get("address_type"),
+		Street:     get("address_street"),
+		City:       get("address_city"),
+		Region:     get("address_region"),
+		PostalCode: get("address_postal_code"),
+		Country:    get("address_country"),
+	}); !a.IsZero() {
+		v.Addresses = append(v.Addresses, a)
+	}
+	if photo := get("photo"); photo != "" {
+		if v.Photo, err = decodePhoto(photo); err != nil {
+			return nil, err
+		}
+	}
+	return v, v.Validate()
+}
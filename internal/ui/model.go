@@ -2,14 +2,17 @@ package ui
 
 import (
 	"fmt"
+	"image/color"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/DalyChouikh/internal/config"
 	"github.com/DalyChouikh/internal/generator"
 	"github.com/DalyChouikh/internal/history"
+	"github.com/DalyChouikh/internal/presets"
 	"github.com/DalyChouikh/internal/templates"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,7 +23,8 @@ import (
 type Step int
 
 const (
-	StepContentType Step = iota
+	StepPreset Step = iota
+	StepContentType
 	StepURL
 	StepTemplate
 	StepFormat
@@ -39,6 +43,17 @@ type Model struct {
 	styles *Styles
 	config *config.QRConfig
 
+	// User defaults from the config file
+	defaultSize      int
+	defaultOutputDir string // Empty means the current directory
+	defaultFilename  string
+
+	// Style presets; preset is nil when none was picked
+	baseConfig config.QRConfig
+	presets    []presets.Preset
+	presetIdx  int // 0 = no preset, i+1 = presets[i]
+	preset     *presets.Preset
+
 	// Current step
 	step Step
 
@@ -49,9 +64,10 @@ type Model struct {
 	colorInput  textinput.Model
 
 	// Selection states
-	formatIndex int // 0 = PNG, 1 = SVG
-	colorIndex  int // Index in predefined colors, -1 for custom
-	colorNames  []string
+	formatIndex    int    // Cursor in formatOptions
+	formatSelected []bool // Formats ticked for this generation
+	colorIndex     int    // Index in predefined colors, -1 for custom
+	colorNames     []string
 
 	// Content type selection
 	contentTypes   []templates.ContentTypeInfo
@@ -66,6 +82,9 @@ type Model struct {
 	fileBrowserActive bool
 	filePicker        FilePicker
 
+	// Set while asking whether to replace an existing output file
+	confirmOverwrite bool
+
 	// QR terminal preview
 	qrPreview string
 
@@ -82,7 +101,7 @@ type Model struct {
 // New creates a new Model with default values.
 func New() Model {
 	styles := NewStyles()
-	cfg := config.DefaultConfig()
+	cfg, settings, cfgErr := config.LoadUserConfig()
 
 	// URL input
 	urlInput := textinput.New()
@@ -92,13 +111,13 @@ func New() Model {
 
 	// Size input
 	sizeInput := textinput.New()
-	sizeInput.Placeholder = "256"
-	sizeInput.CharLimit = 4
+	sizeInput.Placeholder = strconv.Itoa(cfg.Size)
+	sizeInput.CharLimit = 32
 	sizeInput.Width = 46
 
 	// Output input
 	outputInput := textinput.New()
-	outputInput.Placeholder = "qrcode"
+	outputInput.Placeholder = settings.DefaultFilename()
 	outputInput.CharLimit = 256
 	outputInput.Width = 46
 
@@ -114,30 +133,100 @@ func New() Model {
 	bgColorInput.CharLimit = 7
 	bgColorInput.Width = 46
 
-	// Get home directory for default output path
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "."
+	// Prefill custom colors from the config file so 'c' offers them
+	if predefinedColorName(cfg.Foreground) == "" {
+		colorInput.SetValue(config.ColorToHex(cfg.Foreground))
+	}
+	if predefinedColorName(cfg.Background) == "" {
+		bgColorInput.SetValue(config.ColorToHex(cfg.Background))
+	}
+
+	colorNames := config.GetPredefinedColorNames()
+	formatIndex := 0
+	for i, f := range formatOptions {
+		if cfg.Format == f {
+			formatIndex = i
+		}
+	}
+	formatSelected := selectedFormats(cfg)
+
+	// Presets are optional: a broken presets file only hides the picker
+	var presetList []presets.Preset
+	if store, err := presets.NewStore(); err == nil {
+		presetList = store.List()
+	} else if cfgErr == nil {
+		cfgErr = err
+	}
+	step := StepContentType
+	if len(presetList) > 0 {
+		step = StepPreset
 	}
-	cfg.OutputPath = filepath.Join(homeDir, "qrcode.png")
 
 	return Model{
-		styles:         styles,
-		config:         cfg,
-		step:           StepContentType,
-		urlInput:       urlInput,
-		sizeInput:      sizeInput,
-		outputInput:    outputInput,
-		colorInput:     colorInput,
-		formatIndex:    0,
-		colorIndex:     0,
-		colorNames:     config.GetPredefinedColorNames(),
-		contentTypes:   templates.AvailableTypes(),
-		contentTypeIdx: 0,
-		bgColorIndex:   0,
-		bgColorInput:   bgColorInput,
-		filePicker:     NewFilePicker(),
+		styles:           styles,
+		config:           cfg,
+		baseConfig:       *cfg,
+		presets:          presetList,
+		defaultSize:      cfg.Size,
+		defaultOutputDir: config.ExpandHome(settings.OutputDir),
+		defaultFilename:  settings.DefaultFilename(),
+		step:             step,
+		urlInput:         urlInput,
+		sizeInput:        sizeInput,
+		outputInput:      outputInput,
+		colorInput:       colorInput,
+		formatIndex:      formatIndex,
+		formatSelected:   formatSelected,
+		colorIndex:       predefinedColorIndex(colorNames, cfg.Foreground),
+		colorNames:       colorNames,
+		contentTypes:     templates.AvailableTypes(),
+		contentTypeIdx:   0,
+		bgColorIndex:     predefinedColorIndex(colorNames, cfg.Background),
+		bgColorInput:     bgColorInput,
+		filePicker:       NewFilePicker(),
+		err:              cfgErr,
+	}
+}
+
+// formatOptions are the formats offered by the format step.
+var formatOptions = []config.OutputFormat{config.FormatPNG, config.FormatJPEG, config.FormatSVG}
+
+// selectedFormats returns which formatOptions are part of cfg.
+func selectedFormats(cfg *config.QRConfig) []bool {
+	formats := cfg.Formats
+	if len(formats) == 0 {
+		formats = []config.OutputFormat{cfg.Format}
+	}
+	selected := make([]bool, len(formatOptions))
+	for i, option := range formatOptions {
+		for _, f := range formats {
+			if f == option {
+				selected[i] = true
+			}
+		}
+	}
+	return selected
+}
+
+// predefinedColorIndex returns the index of c in the predefined palette, or
+// 0 if it is not a predefined color.
+func predefinedColorIndex(names []string, c color.RGBA) int {
+	for i, name := range names {
+		if config.PredefinedColors[name] == c {
+			return i
+		}
 	}
+	return 0
+}
+
+// predefinedColorName returns the palette name of c, or "" if c is custom.
+func predefinedColorName(c color.RGBA) string {
+	for name, pc := range config.PredefinedColors {
+		if pc == c {
+			return name
+		}
+	}
+	return ""
 }
 
 // Init initializes the model.
@@ -168,7 +257,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 		case "esc":
-			if m.step > StepContentType && m.step < StepComplete {
+			if m.step > StepPreset && m.step < StepComplete {
 				// Let step handlers manage esc in sub-modes
 				if m.step == StepColor && m.colorIndex == -1 {
 					break
@@ -176,7 +265,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.step == StepBgColor && m.bgColorIndex == -1 {
 					break
 				}
-				if m.step == StepOutput && m.fileBrowserActive {
+				if m.step == StepOutput && (m.fileBrowserActive || m.confirmOverwrite) {
 					break
 				}
 				m.step = m.previousStep()
@@ -187,6 +276,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Step-specific handlers
 		switch m.step {
+		case StepPreset:
+			return m.handlePresetStep(msg)
 		case StepContentType:
 			return m.handleContentTypeStep(msg)
 		case StepURL:
@@ -253,9 +344,9 @@ func (m Model) handleURLStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.config.Content = url
 		m.err = nil
-		m.step = StepFormat
 		m.urlInput.Blur()
-		return m, nil
+		cmd := m.advanceTo(StepFormat)
+		return m, cmd
 	}
 
 	var cmd tea.Cmd
@@ -270,23 +361,29 @@ func (m Model) handleFormatStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.formatIndex--
 		}
 	case "right", "l":
-		if m.formatIndex < 1 {
+		if m.formatIndex < len(formatOptions)-1 {
 			m.formatIndex++
 		}
-	case "enter", " ":
-		if m.formatIndex == 0 {
-			m.config.Format = config.FormatPNG
-		} else {
-			m.config.Format = config.FormatSVG
+	case " ", "x":
+		m.formatSelected[m.formatIndex] = !m.formatSelected[m.formatIndex]
+	case "1", "2", "3":
+		m.formatIndex = int(msg.String()[0] - '1')
+		m.formatSelected[m.formatIndex] = !m.formatSelected[m.formatIndex]
+	case "enter":
+		var formats []config.OutputFormat
+		for i, selected := range m.formatSelected {
+			if selected {
+				formats = append(formats, formatOptions[i])
+			}
 		}
+		if len(formats) == 0 {
+			formats = []config.OutputFormat{formatOptions[m.formatIndex]}
+			m.formatSelected[m.formatIndex] = true
+		}
+		m.config.SetFormats(formats)
 		m.err = nil
-		m.step = StepColor
-	case "1":
-		m.formatIndex = 0
-		m.config.Format = config.FormatPNG
-	case "2":
-		m.formatIndex = 1
-		m.config.Format = config.FormatSVG
+		cmd := m.advanceTo(StepColor)
+		return m, cmd
 	}
 	return m, nil
 }
@@ -308,9 +405,9 @@ func (m Model) handleColorStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			m.config.Foreground = color
 			m.err = nil
-			m.step = StepBgColor
 			m.colorInput.Blur()
-			return m, nil
+			cmd := m.advanceTo(StepBgColor)
+			return m, cmd
 		case "esc":
 			m.colorIndex = 0
 			m.colorInput.Blur()
@@ -340,8 +437,8 @@ func (m Model) handleColorStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		colorName := m.colorNames[m.colorIndex]
 		m.config.Foreground = config.PredefinedColors[colorName]
 		m.err = nil
-		m.step = StepBgColor
-		return m, nil
+		cmd := m.advanceTo(StepBgColor)
+		return m, cmd
 	}
 	return m, nil
 }
@@ -349,21 +446,25 @@ func (m Model) handleColorStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleSizeStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
-		sizeStr := strings.TrimSpace(m.sizeInput.Value())
-		if sizeStr == "" {
-			m.config.Size = 256 // Default size
-		} else {
-			size, err := strconv.Atoi(sizeStr)
-			if err != nil || size < 64 || size > 4096 {
+		sizes, err := config.ParseSizes(m.sizeInput.Value())
+		if err != nil {
+			m.err = fmt.Errorf("size must be a number between 64 and 4096")
+			return m, nil
+		}
+		if len(sizes) == 0 {
+			sizes = []int{m.defaultSize}
+		}
+		for _, size := range sizes {
+			if size < 64 || size > 4096 {
 				m.err = fmt.Errorf("size must be a number between 64 and 4096")
 				return m, nil
 			}
-			m.config.Size = size
 		}
+		m.config.SetSizes(sizes)
 		m.err = nil
-		m.step = StepOutput
 		m.sizeInput.Blur()
-		return m, m.outputInput.Focus()
+		cmd := m.advanceTo(StepOutput)
+		return m, cmd
 	}
 
 	var cmd tea.Cmd
@@ -372,6 +473,9 @@ func (m Model) handleSizeStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleOutputStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmOverwrite {
+		return m.handleOverwriteConfirm(msg)
+	}
 	if m.fileBrowserActive {
 		return m.handleOutputFileBrowser(msg)
 	}
@@ -386,7 +490,7 @@ func (m Model) handleOutputStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter":
 		output := strings.TrimSpace(m.outputInput.Value())
 		if output == "" {
-			output = "qrcode"
+			output = filepath.Join(m.defaultOutputDir, m.defaultFilename)
 		}
 
 		// Expand ~ to home directory
@@ -405,11 +509,9 @@ func (m Model) handleOutputStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		m.config.SetOutputPath(output)
-		m.err = nil
-		m.step = StepConfirm
 		m.outputInput.Blur()
-		return m, nil
+		cmd := m.chooseOutput(output)
+		return m, cmd
 	}
 
 	var cmd tea.Cmd
@@ -441,17 +543,59 @@ func (m Model) handleOutputFileBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Check if the file picker has confirmed a selection
 	if m.filePicker.IsConfirmed() {
 		path := m.filePicker.SelectedPath()
-		m.config.SetOutputPath(path)
-		m.err = nil
-		m.step = StepConfirm
-		m.fileBrowserActive = false
 		m.filePicker.Reset()
-		return m, nil
+		cmd := m.chooseOutput(path)
+		return m, cmd
 	}
 
 	return m, cmd
 }
 
+// chooseOutput expands the output pattern and applies the collision
+// policy before moving on to the review step: an existing file is either
+// confirmed with the user, replaced by the next free name, or rejected.
+func (m *Model) chooseOutput(pattern string) tea.Cmd {
+	ct := m.contentTypes[m.contentTypeIdx].Type
+	m.config.OnCollision = m.baseConfig.OnCollision
+	m.config.SetOutputPattern(pattern, config.FilenameVars{
+		Content: templates.RedactSecrets(m.config.Content), // Keep secrets out of {slug} filenames
+		Type:    templates.TypeName(ct),
+		Time:    time.Now(),
+	})
+	m.err = nil
+
+	if m.config.OnCollision == config.CollisionPrompt {
+		if m.config.ExistingOutput() != "" {
+			m.confirmOverwrite = true
+			return nil
+		}
+	} else if err := m.config.ResolveCollisions(); err != nil {
+		m.err = err
+		return m.focusStep()
+	}
+
+	m.fileBrowserActive = false
+	m.step = StepConfirm
+	return nil
+}
+
+// handleOverwriteConfirm answers the "file exists" question in the output
+// step. Declining returns to the input or file browser that chose the path.
+func (m Model) handleOverwriteConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.confirmOverwrite = false
+		m.config.OnCollision = config.CollisionOverwrite
+		m.fileBrowserActive = false
+		m.step = StepConfirm
+		return m, nil
+	case "n", "N", "esc", "enter":
+		m.confirmOverwrite = false
+		return m, m.focusStep()
+	}
+	return m, nil
+}
+
 func (m Model) handleConfirmStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter", "y", "Y":
@@ -461,18 +605,11 @@ func (m Model) handleConfirmStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.err = err
 			return m, nil
 		}
-		m.successPath = m.config.OutputPath
+		m.successPath = strings.Join(gen.Paths(), "\n")
 
 		// Save to history
 		if store, err := history.NewStore(); err == nil {
-			_ = store.Add(history.Entry{
-				Content:    m.config.Content,
-				Format:     string(m.config.Format),
-				Size:       m.config.Size,
-				FgColor:    config.ColorToHex(m.config.Foreground),
-				BgColor:    config.ColorToHex(m.config.Background),
-				OutputPath: m.config.OutputPath,
-			})
+			_ = store.Add(history.NewEntry(m.config, gen.Paths()))
 		}
 
 		// Generate terminal preview for scanning
@@ -516,12 +653,13 @@ func (m Model) handleContentTypeStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter", " ":
 		ct := m.contentTypes[m.contentTypeIdx].Type
 		m.err = nil
+		m.config.SwissCross = false
 		switch ct {
 		case templates.ContentURL, templates.ContentText:
 			m.step = StepURL
 			return m, m.urlInput.Focus()
 		default:
-			// WiFi, vCard, Email, SMS → template wizard
+			// WiFi, vCard, Email, SMS, Event, ... → template wizard
 			tw := NewTemplateWizard(ct)
 			m.templateWizard = &tw
 			m.step = StepTemplate
@@ -537,12 +675,19 @@ func (m Model) handleTemplateStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 
 	cmd := m.templateWizard.Update(msg)
+	m.err = m.templateWizard.Err()
 
 	if m.templateWizard.IsConfirmed() {
 		m.config.Content = m.templateWizard.Result()
-		m.step = StepFormat
+		// The TUI has no error correction step, so a mandated level
+		// always wins over the configured default.
+		ct := templates.DetectType(m.config.Content)
+		required := templates.RequiredErrorCorrection(ct)
+		_ = m.config.RequireLevel(config.ErrorCorrection(required), false)
+		m.config.SwissCross = templates.RequiresSwissCross(ct)
 		m.err = nil
-		return m, nil
+		cmd := m.advanceTo(StepFormat)
+		return m, cmd
 	}
 
 	return m, cmd
@@ -565,9 +710,9 @@ func (m Model) handleBgColorStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			m.config.Background = bgColor
 			m.err = nil
-			m.step = StepSize
 			m.bgColorInput.Blur()
-			return m, m.sizeInput.Focus()
+			cmd := m.advanceTo(StepSize)
+			return m, cmd
 		case "esc":
 			m.bgColorIndex = 0
 			m.bgColorInput.Blur()
@@ -596,15 +741,83 @@ func (m Model) handleBgColorStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		colorName := m.colorNames[m.bgColorIndex]
 		m.config.Background = config.PredefinedColors[colorName]
 		m.err = nil
-		m.step = StepSize
-		return m, m.sizeInput.Focus()
+		cmd := m.advanceTo(StepSize)
+		return m, cmd
 	}
 	return m, nil
 }
 
-// previousStep returns the step to go back to.
+// handlePresetStep lets the user start from a saved style preset.
+func (m Model) handlePresetStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.presetIdx > 0 {
+			m.presetIdx--
+		}
+	case "down", "j":
+		if m.presetIdx < len(m.presets) {
+			m.presetIdx++
+		}
+	case "enter", " ":
+		// Start from the user defaults so switching presets doesn't mix them
+		*m.config = m.baseConfig
+		m.preset = nil
+		if m.presetIdx > 0 {
+			p := m.presets[m.presetIdx-1]
+			p.Apply(m.config)
+			m.preset = &p
+		}
+		m.err = nil
+		m.step = StepContentType
+	}
+	return m, nil
+}
+
+// decided reports whether the chosen preset already answers a step.
+func (m Model) decided(step Step) bool {
+	if m.preset == nil {
+		return false
+	}
+	switch step {
+	case StepFormat:
+		return m.preset.Format != ""
+	case StepColor:
+		return m.preset.Foreground != ""
+	case StepBgColor:
+		return m.preset.Background != ""
+	case StepSize:
+		return m.preset.Size != 0
+	}
+	return false
+}
+
+// advanceTo moves to step, skipping any steps decided by the preset, and
+// focuses the new step's input.
+func (m *Model) advanceTo(step Step) tea.Cmd {
+	for m.decided(step) {
+		step++
+	}
+	m.step = step
+	return m.focusStep()
+}
+
+// previousStep returns the step to go back to, skipping steps decided by
+// the preset.
 func (m Model) previousStep() Step {
-	switch m.step {
+	step := m.previousStepFrom(m.step)
+	for m.decided(step) {
+		step = m.previousStepFrom(step)
+	}
+	return step
+}
+
+func (m Model) previousStepFrom(step Step) Step {
+	switch step {
+	case StepContentType:
+		if len(m.presets) > 0 {
+			return StepPreset
+		}
+		return step
 	case StepURL, StepTemplate:
 		return StepContentType
 	case StepFormat:
@@ -624,7 +837,7 @@ func (m Model) previousStep() Step {
 	case StepConfirm:
 		return StepOutput
 	default:
-		return m.step
+		return step
 	}
 }
 
@@ -703,13 +916,15 @@ func (m Model) View() string {
 	s.WriteString("\n\n")
 
 	// Progress bar
-	if m.step < StepComplete {
+	if m.step > StepPreset && m.step < StepComplete {
 		s.WriteString(m.styles.RenderProgressBar(m.stepDisplayNumber(), totalVisibleSteps))
 		s.WriteString("\n\n")
 	}
 
 	// Current step content
 	switch m.step {
+	case StepPreset:
+		s.WriteString(m.renderPresetStep())
 	case StepContentType:
 		s.WriteString(m.renderContentTypeStep())
 	case StepURL:
@@ -745,6 +960,41 @@ func (m Model) View() string {
 	return m.styles.App.Render(s.String())
 }
 
+func (m Model) renderPresetStep() string {
+	var s strings.Builder
+
+	s.WriteString(m.styles.Header.Render("Start from a preset?"))
+	s.WriteString("\n\n")
+
+	names := []string{"No preset"}
+	descs := []string{"Choose every option yourself"}
+	for _, p := range m.presets {
+		names = append(names, p.Name)
+		descs = append(descs, p.Summary())
+	}
+
+	for i := range names {
+		var line string
+		if i == m.presetIdx {
+			cursor := m.styles.OptionActive.Render("▸")
+			name := m.styles.OptionActive.Render(names[i])
+			desc := lipgloss.NewStyle().Foreground(primaryColor).Italic(true).Render(" — " + descs[i])
+			line = fmt.Sprintf("%s %s%s", cursor, name, desc)
+		} else {
+			cursor := m.styles.Option.Render(" ")
+			name := m.styles.Option.Render(names[i])
+			desc := lipgloss.NewStyle().Foreground(subtleColor).Italic(true).Render(" — " + descs[i])
+			line = fmt.Sprintf("%s %s%s", cursor, name, desc)
+		}
+		s.WriteString(line + "\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(m.styles.Label.Render("Steps set by the preset are skipped"))
+
+	return s.String()
+}
+
 func (m Model) renderContentTypeStep() string {
 	var s strings.Builder
 
@@ -847,23 +1097,31 @@ func (m Model) renderFormatStep() string {
 	s.WriteString(m.styles.Header.Render("Step 3: Choose Output Format"))
 	s.WriteString("\n\n")
 
-	pngStyle := m.styles.Button
-	svgStyle := m.styles.Button
-
-	if m.formatIndex == 0 {
-		pngStyle = m.styles.ButtonActive
-	} else {
-		svgStyle = m.styles.ButtonActive
+	buttons := []string{"  "}
+	for i, f := range formatOptions {
+		style := m.styles.Button
+		if i == m.formatIndex {
+			style = m.styles.ButtonActive
+		}
+		box := "[ ]"
+		if m.formatSelected[i] {
+			box = "[x]"
+		}
+		if i > 0 {
+			buttons = append(buttons, "    ")
+		}
+		buttons = append(buttons, style.Render(box+" "+strings.ToUpper(string(f))))
 	}
-
-	pngBtn := pngStyle.Render("PNG")
-	svgBtn := svgStyle.Render("SVG")
-	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, "  ", pngBtn, "    ", svgBtn))
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, buttons...))
 
 	s.WriteString("\n\n")
 	s.WriteString(m.styles.Label.Render("PNG: Raster image, best for most uses"))
 	s.WriteString("\n")
+	s.WriteString(m.styles.Label.Render("JPG: Raster image for tools that only accept JPEG"))
+	s.WriteString("\n")
 	s.WriteString(m.styles.Label.Render("SVG: Vector format, scales infinitely"))
+	s.WriteString("\n\n")
+	s.WriteString(m.styles.Label.Render("Tick several formats to write them all from one generation"))
 
 	return s.String()
 }
@@ -919,7 +1177,9 @@ func (m Model) renderSizeStep() string {
 	s.WriteString(label + "\n")
 	s.WriteString(m.styles.FocusedInput.Render(m.sizeInput.View()))
 	s.WriteString("\n\n")
-	s.WriteString(m.styles.Label.Render("Leave empty for default (256px)"))
+	s.WriteString(m.styles.Label.Render(fmt.Sprintf("Leave empty for default (%dpx)", m.defaultSize)))
+	s.WriteString("\n")
+	s.WriteString(m.styles.Label.Render("Separate several PNG sizes with commas, e.g. 256, 1024"))
 
 	return s.String()
 }
@@ -930,16 +1190,24 @@ func (m Model) renderOutputStep() string {
 	s.WriteString(m.styles.Header.Render("Step 7: Output Location"))
 	s.WriteString("\n\n")
 
-	if m.fileBrowserActive {
+	if m.confirmOverwrite {
+		s.WriteString(m.styles.Error.Render("⚠ " + m.config.ExistingOutput() + " already exists"))
+		s.WriteString("\n\n")
+		confirmText := lipgloss.NewStyle().Bold(true).Foreground(primaryColor).Render("Overwrite it? ")
+		s.WriteString(confirmText)
+		s.WriteString(m.styles.Label.Render("[Y] Yes  [N/Esc] Choose another name"))
+	} else if m.fileBrowserActive {
 		s.WriteString(m.filePicker.View(m.styles))
 	} else {
 		label := m.styles.LabelFocused.Render("Filename or path:")
 		s.WriteString(label + "\n")
 		s.WriteString(m.styles.FocusedInput.Render(m.outputInput.View()))
 		s.WriteString("\n\n")
-		s.WriteString(m.styles.Label.Render("Leave empty for 'qrcode' in current directory"))
+		s.WriteString(m.styles.Label.Render(m.defaultOutputHint()))
 		s.WriteString("\n")
 		s.WriteString(m.styles.Label.Render("Use ~ for home directory, e.g., ~/Downloads/myqr"))
+		s.WriteString("\n")
+		s.WriteString(m.styles.Label.Render("Placeholders: {date} {type} {slug} {hash} {n}"))
 		s.WriteString("\n\n")
 		s.WriteString(m.styles.Label.Render("Press Tab to browse files"))
 	}
@@ -947,6 +1215,14 @@ func (m Model) renderOutputStep() string {
 	return s.String()
 }
 
+// defaultOutputHint describes where an empty output path will be saved.
+func (m Model) defaultOutputHint() string {
+	if m.defaultOutputDir == "" {
+		return fmt.Sprintf("Leave empty for '%s' in current directory", m.defaultFilename)
+	}
+	return fmt.Sprintf("Leave empty for '%s' in %s", m.defaultFilename, m.defaultOutputDir)
+}
+
 func (m Model) renderConfirmStep() string {
 	var s strings.Builder
 
@@ -970,9 +1246,19 @@ func (m Model) renderPreview() string {
 
 	// Show content type
 	ct := m.contentTypes[m.contentTypeIdx]
+	if m.preset != nil {
+		lines = append(lines, fmt.Sprintf("🏷️  Preset:   %s", m.preset.Name))
+	}
 	lines = append(lines, fmt.Sprintf("📋 Type:     %s %s", ct.Icon, ct.Name))
 	lines = append(lines, fmt.Sprintf("📝 Content:  %s", truncateString(m.config.Content, 40)))
-	lines = append(lines, fmt.Sprintf("📄 Format:   %s", strings.ToUpper(string(m.config.Format))))
+	formats := []string{strings.ToUpper(string(m.config.Format))}
+	if len(m.config.Formats) > 0 {
+		formats = formats[:0]
+		for _, f := range m.config.Formats {
+			formats = append(formats, strings.ToUpper(string(f)))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("📄 Format:   %s", strings.Join(formats, " + ")))
 
 	fgName := "Custom"
 	for name, c := range config.PredefinedColors {
@@ -992,8 +1278,26 @@ func (m Model) renderPreview() string {
 	}
 	lines = append(lines, fmt.Sprintf("🖼️  BG Color: %s (%s)", bgName, config.ColorToHex(m.config.Background)))
 
-	lines = append(lines, fmt.Sprintf("📐 Size:     %dx%d pixels", m.config.Size, m.config.Size))
-	lines = append(lines, fmt.Sprintf("💾 Output:   %s", truncateString(m.config.OutputPath, 40)))
+	if len(m.config.Sizes) > 0 {
+		sizes := make([]string, len(m.config.Sizes))
+		for i, size := range m.config.Sizes {
+			sizes[i] = strconv.Itoa(size)
+		}
+		lines = append(lines, fmt.Sprintf("📐 Sizes:    %s pixels", strings.Join(sizes, ", ")))
+	} else {
+		lines = append(lines, fmt.Sprintf("📐 Size:     %dx%d pixels", m.config.Size, m.config.Size))
+	}
+	if m.config.DPI != 0 {
+		lines = append(lines, fmt.Sprintf("🖨️  Print:    %s at %d DPI",
+			config.FormatLength(m.config.PrintSize()), m.config.DPI))
+	}
+	for i, path := range m.config.OutputPaths() {
+		label := "💾 Output:  "
+		if i > 0 {
+			label = "           "
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", label, truncateString(path, 40)))
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -1004,6 +1308,13 @@ func (m Model) renderCompleteStep() string {
 	successBox := m.styles.Success.Render(fmt.Sprintf("✓ QR code generated successfully!\n\nSaved to:\n%s", m.successPath))
 	s.WriteString(successBox)
 
+	// Authenticator secrets are redacted from history, so this is the
+	// last chance to record them.
+	if secret := templates.OTPSecret(m.config.Content); secret != "" {
+		s.WriteString("\n\n")
+		s.WriteString(m.styles.Label.Render("Secret (not saved in history): " + secret))
+	}
+
 	if m.qrPreview != "" {
 		s.WriteString("\n\n")
 		s.WriteString(m.styles.Header.Render("Scan with your phone:"))
@@ -1021,14 +1332,22 @@ func (m Model) renderHelp() string {
 	var help string
 
 	switch m.step {
-	case StepContentType:
+	case StepPreset:
 		help = "↑/↓: Select • Enter/Space: Confirm • Ctrl+C: Quit"
+	case StepContentType:
+		if len(m.presets) > 0 {
+			help = "↑/↓: Select • Enter/Space: Confirm • Esc: Back • Ctrl+C: Quit"
+		} else {
+			help = "↑/↓: Select • Enter/Space: Confirm • Ctrl+C: Quit"
+		}
 	case StepURL, StepSize:
 		help = "Enter: Confirm • Esc: Back • Ctrl+C: Quit"
 	case StepTemplate:
 		help = "Tab/↓: Next field • Shift+Tab/↑: Prev • Enter: Confirm • Esc: Back • Ctrl+C: Quit"
 	case StepOutput:
-		if m.fileBrowserActive {
+		if m.confirmOverwrite {
+			help = "Y: Overwrite • N/Esc: Choose another name • Ctrl+C: Quit"
+		} else if m.fileBrowserActive {
 			if m.filePicker.nameMode {
 				help = "Enter: Confirm • Esc: Back to browsing • Ctrl+C: Quit"
 			} else {
@@ -1038,7 +1357,7 @@ func (m Model) renderHelp() string {
 			help = "Enter: Confirm • Tab: Browse files • Esc: Back • Ctrl+C: Quit"
 		}
 	case StepFormat:
-		help = "←/→: Select • Enter/Space: Confirm • Esc: Back • Ctrl+C: Quit"
+		help = "←/→: Move • Space: Toggle • Enter: Confirm • Esc: Back • Ctrl+C: Quit"
 	case StepColor:
 		if m.colorIndex == -1 {
 			help = "Enter: Confirm • Esc: Cancel custom color • Ctrl+C: Quit"
@@ -1,13 +1,18 @@
 // Template wizard UI component for structured content input.
 //
-// This component provides multi-field forms for WiFi, vCard, Email, and SMS
-// content types. Each template guides users through filling in the relevant
-// fields, then generates the properly formatted QR code content string.
+// This component provides multi-field forms for WiFi, vCard, Email, SMS,
+// calendar event, map location, SEPA payment, Swiss QR-bill, merchant payment, crypto payment and authenticator content types. Each template guides users through filling in
+// the relevant fields, then generates the properly formatted QR code content
+// string.
 package ui
 
 import (
+	"fmt"
+	"os"
 	"strings"
 
+	"github.com/DalyChouikh/internal/config"
+	"github.com/DalyChouikh/internal/generator"
 	"github.com/DalyChouikh/internal/templates"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -19,19 +24,35 @@ type TemplateWizard struct {
 	contentType templates.ContentType
 
 	// WiFi fields
-	wifiSSID     textinput.Model
-	wifiPassword textinput.Model
-	wifiEncIndex int  // 0=WPA, 1=WEP, 2=None
-	wifiHidden   bool // Hidden network toggle
+	wifiSSID              textinput.Model
+	wifiPassword          textinput.Model
+	wifiEncIndex          int  // Index into templates.WiFiEncryptionTypes()
+	wifiHidden            bool // Hidden network toggle
+	wifiTransitionDisable bool // WPA3 transition disable toggle (WPA and SAE)
+	wifiEAPIndex          int  // Index into templates.WiFiEAPMethods()
+	wifiPhase2Index       int  // Index into templates.WiFiPhase2Methods()
+	wifiIdentity          textinput.Model
+	wifiAnonIdentity      textinput.Model
 
 	// vCard fields
-	vcardFirstName textinput.Model
-	vcardLastName  textinput.Model
-	vcardPhone     textinput.Model
-	vcardEmail     textinput.Model
-	vcardOrg       textinput.Model
-	vcardTitle     textinput.Model
-	vcardURL       textinput.Model
+	vcardFirstName   textinput.Model
+	vcardLastName    textinput.Model
+	vcardPhone       textinput.Model
+	vcardPhoneWork   textinput.Model
+	vcardEmail       textinput.Model
+	vcardEmailWork   textinput.Model
+	vcardOrg         textinput.Model
+	vcardTitle       textinput.Model
+	vcardURL         textinput.Model
+	vcardStreet      textinput.Model
+	vcardCity        textinput.Model
+	vcardPostalCode  textinput.Model
+	vcardCountry     textinput.Model
+	vcardBirthday    textinput.Model
+	vcardNote        textinput.Model
+	vcardPhoto       textinput.Model
+	vcardFormatIndex int      // Index into templates.ContactFormats()
+	vcardSizes       []string // Symbol size per format, filled when the selector is focused
 
 	// Email fields
 	emailAddress textinput.Model
@@ -42,10 +63,78 @@ type TemplateWizard struct {
 	smsPhone   textinput.Model
 	smsMessage textinput.Model
 
+	// Event fields
+	eventSummary     textinput.Model
+	eventLocation    textinput.Model
+	eventStart       textinput.Model
+	eventEnd         textinput.Model
+	eventTimeZone    textinput.Model
+	eventAllDay      bool // All-day toggle
+	eventDescription textinput.Model
+	eventURL         textinput.Model
+
+	// Location fields
+	geoLatitude      textinput.Model
+	geoLongitude     textinput.Model
+	geoAltitude      textinput.Model
+	geoLabel         textinput.Model
+	geoProviderIndex int // Index into templates.GeoProviders()
+
+	// SEPA payment fields
+	sepaName      textinput.Model
+	sepaIBAN      textinput.Model
+	sepaBIC       textinput.Model
+	sepaAmount    textinput.Model
+	sepaReference textinput.Model
+	sepaText      textinput.Model
+	sepaPurpose   textinput.Model
+	sepaInfo      textinput.Model
+
+	// Swiss QR-bill fields
+	swissIBAN          textinput.Model
+	swissName          textinput.Model
+	swissStreet        textinput.Model
+	swissBuilding      textinput.Model
+	swissPostalCode    textinput.Model
+	swissTown          textinput.Model
+	swissCountry       textinput.Model
+	swissAmount        textinput.Model
+	swissReference     textinput.Model
+	swissMessage       textinput.Model
+	swissCurrencyIndex int // Index into templates.SwissCurrencies()
+
+	// Merchant payment fields
+	merchantSchemeIndex int // Index into templates.PaymentSchemes()
+	merchantAccount     textinput.Model
+	merchantName        textinput.Model
+	merchantCity        textinput.Model
+	merchantAmount      textinput.Model
+	merchantReference   textinput.Model
+	merchantDescription textinput.Model
+
+	// Crypto payment fields
+	cryptoNetworkIndex int // Index into templates.CryptoNetworks()
+	cryptoAddress      textinput.Model
+	cryptoAmount       textinput.Model
+	cryptoLabel        textinput.Model
+	cryptoMessage      textinput.Model
+	cryptoChainID      textinput.Model
+
+	// Authenticator fields
+	otpIssuer         textinput.Model
+	otpAccount        textinput.Model
+	otpSecret         textinput.Model
+	otpGenerate       bool            // Generate a random secret toggle
+	otpCounterBased   bool            // HOTP instead of TOTP
+	otpAlgorithmIndex int             // Index into templates.OTPAlgorithms()
+	otpEightDigits    bool            // 8 digits instead of 6
+	otpInterval       textinput.Model // TOTP period or HOTP counter
+
 	// Field navigation
 	focusIndex int    // Which field is focused
 	confirmed  bool   // User confirmed the form
 	result     string // Encoded content string
+	err        error  // Why the last confirmation failed, if it did
 }
 
 // NewTemplateWizard creates a new wizard for the given content type.
@@ -55,15 +144,26 @@ func NewTemplateWizard(ct templates.ContentType) TemplateWizard {
 	// WiFi
 	tw.wifiSSID = newInput("MyNetwork", 64)
 	tw.wifiPassword = newInput("password123", 128)
+	tw.wifiIdentity = newInput("alice@corp.example", 128)
+	tw.wifiAnonIdentity = newInput("anonymous@corp.example (optional)", 128)
 
 	// vCard
 	tw.vcardFirstName = newInput("John", 64)
 	tw.vcardLastName = newInput("Doe", 64)
 	tw.vcardPhone = newInput("+1234567890", 20)
+	tw.vcardPhoneWork = newInput("+1 555 0100", 20)
 	tw.vcardEmail = newInput("john@example.com", 128)
+	tw.vcardEmailWork = newInput("john@acme.example", 128)
 	tw.vcardOrg = newInput("Acme Inc.", 128)
 	tw.vcardTitle = newInput("Software Engineer", 128)
 	tw.vcardURL = newInput("https://example.com", 256)
+	tw.vcardStreet = newInput("1 Main Street", 128)
+	tw.vcardCity = newInput("Springfield", 64)
+	tw.vcardPostalCode = newInput("12345", 16)
+	tw.vcardCountry = newInput("USA", 64)
+	tw.vcardBirthday = newInput("1990-05-17 (YYYY-MM-DD)", 10)
+	tw.vcardNote = newInput("Met at GopherCon", 256)
+	tw.vcardPhoto = newInput("~/avatar.jpg (small JPEG/PNG, optional)", 256)
 
 	// Email
 	tw.emailAddress = newInput("user@example.com", 128)
@@ -74,6 +174,64 @@ func NewTemplateWizard(ct templates.ContentType) TemplateWizard {
 	tw.smsPhone = newInput("+1234567890", 20)
 	tw.smsMessage = newInput("Hello!", 256)
 
+	// Event
+	tw.eventSummary = newInput("GopherCon keynote", 256)
+	tw.eventLocation = newInput("Main hall, 1 Rue de Rivoli, Paris", 256)
+	tw.eventStart = newInput("2026-05-14 09:30", 25)
+	tw.eventEnd = newInput("2026-05-14 10:30 (optional)", 25)
+	tw.eventTimeZone = newInput("Europe/Paris (empty: local time)", 64)
+	tw.eventDescription = newInput("Doors open at 9:00", 512)
+	tw.eventURL = newInput("https://example.com/schedule", 256)
+
+	// Location
+	tw.geoLatitude = newInput("48.8584 or 48°51'30\"N", 32)
+	tw.geoLongitude = newInput("2.2945 or 2°17'40\"E", 32)
+	tw.geoAltitude = newInput("35 (meters, optional)", 16)
+	tw.geoLabel = newInput("Eiffel Tower", 128)
+
+	// SEPA payment
+	tw.sepaName = newInput("ACME GmbH", 70)
+	tw.sepaIBAN = newInput("DE89 3704 0044 0532 0130 00", 42)
+	tw.sepaBIC = newInput("COBADEFFXXX", 11)
+	tw.sepaAmount = newInput("12.50 (empty: payer enters it)", 16)
+	tw.sepaReference = newInput("RF18 5390 0754 7034", 31)
+	tw.sepaText = newInput("Invoice 2026-0042", 140)
+	tw.sepaPurpose = newInput("GDDS (optional)", 4)
+	tw.sepaInfo = newInput("Thank you!", 70)
+
+	// Swiss QR-bill
+	tw.swissIBAN = newInput("CH44 3199 9123 0008 8901 2", 26)
+	tw.swissName = newInput("Robert Schneider AG", 70)
+	tw.swissStreet = newInput("Rue du Lac", 70)
+	tw.swissBuilding = newInput("1268", 16)
+	tw.swissPostalCode = newInput("2501", 16)
+	tw.swissTown = newInput("Biel", 35)
+	tw.swissCountry = newInput("CH", 2)
+	tw.swissAmount = newInput("1949.75 (empty: payer enters it)", 16)
+	tw.swissReference = newInput("21 00000 00003 13947 14300 09017", 40)
+	tw.swissMessage = newInput("Order of 15 June 2026", 140)
+
+	// Merchant payment
+	tw.merchantAccount = newInput("PIX key, UPI ID (shop@okbank) or PayNow number/UEN", 77)
+	tw.merchantName = newInput("Padaria Central", 25)
+	tw.merchantCity = newInput("Sao Paulo", 15)
+	tw.merchantAmount = newInput("25.90 (empty: payer enters it)", 16)
+	tw.merchantReference = newInput("PEDIDO123", 35)
+	tw.merchantDescription = newInput("Order 123", 72)
+
+	// Crypto payment
+	tw.cryptoAddress = newInput("bc1q..., 0x... or a Lightning invoice (lnbc...)", 2048)
+	tw.cryptoAmount = newInput("0.0015 (BTC or ETH, empty: payer enters it)", 40)
+	tw.cryptoLabel = newInput("Alice's Coffee", 128)
+	tw.cryptoMessage = newInput("Order 42", 256)
+	tw.cryptoChainID = newInput("1 (empty: mainnet)", 20)
+
+	// Authenticator
+	tw.otpIssuer = newInput("ACME Corp", 64)
+	tw.otpAccount = newInput("alice@example.com", 128)
+	tw.otpSecret = newInput("JBSWY3DPEHPK3PXP... (base32)", 128)
+	tw.otpInterval = newInput("30 (empty: default)", 20)
+
 	// Focus the first field
 	tw.focusFirst()
 
@@ -102,6 +260,17 @@ func (tw *TemplateWizard) focusFirst() {
 		tw.emailAddress.Focus()
 	case templates.ContentSMS:
 		tw.smsPhone.Focus()
+	case templates.ContentEvent:
+		tw.eventSummary.Focus()
+	case templates.ContentGeo:
+		tw.geoLatitude.Focus()
+	case templates.ContentSEPA:
+		tw.sepaName.Focus()
+	case templates.ContentSwissQR:
+		tw.swissIBAN.Focus()
+	case templates.ContentOTP:
+		tw.otpIssuer.Focus()
+		// Merchant and crypto payments start on a selector (no text input)
 	}
 }
 
@@ -109,31 +278,106 @@ func (tw *TemplateWizard) focusFirst() {
 func (tw *TemplateWizard) blurAll() {
 	tw.wifiSSID.Blur()
 	tw.wifiPassword.Blur()
+	tw.wifiIdentity.Blur()
+	tw.wifiAnonIdentity.Blur()
 	tw.vcardFirstName.Blur()
 	tw.vcardLastName.Blur()
 	tw.vcardPhone.Blur()
+	tw.vcardPhoneWork.Blur()
 	tw.vcardEmail.Blur()
+	tw.vcardEmailWork.Blur()
 	tw.vcardOrg.Blur()
 	tw.vcardTitle.Blur()
 	tw.vcardURL.Blur()
+	tw.vcardStreet.Blur()
+	tw.vcardCity.Blur()
+	tw.vcardPostalCode.Blur()
+	tw.vcardCountry.Blur()
+	tw.vcardBirthday.Blur()
+	tw.vcardNote.Blur()
+	tw.vcardPhoto.Blur()
 	tw.emailAddress.Blur()
 	tw.emailSubject.Blur()
 	tw.emailBody.Blur()
 	tw.smsPhone.Blur()
 	tw.smsMessage.Blur()
+	tw.eventSummary.Blur()
+	tw.eventLocation.Blur()
+	tw.eventStart.Blur()
+	tw.eventEnd.Blur()
+	tw.eventTimeZone.Blur()
+	tw.eventDescription.Blur()
+	tw.eventURL.Blur()
+	tw.geoLatitude.Blur()
+	tw.geoLongitude.Blur()
+	tw.geoAltitude.Blur()
+	tw.geoLabel.Blur()
+	tw.sepaName.Blur()
+	tw.sepaIBAN.Blur()
+	tw.sepaBIC.Blur()
+	tw.sepaAmount.Blur()
+	tw.sepaReference.Blur()
+	tw.sepaText.Blur()
+	tw.sepaPurpose.Blur()
+	tw.sepaInfo.Blur()
+	tw.swissIBAN.Blur()
+	tw.swissName.Blur()
+	tw.swissStreet.Blur()
+	tw.swissBuilding.Blur()
+	tw.swissPostalCode.Blur()
+	tw.swissTown.Blur()
+	tw.swissCountry.Blur()
+	tw.swissAmount.Blur()
+	tw.swissReference.Blur()
+	tw.swissMessage.Blur()
+	tw.merchantAccount.Blur()
+	tw.merchantName.Blur()
+	tw.merchantCity.Blur()
+	tw.merchantAmount.Blur()
+	tw.merchantReference.Blur()
+	tw.merchantDescription.Blur()
+	tw.cryptoAddress.Blur()
+	tw.cryptoAmount.Blur()
+	tw.cryptoLabel.Blur()
+	tw.cryptoMessage.Blur()
+	tw.cryptoChainID.Blur()
+	tw.otpIssuer.Blur()
+	tw.otpAccount.Blur()
+	tw.otpSecret.Blur()
+	tw.otpInterval.Blur()
 }
 
 // fieldCount returns the number of fields for the current content type.
 func (tw *TemplateWizard) fieldCount() int {
 	switch tw.contentType {
 	case templates.ContentWiFi:
+		switch tw.wifiEncryption() {
+		case templates.WiFiEAP:
+			return 8 // SSID, Password, Encryption, Hidden, EAP method, Phase 2, Identity, Anonymous identity
+		case templates.WiFiWPA, templates.WiFiSAE:
+			return 5 // SSID, Password, Encryption, Hidden, Transition disable
+		}
 		return 4 // SSID, Password, Encryption, Hidden
 	case templates.ContentVCard:
-		return 7 // First, Last, Phone, Email, Org, Title, URL
+		return 17 // First, Last, Phones, Emails, Org, Title, URL, Address, Birthday, Note, Photo, Format
 	case templates.ContentEmail:
 		return 3 // Address, Subject, Body
 	case templates.ContentSMS:
 		return 2 // Phone, Message
+	case templates.ContentEvent:
+		return 8 // Summary, Location, Start, End, Time zone, All-day, Description, URL
+	case templates.ContentGeo:
+		return 5 // Latitude, Longitude, Altitude, Label, Provider
+	case templates.ContentSEPA:
+		return 8 // Name, IBAN, BIC, Amount, Reference, Text, Purpose, Info
+	case templates.ContentSwissQR:
+		return 11 // IBAN, Name, Street, Building, Postal code, Town, Country, Amount, Currency, Reference, Message
+	case templates.ContentMerchant:
+		return 7 // Scheme, Account, Name, City, Amount, Reference, Description
+	case templates.ContentCrypto:
+		return 6 // Network, Address, Amount, Label, Message, Chain ID
+	case templates.ContentOTP:
+		return 8 // Issuer, Account, Secret, Generate, Type, Algorithm, Digits, Period/Counter
 	}
 	return 0
 }
@@ -142,7 +386,24 @@ func (tw *TemplateWizard) fieldCount() int {
 func (tw *TemplateWizard) isToggleField() bool {
 	switch tw.contentType {
 	case templates.ContentWiFi:
-		return tw.focusIndex == 2 || tw.focusIndex == 3 // Encryption selector, Hidden toggle
+		if tw.wifiEncryption() == templates.WiFiEAP {
+			return tw.focusIndex >= 2 && tw.focusIndex <= 5 // Encryption, Hidden, EAP method, Phase 2
+		}
+		return tw.focusIndex >= 2 // Encryption selector, Hidden and Transition disable toggles
+	case templates.ContentVCard:
+		return tw.focusIndex == 16 // Format selector
+	case templates.ContentEvent:
+		return tw.focusIndex == 5 // All-day toggle
+	case templates.ContentGeo:
+		return tw.focusIndex == 4 // Provider selector
+	case templates.ContentSwissQR:
+		return tw.focusIndex == 8 // Currency selector
+	case templates.ContentMerchant:
+		return tw.focusIndex == 0 // Scheme selector
+	case templates.ContentCrypto:
+		return tw.focusIndex == 0 // Network selector
+	case templates.ContentOTP:
+		return tw.focusIndex >= 3 && tw.focusIndex <= 6 // Generate toggle, Type, Algorithm and Digits selectors
 	}
 	return false
 }
@@ -160,6 +421,12 @@ func (tw *TemplateWizard) focusField() tea.Cmd {
 			return tw.wifiPassword.Focus()
 			// 2 = encryption selector (no text input)
 			// 3 = hidden toggle (no text input)
+			// 4 = transition disable toggle, or EAP method selector
+			// 5 = phase 2 selector
+		case 6:
+			return tw.wifiIdentity.Focus()
+		case 7:
+			return tw.wifiAnonIdentity.Focus()
 		}
 	case templates.ContentVCard:
 		switch tw.focusIndex {
@@ -170,13 +437,33 @@ func (tw *TemplateWizard) focusField() tea.Cmd {
 		case 2:
 			return tw.vcardPhone.Focus()
 		case 3:
-			return tw.vcardEmail.Focus()
+			return tw.vcardPhoneWork.Focus()
 		case 4:
-			return tw.vcardOrg.Focus()
+			return tw.vcardEmail.Focus()
 		case 5:
-			return tw.vcardTitle.Focus()
+			return tw.vcardEmailWork.Focus()
 		case 6:
+			return tw.vcardOrg.Focus()
+		case 7:
+			return tw.vcardTitle.Focus()
+		case 8:
 			return tw.vcardURL.Focus()
+		case 9:
+			return tw.vcardStreet.Focus()
+		case 10:
+			return tw.vcardCity.Focus()
+		case 11:
+			return tw.vcardPostalCode.Focus()
+		case 12:
+			return tw.vcardCountry.Focus()
+		case 13:
+			return tw.vcardBirthday.Focus()
+		case 14:
+			return tw.vcardNote.Focus()
+		case 15:
+			return tw.vcardPhoto.Focus()
+		case 16:
+			tw.compareContactSizes()
 		}
 	case templates.ContentEmail:
 		switch tw.focusIndex {
@@ -194,12 +481,129 @@ func (tw *TemplateWizard) focusField() tea.Cmd {
 		case 1:
 			return tw.smsMessage.Focus()
 		}
+	case templates.ContentEvent:
+		switch tw.focusIndex {
+		case 0:
+			return tw.eventSummary.Focus()
+		case 1:
+			return tw.eventLocation.Focus()
+		case 2:
+			return tw.eventStart.Focus()
+		case 3:
+			return tw.eventEnd.Focus()
+		case 4:
+			return tw.eventTimeZone.Focus()
+			// 5 = all-day toggle (no text input)
+		case 6:
+			return tw.eventDescription.Focus()
+		case 7:
+			return tw.eventURL.Focus()
+		}
+	case templates.ContentGeo:
+		switch tw.focusIndex {
+		case 0:
+			return tw.geoLatitude.Focus()
+		case 1:
+			return tw.geoLongitude.Focus()
+		case 2:
+			return tw.geoAltitude.Focus()
+		case 3:
+			return tw.geoLabel.Focus()
+			// 4 = provider selector (no text input)
+		}
+	case templates.ContentSEPA:
+		switch tw.focusIndex {
+		case 0:
+			return tw.sepaName.Focus()
+		case 1:
+			return tw.sepaIBAN.Focus()
+		case 2:
+			return tw.sepaBIC.Focus()
+		case 3:
+			return tw.sepaAmount.Focus()
+		case 4:
+			return tw.sepaReference.Focus()
+		case 5:
+			return tw.sepaText.Focus()
+		case 6:
+			return tw.sepaPurpose.Focus()
+		case 7:
+			return tw.sepaInfo.Focus()
+		}
+	case templates.ContentSwissQR:
+		switch tw.focusIndex {
+		case 0:
+			return tw.swissIBAN.Focus()
+		case 1:
+			return tw.swissName.Focus()
+		case 2:
+			return tw.swissStreet.Focus()
+		case 3:
+			return tw.swissBuilding.Focus()
+		case 4:
+			return tw.swissPostalCode.Focus()
+		case 5:
+			return tw.swissTown.Focus()
+		case 6:
+			return tw.swissCountry.Focus()
+		case 7:
+			return tw.swissAmount.Focus()
+			// 8 = currency selector (no text input)
+		case 9:
+			return tw.swissReference.Focus()
+		case 10:
+			return tw.swissMessage.Focus()
+		}
+	case templates.ContentMerchant:
+		switch tw.focusIndex {
+		// 0 = scheme selector (no text input)
+		case 1:
+			return tw.merchantAccount.Focus()
+		case 2:
+			return tw.merchantName.Focus()
+		case 3:
+			return tw.merchantCity.Focus()
+		case 4:
+			return tw.merchantAmount.Focus()
+		case 5:
+			return tw.merchantReference.Focus()
+		case 6:
+			return tw.merchantDescription.Focus()
+		}
+	case templates.ContentCrypto:
+		switch tw.focusIndex {
+		// 0 = network selector (no text input)
+		case 1:
+			return tw.cryptoAddress.Focus()
+		case 2:
+			return tw.cryptoAmount.Focus()
+		case 3:
+			return tw.cryptoLabel.Focus()
+		case 4:
+			return tw.cryptoMessage.Focus()
+		case 5:
+			return tw.cryptoChainID.Focus()
+		}
+	case templates.ContentOTP:
+		switch tw.focusIndex {
+		case 0:
+			return tw.otpIssuer.Focus()
+		case 1:
+			return tw.otpAccount.Focus()
+		case 2:
+			return tw.otpSecret.Focus()
+			// 3-6 = generate toggle, type, algorithm and digits selectors (no text input)
+		case 7:
+			return tw.otpInterval.Focus()
+		}
 	}
 	return nil
 }
 
 // Update handles key messages for the template wizard.
 func (tw *TemplateWizard) Update(msg tea.KeyMsg) tea.Cmd {
+	tw.err = nil
+
 	switch msg.String() {
 	case "tab", "down":
 		if tw.focusIndex < tw.fieldCount()-1 {
@@ -237,20 +641,124 @@ func (tw *TemplateWizard) handleToggle(key string) {
 	if tw.contentType == templates.ContentWiFi {
 		switch tw.focusIndex {
 		case 2: // Encryption
+			selectOption(&tw.wifiEncIndex, len(templates.WiFiEncryptionTypes()), key)
+		case 3: // Hidden
+			tw.wifiHidden = !tw.wifiHidden
+		case 4: // Transition disable, or EAP method for enterprise networks
+			if tw.wifiEncryption() == templates.WiFiEAP {
+				selectOption(&tw.wifiEAPIndex, len(templates.WiFiEAPMethods()), key)
+			} else {
+				tw.wifiTransitionDisable = !tw.wifiTransitionDisable
+			}
+		case 5: // Phase 2
+			selectOption(&tw.wifiPhase2Index, len(templates.WiFiPhase2Methods()), key)
+		}
+	}
+	if tw.contentType == templates.ContentVCard && tw.focusIndex == 16 {
+		n := len(templates.ContactFormats())
+		switch key {
+		case "left":
+			if tw.vcardFormatIndex > 0 {
+				tw.vcardFormatIndex--
+			}
+		case "right":
+			if tw.vcardFormatIndex < n-1 {
+				tw.vcardFormatIndex++
+			}
+		case " ":
+			tw.vcardFormatIndex = (tw.vcardFormatIndex + 1) % n
+		}
+	}
+	if tw.contentType == templates.ContentEvent && tw.focusIndex == 5 {
+		tw.eventAllDay = !tw.eventAllDay
+	}
+	if tw.contentType == templates.ContentGeo && tw.focusIndex == 4 {
+		n := len(templates.GeoProviders())
+		switch key {
+		case "left":
+			if tw.geoProviderIndex > 0 {
+				tw.geoProviderIndex--
+			}
+		case "right":
+			if tw.geoProviderIndex < n-1 {
+				tw.geoProviderIndex++
+			}
+		case " ":
+			tw.geoProviderIndex = (tw.geoProviderIndex + 1) % n
+		}
+	}
+	if tw.contentType == templates.ContentSwissQR && tw.focusIndex == 8 {
+		n := len(templates.SwissCurrencies())
+		switch key {
+		case "left":
+			if tw.swissCurrencyIndex > 0 {
+				tw.swissCurrencyIndex--
+			}
+		case "right":
+			if tw.swissCurrencyIndex < n-1 {
+				tw.swissCurrencyIndex++
+			}
+		case " ":
+			tw.swissCurrencyIndex = (tw.swissCurrencyIndex + 1) % n
+		}
+	}
+	if tw.contentType == templates.ContentMerchant && tw.focusIndex == 0 {
+		n := len(templates.PaymentSchemes())
+		switch key {
+		case "left":
+			if tw.merchantSchemeIndex > 0 {
+				tw.merchantSchemeIndex--
+			}
+		case "right":
+			if tw.merchantSchemeIndex < n-1 {
+				tw.merchantSchemeIndex++
+			}
+		case " ":
+			tw.merchantSchemeIndex = (tw.merchantSchemeIndex + 1) % n
+		}
+	}
+	if tw.contentType == templates.ContentCrypto && tw.focusIndex == 0 {
+		n := len(templates.CryptoNetworks())
+		switch key {
+		case "left":
+			if tw.cryptoNetworkIndex > 0 {
+				tw.cryptoNetworkIndex--
+			}
+		case "right":
+			if tw.cryptoNetworkIndex < n-1 {
+				tw.cryptoNetworkIndex++
+			}
+		case " ":
+			tw.cryptoNetworkIndex = (tw.cryptoNetworkIndex + 1) % n
+		}
+	}
+	if tw.contentType == templates.ContentOTP {
+		switch tw.focusIndex {
+		case 3: // Generate secret
+			tw.otpGenerate = !tw.otpGenerate
+		case 4: // TOTP or HOTP
+			tw.otpCounterBased = !tw.otpCounterBased
+			if tw.otpCounterBased {
+				tw.otpInterval.Placeholder = "0 (initial counter)"
+			} else {
+				tw.otpInterval.Placeholder = "30 (empty: default)"
+			}
+		case 5: // Algorithm
+			n := len(templates.OTPAlgorithms())
 			switch key {
 			case "left":
-				if tw.wifiEncIndex > 0 {
-					tw.wifiEncIndex--
+				if tw.otpAlgorithmIndex > 0 {
+					tw.otpAlgorithmIndex--
 				}
 			case "right":
-				if tw.wifiEncIndex < 2 {
-					tw.wifiEncIndex++
+				if tw.otpAlgorithmIndex < n-1 {
+					tw.otpAlgorithmIndex++
 				}
 			case " ":
-				tw.wifiEncIndex = (tw.wifiEncIndex + 1) % 3
+				tw.otpAlgorithmIndex = (tw.otpAlgorithmIndex + 1) % n
 			}
-		case 3: // Hidden
-			tw.wifiHidden = !tw.wifiHidden
+		case 6: // Digits
+			tw.otpEightDigits = !tw.otpEightDigits
 		}
 	}
 }
@@ -266,6 +774,10 @@ func (tw *TemplateWizard) updateCurrentInput(msg tea.KeyMsg) tea.Cmd {
 			tw.wifiSSID, cmd = tw.wifiSSID.Update(msg)
 		case 1:
 			tw.wifiPassword, cmd = tw.wifiPassword.Update(msg)
+		case 6:
+			tw.wifiIdentity, cmd = tw.wifiIdentity.Update(msg)
+		case 7:
+			tw.wifiAnonIdentity, cmd = tw.wifiAnonIdentity.Update(msg)
 		}
 	case templates.ContentVCard:
 		switch tw.focusIndex {
@@ -276,13 +788,31 @@ func (tw *TemplateWizard) updateCurrentInput(msg tea.KeyMsg) tea.Cmd {
 		case 2:
 			tw.vcardPhone, cmd = tw.vcardPhone.Update(msg)
 		case 3:
-			tw.vcardEmail, cmd = tw.vcardEmail.Update(msg)
+			tw.vcardPhoneWork, cmd = tw.vcardPhoneWork.Update(msg)
 		case 4:
-			tw.vcardOrg, cmd = tw.vcardOrg.Update(msg)
+			tw.vcardEmail, cmd = tw.vcardEmail.Update(msg)
 		case 5:
-			tw.vcardTitle, cmd = tw.vcardTitle.Update(msg)
+			tw.vcardEmailWork, cmd = tw.vcardEmailWork.Update(msg)
 		case 6:
+			tw.vcardOrg, cmd = tw.vcardOrg.Update(msg)
+		case 7:
+			tw.vcardTitle, cmd = tw.vcardTitle.Update(msg)
+		case 8:
 			tw.vcardURL, cmd = tw.vcardURL.Update(msg)
+		case 9:
+			tw.vcardStreet, cmd = tw.vcardStreet.Update(msg)
+		case 10:
+			tw.vcardCity, cmd = tw.vcardCity.Update(msg)
+		case 11:
+			tw.vcardPostalCode, cmd = tw.vcardPostalCode.Update(msg)
+		case 12:
+			tw.vcardCountry, cmd = tw.vcardCountry.Update(msg)
+		case 13:
+			tw.vcardBirthday, cmd = tw.vcardBirthday.Update(msg)
+		case 14:
+			tw.vcardNote, cmd = tw.vcardNote.Update(msg)
+		case 15:
+			tw.vcardPhoto, cmd = tw.vcardPhoto.Update(msg)
 		}
 	case templates.ContentEmail:
 		switch tw.focusIndex {
@@ -300,6 +830,115 @@ func (tw *TemplateWizard) updateCurrentInput(msg tea.KeyMsg) tea.Cmd {
 		case 1:
 			tw.smsMessage, cmd = tw.smsMessage.Update(msg)
 		}
+	case templates.ContentEvent:
+		switch tw.focusIndex {
+		case 0:
+			tw.eventSummary, cmd = tw.eventSummary.Update(msg)
+		case 1:
+			tw.eventLocation, cmd = tw.eventLocation.Update(msg)
+		case 2:
+			tw.eventStart, cmd = tw.eventStart.Update(msg)
+		case 3:
+			tw.eventEnd, cmd = tw.eventEnd.Update(msg)
+		case 4:
+			tw.eventTimeZone, cmd = tw.eventTimeZone.Update(msg)
+		case 6:
+			tw.eventDescription, cmd = tw.eventDescription.Update(msg)
+		case 7:
+			tw.eventURL, cmd = tw.eventURL.Update(msg)
+		}
+	case templates.ContentGeo:
+		switch tw.focusIndex {
+		case 0:
+			tw.geoLatitude, cmd = tw.geoLatitude.Update(msg)
+		case 1:
+			tw.geoLongitude, cmd = tw.geoLongitude.Update(msg)
+		case 2:
+			tw.geoAltitude, cmd = tw.geoAltitude.Update(msg)
+		case 3:
+			tw.geoLabel, cmd = tw.geoLabel.Update(msg)
+		}
+	case templates.ContentSEPA:
+		switch tw.focusIndex {
+		case 0:
+			tw.sepaName, cmd = tw.sepaName.Update(msg)
+		case 1:
+			tw.sepaIBAN, cmd = tw.sepaIBAN.Update(msg)
+		case 2:
+			tw.sepaBIC, cmd = tw.sepaBIC.Update(msg)
+		case 3:
+			tw.sepaAmount, cmd = tw.sepaAmount.Update(msg)
+		case 4:
+			tw.sepaReference, cmd = tw.sepaReference.Update(msg)
+		case 5:
+			tw.sepaText, cmd = tw.sepaText.Update(msg)
+		case 6:
+			tw.sepaPurpose, cmd = tw.sepaPurpose.Update(msg)
+		case 7:
+			tw.sepaInfo, cmd = tw.sepaInfo.Update(msg)
+		}
+	case templates.ContentSwissQR:
+		switch tw.focusIndex {
+		case 0:
+			tw.swissIBAN, cmd = tw.swissIBAN.Update(msg)
+		case 1:
+			tw.swissName, cmd = tw.swissName.Update(msg)
+		case 2:
+			tw.swissStreet, cmd = tw.swissStreet.Update(msg)
+		case 3:
+			tw.swissBuilding, cmd = tw.swissBuilding.Update(msg)
+		case 4:
+			tw.swissPostalCode, cmd = tw.swissPostalCode.Update(msg)
+		case 5:
+			tw.swissTown, cmd = tw.swissTown.Update(msg)
+		case 6:
+			tw.swissCountry, cmd = tw.swissCountry.Update(msg)
+		case 7:
+			tw.swissAmount, cmd = tw.swissAmount.Update(msg)
+		case 9:
+			tw.swissReference, cmd = tw.swissReference.Update(msg)
+		case 10:
+			tw.swissMessage, cmd = tw.swissMessage.Update(msg)
+		}
+	case templates.ContentMerchant:
+		switch tw.focusIndex {
+		case 1:
+			tw.merchantAccount, cmd = tw.merchantAccount.Update(msg)
+		case 2:
+			tw.merchantName, cmd = tw.merchantName.Update(msg)
+		case 3:
+			tw.merchantCity, cmd = tw.merchantCity.Update(msg)
+		case 4:
+			tw.merchantAmount, cmd = tw.merchantAmount.Update(msg)
+		case 5:
+			tw.merchantReference, cmd = tw.merchantReference.Update(msg)
+		case 6:
+			tw.merchantDescription, cmd = tw.merchantDescription.Update(msg)
+		}
+	case templates.ContentCrypto:
+		switch tw.focusIndex {
+		case 1:
+			tw.cryptoAddress, cmd = tw.cryptoAddress.Update(msg)
+		case 2:
+			tw.cryptoAmount, cmd = tw.cryptoAmount.Update(msg)
+		case 3:
+			tw.cryptoLabel, cmd = tw.cryptoLabel.Update(msg)
+		case 4:
+			tw.cryptoMessage, cmd = tw.cryptoMessage.Update(msg)
+		case 5:
+			tw.cryptoChainID, cmd = tw.cryptoChainID.Update(msg)
+		}
+	case templates.ContentOTP:
+		switch tw.focusIndex {
+		case 0:
+			tw.otpIssuer, cmd = tw.otpIssuer.Update(msg)
+		case 1:
+			tw.otpAccount, cmd = tw.otpAccount.Update(msg)
+		case 2:
+			tw.otpSecret, cmd = tw.otpSecret.Update(msg)
+		case 7:
+			tw.otpInterval, cmd = tw.otpInterval.Update(msg)
+		}
 	}
 
 	return cmd
@@ -316,6 +955,10 @@ func (tw *TemplateWizard) UpdateBlink(msg tea.Msg) tea.Cmd {
 			tw.wifiSSID, cmd = tw.wifiSSID.Update(msg)
 		case 1:
 			tw.wifiPassword, cmd = tw.wifiPassword.Update(msg)
+		case 6:
+			tw.wifiIdentity, cmd = tw.wifiIdentity.Update(msg)
+		case 7:
+			tw.wifiAnonIdentity, cmd = tw.wifiAnonIdentity.Update(msg)
 		}
 	case templates.ContentVCard:
 		switch tw.focusIndex {
@@ -326,13 +969,31 @@ func (tw *TemplateWizard) UpdateBlink(msg tea.Msg) tea.Cmd {
 		case 2:
 			tw.vcardPhone, cmd = tw.vcardPhone.Update(msg)
 		case 3:
-			tw.vcardEmail, cmd = tw.vcardEmail.Update(msg)
+			tw.vcardPhoneWork, cmd = tw.vcardPhoneWork.Update(msg)
 		case 4:
-			tw.vcardOrg, cmd = tw.vcardOrg.Update(msg)
+			tw.vcardEmail, cmd = tw.vcardEmail.Update(msg)
 		case 5:
-			tw.vcardTitle, cmd = tw.vcardTitle.Update(msg)
+			tw.vcardEmailWork, cmd = tw.vcardEmailWork.Update(msg)
 		case 6:
+			tw.vcardOrg, cmd = tw.vcardOrg.Update(msg)
+		case 7:
+			tw.vcardTitle, cmd = tw.vcardTitle.Update(msg)
+		case 8:
 			tw.vcardURL, cmd = tw.vcardURL.Update(msg)
+		case 9:
+			tw.vcardStreet, cmd = tw.vcardStreet.Update(msg)
+		case 10:
+			tw.vcardCity, cmd = tw.vcardCity.Update(msg)
+		case 11:
+			tw.vcardPostalCode, cmd = tw.vcardPostalCode.Update(msg)
+		case 12:
+			tw.vcardCountry, cmd = tw.vcardCountry.Update(msg)
+		case 13:
+			tw.vcardBirthday, cmd = tw.vcardBirthday.Update(msg)
+		case 14:
+			tw.vcardNote, cmd = tw.vcardNote.Update(msg)
+		case 15:
+			tw.vcardPhoto, cmd = tw.vcardPhoto.Update(msg)
 		}
 	case templates.ContentEmail:
 		switch tw.focusIndex {
@@ -350,6 +1011,115 @@ func (tw *TemplateWizard) UpdateBlink(msg tea.Msg) tea.Cmd {
 		case 1:
 			tw.smsMessage, cmd = tw.smsMessage.Update(msg)
 		}
+	case templates.ContentEvent:
+		switch tw.focusIndex {
+		case 0:
+			tw.eventSummary, cmd = tw.eventSummary.Update(msg)
+		case 1:
+			tw.eventLocation, cmd = tw.eventLocation.Update(msg)
+		case 2:
+			tw.eventStart, cmd = tw.eventStart.Update(msg)
+		case 3:
+			tw.eventEnd, cmd = tw.eventEnd.Update(msg)
+		case 4:
+			tw.eventTimeZone, cmd = tw.eventTimeZone.Update(msg)
+		case 6:
+			tw.eventDescription, cmd = tw.eventDescription.Update(msg)
+		case 7:
+			tw.eventURL, cmd = tw.eventURL.Update(msg)
+		}
+	case templates.ContentGeo:
+		switch tw.focusIndex {
+		case 0:
+			tw.geoLatitude, cmd = tw.geoLatitude.Update(msg)
+		case 1:
+			tw.geoLongitude, cmd = tw.geoLongitude.Update(msg)
+		case 2:
+			tw.geoAltitude, cmd = tw.geoAltitude.Update(msg)
+		case 3:
+			tw.geoLabel, cmd = tw.geoLabel.Update(msg)
+		}
+	case templates.ContentSEPA:
+		switch tw.focusIndex {
+		case 0:
+			tw.sepaName, cmd = tw.sepaName.Update(msg)
+		case 1:
+			tw.sepaIBAN, cmd = tw.sepaIBAN.Update(msg)
+		case 2:
+			tw.sepaBIC, cmd = tw.sepaBIC.Update(msg)
+		case 3:
+			tw.sepaAmount, cmd = tw.sepaAmount.Update(msg)
+		case 4:
+			tw.sepaReference, cmd = tw.sepaReference.Update(msg)
+		case 5:
+			tw.sepaText, cmd = tw.sepaText.Update(msg)
+		case 6:
+			tw.sepaPurpose, cmd = tw.sepaPurpose.Update(msg)
+		case 7:
+			tw.sepaInfo, cmd = tw.sepaInfo.Update(msg)
+		}
+	case templates.ContentSwissQR:
+		switch tw.focusIndex {
+		case 0:
+			tw.swissIBAN, cmd = tw.swissIBAN.Update(msg)
+		case 1:
+			tw.swissName, cmd = tw.swissName.Update(msg)
+		case 2:
+			tw.swissStreet, cmd = tw.swissStreet.Update(msg)
+		case 3:
+			tw.swissBuilding, cmd = tw.swissBuilding.Update(msg)
+		case 4:
+			tw.swissPostalCode, cmd = tw.swissPostalCode.Update(msg)
+		case 5:
+			tw.swissTown, cmd = tw.swissTown.Update(msg)
+		case 6:
+			tw.swissCountry, cmd = tw.swissCountry.Update(msg)
+		case 7:
+			tw.swissAmount, cmd = tw.swissAmount.Update(msg)
+		case 9:
+			tw.swissReference, cmd = tw.swissReference.Update(msg)
+		case 10:
+			tw.swissMessage, cmd = tw.swissMessage.Update(msg)
+		}
+	case templates.ContentMerchant:
+		switch tw.focusIndex {
+		case 1:
+			tw.merchantAccount, cmd = tw.merchantAccount.Update(msg)
+		case 2:
+			tw.merchantName, cmd = tw.merchantName.Update(msg)
+		case 3:
+			tw.merchantCity, cmd = tw.merchantCity.Update(msg)
+		case 4:
+			tw.merchantAmount, cmd = tw.merchantAmount.Update(msg)
+		case 5:
+			tw.merchantReference, cmd = tw.merchantReference.Update(msg)
+		case 6:
+			tw.merchantDescription, cmd = tw.merchantDescription.Update(msg)
+		}
+	case templates.ContentCrypto:
+		switch tw.focusIndex {
+		case 1:
+			tw.cryptoAddress, cmd = tw.cryptoAddress.Update(msg)
+		case 2:
+			tw.cryptoAmount, cmd = tw.cryptoAmount.Update(msg)
+		case 3:
+			tw.cryptoLabel, cmd = tw.cryptoLabel.Update(msg)
+		case 4:
+			tw.cryptoMessage, cmd = tw.cryptoMessage.Update(msg)
+		case 5:
+			tw.cryptoChainID, cmd = tw.cryptoChainID.Update(msg)
+		}
+	case templates.ContentOTP:
+		switch tw.focusIndex {
+		case 0:
+			tw.otpIssuer, cmd = tw.otpIssuer.Update(msg)
+		case 1:
+			tw.otpAccount, cmd = tw.otpAccount.Update(msg)
+		case 2:
+			tw.otpSecret, cmd = tw.otpSecret.Update(msg)
+		case 7:
+			tw.otpInterval, cmd = tw.otpInterval.Update(msg)
+		}
 	}
 
 	return cmd
@@ -363,32 +1133,46 @@ func (tw *TemplateWizard) tryEncode() bool {
 		if ssid == "" {
 			return false
 		}
-		encTypes := templates.WiFiEncryptionTypes()
 		data := &templates.WiFiData{
 			SSID:       ssid,
 			Password:   tw.wifiPassword.Value(),
-			Encryption: encTypes[tw.wifiEncIndex].Type,
+			Encryption: tw.wifiEncryption(),
 			Hidden:     tw.wifiHidden,
 		}
+		switch data.Encryption {
+		case templates.WiFiWPA, templates.WiFiSAE:
+			data.TransitionDisable = tw.wifiTransitionDisable
+		case templates.WiFiEAP:
+			data.EAPMethod = templates.WiFiEAPMethods()[tw.wifiEAPIndex]
+			if data.EAPMethod != "PWD" {
+				data.Phase2Method = templates.WiFiPhase2Methods()[tw.wifiPhase2Index]
+			}
+			data.Identity = strings.TrimSpace(tw.wifiIdentity.Value())
+			data.AnonymousIdentity = strings.TrimSpace(tw.wifiAnonIdentity.Value())
+		}
+		if err := data.Validate(); err != nil {
+			tw.err = err
+			return false
+		}
 		tw.result = data.Encode()
 		return true
 
 	case templates.ContentVCard:
-		firstName := strings.TrimSpace(tw.vcardFirstName.Value())
-		lastName := strings.TrimSpace(tw.vcardLastName.Value())
-		if firstName == "" && lastName == "" {
+		if strings.TrimSpace(tw.vcardFirstName.Value()) == "" && strings.TrimSpace(tw.vcardLastName.Value()) == "" {
 			return false
 		}
-		data := &templates.VCardData{
-			FirstName:    firstName,
-			LastName:     lastName,
-			Phone:        strings.TrimSpace(tw.vcardPhone.Value()),
-			Email:        strings.TrimSpace(tw.vcardEmail.Value()),
-			Organization: strings.TrimSpace(tw.vcardOrg.Value()),
-			Title:        strings.TrimSpace(tw.vcardTitle.Value()),
-			URL:          strings.TrimSpace(tw.vcardURL.Value()),
+		fields, err := tw.contactFields()
+		if err != nil {
+			tw.err = err
+			return false
 		}
-		tw.result = data.Encode()
+		setContactFormat(fields, tw.vcardFormatIndex)
+		result, err := templates.FromFields(templates.ContentVCard, fields)
+		if err != nil {
+			tw.err = err
+			return false
+		}
+		tw.result = result
 		return true
 
 	case templates.ContentEmail:
@@ -415,6 +1199,143 @@ func (tw *TemplateWizard) tryEncode() bool {
 		}
 		tw.result = data.Encode()
 		return true
+
+	case templates.ContentEvent:
+		allDay := "false"
+		if tw.eventAllDay {
+			allDay = "true"
+		}
+		result, err := templates.FromFields(templates.ContentEvent, map[string]string{
+			"summary":     tw.eventSummary.Value(),
+			"location":    tw.eventLocation.Value(),
+			"start":       tw.eventStart.Value(),
+			"end":         tw.eventEnd.Value(),
+			"timezone":    tw.eventTimeZone.Value(),
+			"all_day":     allDay,
+			"description": tw.eventDescription.Value(),
+			"url":         tw.eventURL.Value(),
+		})
+		if err != nil {
+			tw.err = err
+			return false
+		}
+		tw.result = result
+		return true
+
+	case templates.ContentGeo:
+		result, err := templates.FromFields(templates.ContentGeo, map[string]string{
+			"latitude":  tw.geoLatitude.Value(),
+			"longitude": tw.geoLongitude.Value(),
+			"altitude":  tw.geoAltitude.Value(),
+			"label":     tw.geoLabel.Value(),
+			"provider":  string(templates.GeoProviders()[tw.geoProviderIndex].Provider),
+		})
+		if err != nil {
+			tw.err = err
+			return false
+		}
+		tw.result = result
+		return true
+
+	case templates.ContentSEPA:
+		result, err := templates.FromFields(templates.ContentSEPA, map[string]string{
+			"name":      tw.sepaName.Value(),
+			"iban":      tw.sepaIBAN.Value(),
+			"bic":       tw.sepaBIC.Value(),
+			"amount":    tw.sepaAmount.Value(),
+			"reference": tw.sepaReference.Value(),
+			"text":      tw.sepaText.Value(),
+			"purpose":   tw.sepaPurpose.Value(),
+			"info":      tw.sepaInfo.Value(),
+		})
+		if err != nil {
+			tw.err = err
+			return false
+		}
+		tw.result = result
+		return true
+
+	case templates.ContentSwissQR:
+		result, err := templates.FromFields(templates.ContentSwissQR, map[string]string{
+			"iban":                 tw.swissIBAN.Value(),
+			"creditor_name":        tw.swissName.Value(),
+			"creditor_street":      tw.swissStreet.Value(),
+			"creditor_building":    tw.swissBuilding.Value(),
+			"creditor_postal_code": tw.swissPostalCode.Value(),
+			"creditor_town":        tw.swissTown.Value(),
+			"creditor_country":     tw.swissCountry.Value(),
+			"amount":               tw.swissAmount.Value(),
+			"reference":            tw.swissReference.Value(),
+			"message":              tw.swissMessage.Value(),
+			"currency":             templates.SwissCurrencies()[tw.swissCurrencyIndex],
+		})
+		if err != nil {
+			tw.err = err
+			return false
+		}
+		tw.result = result
+		return true
+
+	case templates.ContentMerchant:
+		result, err := templates.FromFields(templates.ContentMerchant, map[string]string{
+			"scheme":      string(templates.PaymentSchemes()[tw.merchantSchemeIndex].Scheme),
+			"account":     tw.merchantAccount.Value(),
+			"name":        tw.merchantName.Value(),
+			"city":        tw.merchantCity.Value(),
+			"amount":      tw.merchantAmount.Value(),
+			"reference":   tw.merchantReference.Value(),
+			"description": tw.merchantDescription.Value(),
+		})
+		if err != nil {
+			tw.err = err
+			return false
+		}
+		tw.result = result
+		return true
+
+	case templates.ContentCrypto:
+		result, err := templates.FromFields(templates.ContentCrypto, map[string]string{
+			"network":  string(templates.CryptoNetworks()[tw.cryptoNetworkIndex].Network),
+			"address":  tw.cryptoAddress.Value(),
+			"amount":   tw.cryptoAmount.Value(),
+			"label":    tw.cryptoLabel.Value(),
+			"message":  tw.cryptoMessage.Value(),
+			"chain_id": tw.cryptoChainID.Value(),
+		})
+		if err != nil {
+			tw.err = err
+			return false
+		}
+		tw.result = result
+		return true
+
+	case templates.ContentOTP:
+		fields := map[string]string{
+			"otp_type":        "totp",
+			"issuer":          tw.otpIssuer.Value(),
+			"account":         tw.otpAccount.Value(),
+			"secret":          tw.otpSecret.Value(),
+			"generate_secret": "false",
+			"algorithm":       templates.OTPAlgorithms()[tw.otpAlgorithmIndex],
+			"period":          tw.otpInterval.Value(),
+		}
+		if tw.otpGenerate {
+			fields["generate_secret"] = "true"
+		}
+		if tw.otpCounterBased {
+			fields["otp_type"] = "hotp"
+			fields["counter"], fields["period"] = fields["period"], ""
+		}
+		if tw.otpEightDigits {
+			fields["digits"] = "8"
+		}
+		result, err := templates.FromFields(templates.ContentOTP, fields)
+		if err != nil {
+			tw.err = err
+			return false
+		}
+		tw.result = result
+		return true
 	}
 
 	return false
@@ -425,6 +1346,12 @@ func (tw *TemplateWizard) IsConfirmed() bool {
 	return tw.confirmed
 }
 
+// Err returns why the last confirmation was rejected, or nil. Forms that
+// only require a field to be filled in do not report errors.
+func (tw *TemplateWizard) Err() error {
+	return tw.err
+}
+
 // Result returns the encoded content string.
 func (tw *TemplateWizard) Result() string {
 	return tw.result
@@ -441,6 +1368,20 @@ func (tw *TemplateWizard) View(styles *Styles) string {
 		return tw.viewEmail(styles)
 	case templates.ContentSMS:
 		return tw.viewSMS(styles)
+	case templates.ContentEvent:
+		return tw.viewEvent(styles)
+	case templates.ContentGeo:
+		return tw.viewGeo(styles)
+	case templates.ContentSEPA:
+		return tw.viewSEPA(styles)
+	case templates.ContentSwissQR:
+		return tw.viewSwissQR(styles)
+	case templates.ContentMerchant:
+		return tw.viewMerchant(styles)
+	case templates.ContentCrypto:
+		return tw.viewCrypto(styles)
+	case templates.ContentOTP:
+		return tw.viewOTP(styles)
 	}
 	return ""
 }
@@ -483,19 +1424,173 @@ func (tw *TemplateWizard) viewWiFi(styles *Styles) string {
 	}
 	s.WriteString(label.Render("Hidden Network: ") + label.Render(toggleStr))
 
+	switch tw.wifiEncryption() {
+	case templates.WiFiWPA, templates.WiFiSAE:
+		// Transition disable toggle
+		s.WriteString("\n\n")
+		label = styles.Label
+		if tw.focusIndex == 4 {
+			label = styles.LabelFocused
+		}
+		toggleStr = "○ No"
+		if tw.wifiTransitionDisable {
+			toggleStr = "● Yes"
+		}
+		s.WriteString(label.Render("WPA3 Only After Joining (transition disable): ") + label.Render(toggleStr))
+
+	case templates.WiFiEAP:
+		// EAP and phase 2 selectors
+		selector := func(index int, title string, options []string, active int) {
+			s.WriteString("\n\n")
+			label := styles.Label
+			if tw.focusIndex == index {
+				label = styles.LabelFocused
+			}
+			s.WriteString(label.Render(title))
+			s.WriteString("\n")
+			var btns []string
+			for i, o := range options {
+				style := styles.Button
+				if i == active {
+					style = styles.ButtonActive
+				}
+				btns = append(btns, style.Render(o))
+			}
+			s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, btns...))
+		}
+		selector(4, "EAP Method:", templates.WiFiEAPMethods(), tw.wifiEAPIndex)
+		phase2 := "Phase 2:"
+		if templates.WiFiEAPMethods()[tw.wifiEAPIndex] == "PWD" {
+			phase2 = "Phase 2 (not used by PWD):"
+		}
+		selector(5, phase2, templates.WiFiPhase2Methods(), tw.wifiPhase2Index)
+		s.WriteString("\n")
+		s.WriteString(renderField(styles, "Identity:", &tw.wifiIdentity, tw.focusIndex == 6, false))
+		s.WriteString(renderField(styles, "Anonymous Identity:", &tw.wifiAnonIdentity, tw.focusIndex == 7, false))
+	}
+
 	return s.String()
 }
 
+// wifiEncryption returns the selected WiFi encryption type.
+func (tw *TemplateWizard) wifiEncryption() templates.WiFiEncryption {
+	return templates.WiFiEncryptionTypes()[tw.wifiEncIndex].Type
+}
+
+// contactFields collects the contact form as FromFields input, reading the
+// photo file when one is given. The format is left for the caller to set.
+func (tw *TemplateWizard) contactFields() (map[string]string, error) {
+	fields := map[string]string{
+		"first_name":          tw.vcardFirstName.Value(),
+		"last_name":           tw.vcardLastName.Value(),
+		"phone":               tw.vcardPhone.Value(),
+		"phone_work":          tw.vcardPhoneWork.Value(),
+		"email":               tw.vcardEmail.Value(),
+		"email_work":          tw.vcardEmailWork.Value(),
+		"organization":        tw.vcardOrg.Value(),
+		"title":               tw.vcardTitle.Value(),
+		"url":                 tw.vcardURL.Value(),
+		"address_street":      tw.vcardStreet.Value(),
+		"address_city":        tw.vcardCity.Value(),
+		"address_postal_code": tw.vcardPostalCode.Value(),
+		"address_country":     tw.vcardCountry.Value(),
+		"birthday":            tw.vcardBirthday.Value(),
+		"note":                tw.vcardNote.Value(),
+	}
+	if path := strings.TrimSpace(tw.vcardPhoto.Value()); path != "" {
+		photo, err := os.ReadFile(config.ExpandHome(path))
+		if err != nil {
+			return nil, err
+		}
+		fields["photo"] = templates.PhotoDataURI(photo)
+	}
+	return fields, nil
+}
+
+// setContactFormat sets the "format" and "version" fields for the
+// templates.ContactFormats entry at index i.
+func setContactFormat(fields map[string]string, i int) {
+	f := templates.ContactFormats()[i]
+	fields["format"] = "vcard"
+	if f.MeCard {
+		fields["format"] = "mecard"
+	}
+	fields["version"] = string(f.Version)
+}
+
+// compareContactSizes encodes the contact in every format and records the
+// symbol each one needs at level M, so the format selector can show which
+// produces the smallest code. Formats that cannot hold the contact, such
+// as a MeCard with a photo, are marked "n/a".
+func (tw *TemplateWizard) compareContactSizes() {
+	tw.vcardSizes = nil
+	fields, err := tw.contactFields()
+	if err != nil {
+		return
+	}
+	for i := range templates.ContactFormats() {
+		setContactFormat(fields, i)
+		size := "n/a"
+		if content, err := templates.FromFields(templates.ContentVCard, fields); err == nil {
+			if version, err := generator.SymbolVersion(content, config.ECMedium); err == nil {
+				modules := 17 + 4*version
+				size = fmt.Sprintf("v%d, %d×%d", version, modules, modules)
+			} else {
+				size = "too large"
+			}
+		}
+		tw.vcardSizes = append(tw.vcardSizes, size)
+	}
+}
+
 func (tw *TemplateWizard) viewVCard(styles *Styles) string {
 	var s strings.Builder
 
 	s.WriteString(renderField(styles, "First Name:", &tw.vcardFirstName, tw.focusIndex == 0, true))
 	s.WriteString(renderField(styles, "Last Name:", &tw.vcardLastName, tw.focusIndex == 1, false))
-	s.WriteString(renderField(styles, "Phone:", &tw.vcardPhone, tw.focusIndex == 2, false))
-	s.WriteString(renderField(styles, "Email:", &tw.vcardEmail, tw.focusIndex == 3, false))
-	s.WriteString(renderField(styles, "Organization:", &tw.vcardOrg, tw.focusIndex == 4, false))
-	s.WriteString(renderField(styles, "Job Title:", &tw.vcardTitle, tw.focusIndex == 5, false))
-	s.WriteString(renderField(styles, "Website:", &tw.vcardURL, tw.focusIndex == 6, false))
+	s.WriteString(renderField(styles, "Mobile Phone:", &tw.vcardPhone, tw.focusIndex == 2, false))
+	s.WriteString(renderField(styles, "Work Phone:", &tw.vcardPhoneWork, tw.focusIndex == 3, false))
+	s.WriteString(renderField(styles, "Email:", &tw.vcardEmail, tw.focusIndex == 4, false))
+	s.WriteString(renderField(styles, "Work Email:", &tw.vcardEmailWork, tw.focusIndex == 5, false))
+	s.WriteString(renderField(styles, "Organization:", &tw.vcardOrg, tw.focusIndex == 6, false))
+	s.WriteString(renderField(styles, "Job Title:", &tw.vcardTitle, tw.focusIndex == 7, false))
+	s.WriteString(renderField(styles, "Website:", &tw.vcardURL, tw.focusIndex == 8, false))
+	s.WriteString(renderField(styles, "Street:", &tw.vcardStreet, tw.focusIndex == 9, false))
+	s.WriteString(renderField(styles, "City:", &tw.vcardCity, tw.focusIndex == 10, false))
+	s.WriteString(renderField(styles, "Postal Code:", &tw.vcardPostalCode, tw.focusIndex == 11, false))
+	s.WriteString(renderField(styles, "Country:", &tw.vcardCountry, tw.focusIndex == 12, false))
+	s.WriteString(renderField(styles, "Birthday:", &tw.vcardBirthday, tw.focusIndex == 13, false))
+	s.WriteString(renderField(styles, "Note:", &tw.vcardNote, tw.focusIndex == 14, false))
+	s.WriteString(renderField(styles, "Photo File:", &tw.vcardPhoto, tw.focusIndex == 15, false))
+
+	// Format selector
+	s.WriteString("\n")
+	label := styles.Label
+	if tw.focusIndex == 16 {
+		label = styles.LabelFocused
+	}
+	s.WriteString(label.Render("Format:"))
+	s.WriteString("\n")
+
+	var btns []string
+	for i, f := range templates.ContactFormats() {
+		style := styles.Button
+		if i == tw.vcardFormatIndex {
+			style = styles.ButtonActive
+		}
+		btns = append(btns, style.Render(f.Name))
+	}
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, btns...))
+
+	// Capacity comparison, so the smallest symbol can be picked
+	if len(tw.vcardSizes) > 0 {
+		var sizes []string
+		for i, f := range templates.ContactFormats() {
+			sizes = append(sizes, f.Name+": "+tw.vcardSizes[i])
+		}
+		s.WriteString("\n")
+		s.WriteString(styles.Help.Render("QR size at level M — " + strings.Join(sizes, " · ")))
+	}
 
 	return s.String()
 }
@@ -519,6 +1614,257 @@ func (tw *TemplateWizard) viewSMS(styles *Styles) string {
 	return s.String()
 }
 
+func (tw *TemplateWizard) viewEvent(styles *Styles) string {
+	var s strings.Builder
+
+	s.WriteString(renderField(styles, "Title:", &tw.eventSummary, tw.focusIndex == 0, true))
+	s.WriteString(renderField(styles, "Location:", &tw.eventLocation, tw.focusIndex == 1, false))
+	s.WriteString(renderField(styles, "Start (YYYY-MM-DD HH:MM, or a date for all-day):", &tw.eventStart, tw.focusIndex == 2, false))
+	s.WriteString(renderField(styles, "End:", &tw.eventEnd, tw.focusIndex == 3, false))
+	s.WriteString(renderField(styles, "Time Zone:", &tw.eventTimeZone, tw.focusIndex == 4, false))
+
+	// All-day toggle
+	s.WriteString("\n\n")
+	label := styles.Label
+	if tw.focusIndex == 5 {
+		label = styles.LabelFocused
+	}
+	toggleStr := "○ No"
+	if tw.eventAllDay {
+		toggleStr = "● Yes"
+	}
+	s.WriteString(label.Render("All-day Event: ") + label.Render(toggleStr))
+	s.WriteString("\n")
+
+	s.WriteString(renderField(styles, "Description:", &tw.eventDescription, tw.focusIndex == 6, false))
+	s.WriteString(renderField(styles, "Website:", &tw.eventURL, tw.focusIndex == 7, false))
+
+	return s.String()
+}
+
+func (tw *TemplateWizard) viewGeo(styles *Styles) string {
+	var s strings.Builder
+
+	s.WriteString(renderField(styles, "Latitude (decimal or DMS):", &tw.geoLatitude, tw.focusIndex == 0, true))
+	s.WriteString(renderField(styles, "Longitude:", &tw.geoLongitude, tw.focusIndex == 1, false))
+	s.WriteString(renderField(styles, "Altitude:", &tw.geoAltitude, tw.focusIndex == 2, false))
+	s.WriteString(renderField(styles, "Label:", &tw.geoLabel, tw.focusIndex == 3, false))
+
+	// Provider selector
+	s.WriteString("\n")
+	label := styles.Label
+	if tw.focusIndex == 4 {
+		label = styles.LabelFocused
+	}
+	s.WriteString(label.Render("Open With:"))
+	s.WriteString("\n")
+
+	var btns []string
+	for i, p := range templates.GeoProviders() {
+		style := styles.Button
+		if i == tw.geoProviderIndex {
+			style = styles.ButtonActive
+		}
+		btns = append(btns, style.Render(p.Name))
+	}
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, btns...))
+
+	return s.String()
+}
+
+func (tw *TemplateWizard) viewSEPA(styles *Styles) string {
+	var s strings.Builder
+
+	s.WriteString(renderField(styles, "Beneficiary Name:", &tw.sepaName, tw.focusIndex == 0, true))
+	s.WriteString(renderField(styles, "IBAN:", &tw.sepaIBAN, tw.focusIndex == 1, false))
+	s.WriteString(renderField(styles, "BIC (optional in the EEA):", &tw.sepaBIC, tw.focusIndex == 2, false))
+	s.WriteString(renderField(styles, "Amount (EUR):", &tw.sepaAmount, tw.focusIndex == 3, false))
+	s.WriteString(renderField(styles, "Creditor Reference (RF):", &tw.sepaReference, tw.focusIndex == 4, false))
+	s.WriteString(renderField(styles, "Remittance Text (instead of a reference):", &tw.sepaText, tw.focusIndex == 5, false))
+	s.WriteString(renderField(styles, "Purpose Code:", &tw.sepaPurpose, tw.focusIndex == 6, false))
+	s.WriteString(renderField(styles, "Note to Payer:", &tw.sepaInfo, tw.focusIndex == 7, false))
+
+	return s.String()
+}
+
+func (tw *TemplateWizard) viewSwissQR(styles *Styles) string {
+	var s strings.Builder
+
+	s.WriteString(renderField(styles, "IBAN or QR-IBAN:", &tw.swissIBAN, tw.focusIndex == 0, true))
+	s.WriteString(renderField(styles, "Creditor Name:", &tw.swissName, tw.focusIndex == 1, false))
+	s.WriteString(renderField(styles, "Street:", &tw.swissStreet, tw.focusIndex == 2, false))
+	s.WriteString(renderField(styles, "Building Number:", &tw.swissBuilding, tw.focusIndex == 3, false))
+	s.WriteString(renderField(styles, "Postal Code:", &tw.swissPostalCode, tw.focusIndex == 4, false))
+	s.WriteString(renderField(styles, "Town:", &tw.swissTown, tw.focusIndex == 5, false))
+	s.WriteString(renderField(styles, "Country:", &tw.swissCountry, tw.focusIndex == 6, false))
+	s.WriteString(renderField(styles, "Amount:", &tw.swissAmount, tw.focusIndex == 7, false))
+
+	// Currency selector
+	s.WriteString("\n")
+	label := styles.Label
+	if tw.focusIndex == 8 {
+		label = styles.LabelFocused
+	}
+	s.WriteString(label.Render("Currency:"))
+	s.WriteString("\n")
+
+	var btns []string
+	for i, c := range templates.SwissCurrencies() {
+		style := styles.Button
+		if i == tw.swissCurrencyIndex {
+			style = styles.ButtonActive
+		}
+		btns = append(btns, style.Render(c))
+	}
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, btns...))
+	s.WriteString("\n")
+
+	s.WriteString(renderField(styles, "Reference (QR reference or RF...):", &tw.swissReference, tw.focusIndex == 9, false))
+	s.WriteString(renderField(styles, "Message:", &tw.swissMessage, tw.focusIndex == 10, false))
+
+	return s.String()
+}
+
+func (tw *TemplateWizard) viewMerchant(styles *Styles) string {
+	var s strings.Builder
+
+	// Scheme selector
+	label := styles.Label
+	if tw.focusIndex == 0 {
+		label = styles.LabelFocused
+	}
+	s.WriteString(label.Render("Payment Scheme:"))
+	s.WriteString("\n")
+
+	var btns []string
+	for i, p := range templates.PaymentSchemes() {
+		style := styles.Button
+		if i == tw.merchantSchemeIndex {
+			style = styles.ButtonActive
+		}
+		btns = append(btns, style.Render(p.Name))
+	}
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, btns...))
+	s.WriteString("\n")
+
+	s.WriteString(renderField(styles, "Account (PIX key, UPI ID, PayNow mobile or UEN):", &tw.merchantAccount, tw.focusIndex == 1, false))
+	s.WriteString(renderField(styles, "Merchant Name:", &tw.merchantName, tw.focusIndex == 2, false))
+	s.WriteString(renderField(styles, "City (PIX):", &tw.merchantCity, tw.focusIndex == 3, false))
+	s.WriteString(renderField(styles, "Amount:", &tw.merchantAmount, tw.focusIndex == 4, false))
+	s.WriteString(renderField(styles, "Reference:", &tw.merchantReference, tw.focusIndex == 5, false))
+	s.WriteString(renderField(styles, "Description:", &tw.merchantDescription, tw.focusIndex == 6, false))
+
+	return s.String()
+}
+
+func (tw *TemplateWizard) viewCrypto(styles *Styles) string {
+	var s strings.Builder
+
+	// Network selector
+	label := styles.Label
+	if tw.focusIndex == 0 {
+		label = styles.LabelFocused
+	}
+	s.WriteString(label.Render("Network:"))
+	s.WriteString("\n")
+
+	var btns []string
+	for i, n := range templates.CryptoNetworks() {
+		style := styles.Button
+		if i == tw.cryptoNetworkIndex {
+			style = styles.ButtonActive
+		}
+		btns = append(btns, style.Render(n.Name))
+	}
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, btns...))
+	s.WriteString("\n")
+
+	s.WriteString(renderField(styles, "Address (or Lightning invoice):", &tw.cryptoAddress, tw.focusIndex == 1, false))
+	s.WriteString(renderField(styles, "Amount:", &tw.cryptoAmount, tw.focusIndex == 2, false))
+	s.WriteString(renderField(styles, "Label (Bitcoin):", &tw.cryptoLabel, tw.focusIndex == 3, false))
+	s.WriteString(renderField(styles, "Message (Bitcoin):", &tw.cryptoMessage, tw.focusIndex == 4, false))
+	s.WriteString(renderField(styles, "Chain ID (Ethereum):", &tw.cryptoChainID, tw.focusIndex == 5, false))
+
+	return s.String()
+}
+
+func (tw *TemplateWizard) viewOTP(styles *Styles) string {
+	var s strings.Builder
+
+	s.WriteString(renderField(styles, "Issuer:", &tw.otpIssuer, tw.focusIndex == 0, true))
+	s.WriteString(renderField(styles, "Account:", &tw.otpAccount, tw.focusIndex == 1, false))
+	s.WriteString(renderField(styles, "Secret (base32):", &tw.otpSecret, tw.focusIndex == 2, false))
+
+	// Generate toggle
+	s.WriteString("\n\n")
+	label := styles.Label
+	if tw.focusIndex == 3 {
+		label = styles.LabelFocused
+	}
+	toggleStr := "○ No"
+	if tw.otpGenerate {
+		toggleStr = "● Yes"
+	}
+	s.WriteString(label.Render("Generate Random Secret: ") + label.Render(toggleStr))
+
+	// Type, algorithm and digits selectors
+	selector := func(index int, title string, options []string, active int) {
+		s.WriteString("\n\n")
+		label := styles.Label
+		if tw.focusIndex == index {
+			label = styles.LabelFocused
+		}
+		s.WriteString(label.Render(title))
+		s.WriteString("\n")
+		var btns []string
+		for i, o := range options {
+			style := styles.Button
+			if i == active {
+				style = styles.ButtonActive
+			}
+			btns = append(btns, style.Render(o))
+		}
+		s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, btns...))
+	}
+	selector(4, "Type:", []string{"TOTP (time)", "HOTP (counter)"}, boolIndex(tw.otpCounterBased))
+	selector(5, "Algorithm:", templates.OTPAlgorithms(), tw.otpAlgorithmIndex)
+	selector(6, "Digits:", []string{"6", "8"}, boolIndex(tw.otpEightDigits))
+	s.WriteString("\n")
+
+	interval := "Period (seconds):"
+	if tw.otpCounterBased {
+		interval = "Initial Counter:"
+	}
+	s.WriteString(renderField(styles, interval, &tw.otpInterval, tw.focusIndex == 7, false))
+
+	return s.String()
+}
+
+// selectOption moves a selector index with left/right and cycles it with
+// space, for selectors with n options.
+func selectOption(index *int, n int, key string) {
+	switch key {
+	case "left":
+		if *index > 0 {
+			*index--
+		}
+	case "right":
+		if *index < n-1 {
+			*index++
+		}
+	case " ":
+		*index = (*index + 1) % n
+	}
+}
+
+// boolIndex returns 1 for true and 0 for false, for two-option selectors.
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 // renderField renders a labeled text input field.
 func renderField(styles *Styles, labelText string, input *textinput.Model, focused bool, first bool) string {
 	var s strings.Builder
@@ -0,0 +1,85 @@
+package qrgen
+
+import (
+	"errors"
+
+	"github.com/DalyChouikh/internal/config"
+	"github.com/DalyChouikh/internal/generator"
+)
+
+// Errors returned by Render, Encode, WriteFile and SwissQRSize. They are
+// usually wrapped with additional context, so match them with errors.Is.
+var (
+	// ErrEmptyContent is returned when there is nothing to encode.
+	ErrEmptyContent = config.ErrEmptyContent
+
+	// ErrInvalidSize is returned when the size is outside 64-4096 pixels.
+	ErrInvalidSize = config.ErrInvalidSize
+
+	// ErrInvalidFormat is returned for an unsupported output format.
+	ErrInvalidFormat = config.ErrInvalidFormat
+
+	// ErrInvalidEmbed is returned when WithEmbed names a format that
+	// cannot be embedded.
+	ErrInvalidEmbed = config.ErrInvalidEmbed
+
+	// ErrInvalidDPI is returned when the DPI is outside 72-2400.
+	ErrInvalidDPI = config.ErrInvalidDPI
+
+	// ErrHalftoneFormat is returned when halftone mode is used without a
+	// raster output format.
+	ErrHalftoneFormat = config.ErrHalftoneFormat
+
+	// ErrInvalidAnimation is returned for an unknown animation mode.
+	ErrInvalidAnimation = config.ErrInvalidAnimation
+
+	// ErrAnimationFormat is returned when an animation is requested
+	// without FormatGIF.
+	ErrAnimationFormat = config.ErrAnimationFormat
+
+	// ErrAnimationContrast is returned when a frame's foreground color is
+	// too close to the background to scan.
+	ErrAnimationContrast = config.ErrAnimationContrast
+
+	// ErrSwissCrossAnimation is returned when WithSwissCross is combined
+	// with an animation.
+	ErrSwissCrossAnimation = config.ErrSwissCrossAnimation
+
+	// ErrRequiredLevel is returned when the content type fixes the error
+	// correction level (Swiss QR-bills use M) and another level was set.
+	ErrRequiredLevel = config.ErrRequiredLevel
+
+	// ErrInvalidModuleStyle is returned for an unknown module style.
+	ErrInvalidModuleStyle = config.ErrInvalidModuleStyle
+
+	// ErrInvalidFrame is returned when the frame is wider than 8 modules.
+	ErrInvalidFrame = config.ErrInvalidFrame
+
+	// ErrStyleAnimation, ErrStyleHalftone and ErrStyleSwissCross are
+	// returned when gradients, module styles, logos or frames are combined
+	// with an animation, halftone mode or the Swiss cross.
+	ErrStyleAnimation  = config.ErrStyleAnimation
+	ErrStyleHalftone   = config.ErrStyleHalftone
+	ErrStyleSwissCross = config.ErrStyleSwissCross
+
+	// ErrGradientContrast is returned when a gradient's end color is too
+	// close to the background to scan.
+	ErrGradientContrast = config.ErrGradientContrast
+
+	// ErrInvalidRenderer is returned for an unknown text renderer.
+	ErrInvalidRenderer = config.ErrInvalidRenderer
+
+	// ErrInvalidErrorCorrection is returned for an unknown correction level.
+	ErrInvalidErrorCorrection = config.ErrInvalidErrorCorrection
+
+	// ErrInvalidColor is returned for a nil color, or when the foreground
+	// and background are the same color.
+	ErrInvalidColor = errors.New("foreground and background must be distinct colors")
+
+	// ErrEmptyOutputPath is returned by WriteFile when path is empty.
+	ErrEmptyOutputPath = config.ErrEmptyOutputPath
+
+	// ErrEncoding is returned when the content cannot be encoded, typically
+	// because it exceeds the capacity of the largest QR code version.
+	ErrEncoding = generator.ErrEncoding
+)
@@ -0,0 +1,87 @@
+package qrgen_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DalyChouikh/pkg/qrgen"
+)
+
+func ExampleEncode() {
+	png, err := qrgen.Encode(context.Background(), "https://example.com", qrgen.WithSize(512))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(strings.HasPrefix(string(png), "\x89PNG"))
+	// Output: true
+}
+
+func ExampleEncode_text() {
+	art, err := qrgen.Encode(context.Background(), "hi",
+		qrgen.WithFormat(qrgen.FormatText),
+		qrgen.WithTextRenderer(qrgen.RendererASCII, false),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(strings.Count(string(art), "\n") > 20)
+	// Output: true
+}
+
+func ExampleWriteFile() {
+	dir, err := os.MkdirTemp("", "qrgen")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The file is written to exactly this path, whatever its extension.
+	path := filepath.Join(dir, "code.img")
+	err = qrgen.WriteFile(context.Background(), path, "https://example.com", qrgen.WithFormat(qrgen.FormatSVG))
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(strings.Contains(string(data), "<svg"))
+	// Output: true
+}
+
+func ExampleEncodeWiFi() {
+	payload, err := qrgen.EncodeWiFi(qrgen.WiFiData{
+		SSID:       "Office",
+		Password:   "s3cret;",
+		Encryption: qrgen.WiFiWPA,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(payload)
+	// Output: WIFI:T:WPA;S:Office;P:s3cret\;;;
+}
+
+func ExampleEncodeSEPA() {
+	payload, err := qrgen.EncodeSEPA(qrgen.SEPAData{
+		Name:   "Red Cross",
+		IBAN:   "DE89370400440532013000",
+		Amount: 1250,
+		Text:   "Donation",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(strings.Split(payload, "\n")[0:3])
+	// Output: [BCD 002 1]
+}
+
+func ExampleWithSize_invalid() {
+	_, err := qrgen.Encode(context.Background(), "https://example.com", qrgen.WithSize(10))
+	fmt.Println(err)
+	// Output: size must be between 64 and 4096 pixels
+}
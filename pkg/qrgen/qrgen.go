@@ -0,0 +1,392 @@
+// Package qrgen is the public Go API for embedding the qrgen QR code
+// generator in other programs.
+//
+// It wraps the same configuration, generator and content templates used by
+// the qrgen CLI, so codes produced here are identical to the ones produced
+// by the binary. Options are supplied as functional options on top of the
+// CLI defaults (PNG, 256px, black on white).
+//
+// Render a PNG into any io.Writer, for example an HTTP response:
+//
+//	err := qrgen.Render(ctx, w, "https://example.com",
+//		qrgen.WithFormat(qrgen.FormatPNG),
+//		qrgen.WithSize(512),
+//	)
+//
+// Write an SVG file atomically:
+//
+//	payload, err := qrgen.EncodeWiFi(qrgen.WiFiData{
+//		SSID:       "Office",
+//		Password:   "secret",
+//		Encryption: qrgen.WiFiWPA,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	err = qrgen.WriteFile(ctx, "wifi.svg", payload, qrgen.WithFormat(qrgen.FormatSVG))
+//
+// Every entry point validates its options before encoding, and template
+// encoders validate their data. Errors can be inspected with errors.Is
+// against the sentinel errors declared in this package, such as
+// ErrEmptyContent or ErrEncoding.
+package qrgen
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"io"
+	"time"
+
+	"github.com/DalyChouikh/internal/config"
+	"github.com/DalyChouikh/internal/fsutil"
+	"github.com/DalyChouikh/internal/generator"
+)
+
+// Format is an output format.
+type Format string
+
+// Supported output formats.
+const (
+	FormatPNG  Format = Format(config.FormatPNG)
+	FormatJPEG Format = Format(config.FormatJPEG)
+	FormatGIF  Format = Format(config.FormatGIF)
+	FormatSVG  Format = Format(config.FormatSVG)
+	FormatText Format = Format(config.FormatText)
+
+	// Embedding formats wrap the image chosen with WithEmbed.
+	FormatHTML     Format = Format(config.FormatHTML)     // <img> with a data URI, or inline <svg>
+	FormatDataURI  Format = Format(config.FormatDataURI)  // A bare data: URI
+	FormatMarkdown Format = Format(config.FormatMarkdown) // ![alt](data:...) image
+)
+
+// TextRenderer selects how modules are drawn for FormatText.
+type TextRenderer string
+
+// Supported text renderers.
+const (
+	RendererANSI     TextRenderer = TextRenderer(config.RendererANSI)
+	RendererASCII    TextRenderer = TextRenderer(config.RendererASCII)
+	RendererBlocks   TextRenderer = TextRenderer(config.RendererBlocks)
+	RendererQuadrant TextRenderer = TextRenderer(config.RendererQuadrant)
+	RendererBraille  TextRenderer = TextRenderer(config.RendererBraille)
+)
+
+// Option configures a QR code generation call.
+type Option func(*settings)
+
+// settings holds the options of one call. toConfig maps it onto the
+// generator's configuration.
+type settings struct {
+	format     Format
+	size       int
+	dpi        int
+	halftone   string
+	embed      Format
+	alt        string
+	fg, bg     color.Color
+	level      ErrorCorrection
+	renderer   TextRenderer
+	invert     bool
+	swissCross bool
+
+	animation   AnimationMode
+	frames      int
+	delay       time.Duration
+	loop        int
+	payloads    []string
+	cycleColors []color.Color
+
+	err error // Set by an option given an unusable value
+}
+
+// WithFormat sets the output format. The default is FormatPNG.
+func WithFormat(f Format) Option {
+	return func(s *settings) {
+		s.format = f
+	}
+}
+
+// WithSize sets the image width and height in pixels (64-4096).
+func WithSize(px int) Option {
+	return func(s *settings) {
+		s.size = px
+	}
+}
+
+// WithDPI records the print resolution in PNG and JPEG metadata so the
+// image prints at its intended physical size.
+func WithDPI(dpi int) Option {
+	return func(s *settings) {
+		s.dpi = dpi
+	}
+}
+
+// WithHalftone blends the image at path into PNG, JPEG and GIF output: each
+// module keeps its true color only at its center, and the rest of the code
+// shows a dithered version of the image. Use it with ECHigh.
+func WithHalftone(path string) Option {
+	return func(s *settings) {
+		s.halftone = path
+	}
+}
+
+// WithEmbed sets the image format wrapped by FormatHTML, FormatDataURI and
+// FormatMarkdown: FormatPNG (the default), FormatJPEG, FormatGIF or
+// FormatSVG. With FormatHTML an SVG is inlined as an <svg> element.
+func WithEmbed(f Format) Option {
+	return func(s *settings) {
+		s.embed = f
+	}
+}
+
+// WithAlt sets the alt text of FormatHTML and FormatMarkdown output
+// (default "QR code").
+func WithAlt(alt string) Option {
+	return func(s *settings) {
+		s.alt = alt
+	}
+}
+
+// AnimationMode selects how FormatGIF output is animated.
+type AnimationMode string
+
+// Supported animation modes. Every frame is a complete, scannable code.
+const (
+	AnimationColorCycle AnimationMode = AnimationMode(config.AnimationColorCycle)
+	AnimationReveal     AnimationMode = AnimationMode(config.AnimationReveal)
+	AnimationPayloads   AnimationMode = AnimationMode(config.AnimationPayloads)
+)
+
+// WithAnimation animates FormatGIF output. frames is the number of frames
+// per loop for color cycling and reveals (0 means 12), delay is how long
+// each frame is shown (0 picks a default) and loop is the number of times
+// to play (0 loops forever).
+func WithAnimation(mode AnimationMode, frames int, delay time.Duration, loop int) Option {
+	return func(s *settings) {
+		s.animation = mode
+		s.frames = frames
+		s.delay = delay
+		s.loop = loop
+	}
+}
+
+// WithPayloads sets the contents AnimationPayloads shows after the main
+// content, one per frame.
+func WithPayloads(payloads ...string) Option {
+	return func(s *settings) {
+		s.payloads = payloads
+	}
+}
+
+// WithCycleColors sets the foreground colors AnimationColorCycle blends
+// through. Each must contrast with the background.
+func WithCycleColors(colors ...color.Color) Option {
+	return func(s *settings) {
+		s.cycleColors = colors
+	}
+}
+
+// WithForeground sets the color of the dark modules.
+func WithForeground(fg color.Color) Option {
+	return func(s *settings) {
+		if fg == nil {
+			s.err = ErrInvalidColor
+		}
+		s.fg = fg
+	}
+}
+
+// WithBackground sets the background color.
+func WithBackground(bg color.Color) Option {
+	return func(s *settings) {
+		if bg == nil {
+			s.err = ErrInvalidColor
+		}
+		s.bg = bg
+	}
+}
+
+// ErrorCorrection is a QR error correction level.
+type ErrorCorrection string
+
+// Error correction levels, from least to most redundant.
+const (
+	ECLow      ErrorCorrection = ErrorCorrection(config.ECLow)
+	ECMedium   ErrorCorrection = ErrorCorrection(config.ECMedium)
+	ECQuartile ErrorCorrection = ErrorCorrection(config.ECQuartile)
+	ECHigh     ErrorCorrection = ErrorCorrection(config.ECHigh)
+)
+
+// WithSwissCross overlays the Swiss cross in the center of image output, as
+// Swiss QR-bills require. Pair it with WithDPI and a size from
+// SwissQRSize to print the code at the mandated 46 mm.
+func WithSwissCross() Option {
+	return func(s *settings) {
+		s.swissCross = true
+	}
+}
+
+// WithErrorCorrection sets the error correction level. The default is
+// ECMedium.
+func WithErrorCorrection(level ErrorCorrection) Option {
+	return func(s *settings) {
+		s.level = level
+	}
+}
+
+// WithTextRenderer sets the renderer used for FormatText. When invert is
+// true, light modules are drawn instead of dark ones.
+func WithTextRenderer(r TextRenderer, invert bool) Option {
+	return func(s *settings) {
+		s.renderer = r
+		s.invert = invert
+	}
+}
+
+// SwissQRSize returns the pixel size at which the code for content prints
+// with a 46 mm symbol at dpi, for use with WithSize and WithDPI.
+func SwissQRSize(content string, dpi int) (int, error) {
+	cfg, err := newConfig(content, nil)
+	if err != nil {
+		return 0, err
+	}
+	bitmap, err := generator.New(cfg).Bitmap()
+	if err != nil {
+		return 0, err
+	}
+	return config.SwissQRSize(len(bitmap), dpi), nil
+}
+
+// Render encodes content and writes the QR code to w.
+func Render(ctx context.Context, w io.Writer, content string, opts ...Option) error {
+	cfg, err := newConfig(content, opts)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return generator.New(cfg).Render(&ctxWriter{ctx: ctx, w: w})
+}
+
+// Encode encodes content and returns the QR code bytes.
+func Encode(ctx context.Context, content string, opts ...Option) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Render(ctx, &buf, content, opts...); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile encodes content and writes the QR code to exactly path, in the
+// format chosen with WithFormat whatever the file extension. The file is
+// written atomically: it either contains the complete QR code or is left
+// untouched.
+func WriteFile(ctx context.Context, path, content string, opts ...Option) error {
+	if path == "" {
+		return ErrEmptyOutputPath
+	}
+	cfg, err := newConfig(content, opts)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	gen := generator.New(cfg)
+	return fsutil.WriteFileAtomic(path, func(w io.Writer) error {
+		return gen.Render(&ctxWriter{ctx: ctx, w: w})
+	})
+}
+
+// newConfig applies opts on top of the CLI defaults and validates the
+// result, so every entry point rejects bad options the same way.
+func newConfig(content string, opts []Option) (*config.QRConfig, error) {
+	s := defaultSettings()
+	for _, opt := range opts {
+		opt(s)
+	}
+	cfg, err := s.toConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Content = content
+	if err := cfg.ValidateOptions(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// defaultSettings returns the CLI defaults: PNG, 256px, black on white,
+// error correction M.
+func defaultSettings() *settings {
+	d := config.DefaultConfig()
+	return &settings{
+		format:   Format(d.Format),
+		size:     d.Size,
+		fg:       d.Foreground,
+		bg:       d.Background,
+		level:    ErrorCorrection(d.Level),
+		renderer: TextRenderer(d.Renderer),
+	}
+}
+
+// toConfig maps the settings onto the generator's configuration.
+func (s *settings) toConfig() (*config.QRConfig, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	cfg := &config.QRConfig{
+		Format:     config.OutputFormat(s.format),
+		Size:       s.size,
+		Foreground: toRGBA(s.fg),
+		Background: toRGBA(s.bg),
+		DPI:        s.dpi,
+		Halftone:   s.halftone,
+		Embed:      config.OutputFormat(s.embed),
+		Alt:        s.alt,
+		Level:      config.ErrorCorrection(s.level),
+		Renderer:   config.TextRenderer(s.renderer),
+		Invert:     s.invert,
+		SwissCross: s.swissCross,
+	}
+	if cfg.Foreground == cfg.Background {
+		return nil, ErrInvalidColor
+	}
+
+	cfg.Animation = config.Animation{
+		Mode:     config.AnimationMode(s.animation),
+		Frames:   s.frames,
+		Delay:    int(s.delay.Milliseconds()),
+		Loop:     s.loop,
+		Payloads: s.payloads,
+	}
+	for _, col := range s.cycleColors {
+		if col == nil {
+			return nil, ErrInvalidColor
+		}
+		cfg.Animation.Colors = append(cfg.Animation.Colors, toRGBA(col))
+	}
+	return cfg, nil
+}
+
+// toRGBA converts any color.Color to color.RGBA.
+func toRGBA(c color.Color) color.RGBA {
+	return color.RGBAModel.Convert(c).(color.RGBA)
+}
+
+// ctxWriter stops writing once the context is cancelled.
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw *ctxWriter) Write(p []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cw.w.Write(p)
+}
@@ -0,0 +1,213 @@
+package qrgen
+
+import "github.com/DalyChouikh/internal/templates"
+
+// Structured content types. Fill one in and pass the result of the matching
+// Encode function as the content to Render, Encode or WriteFile.
+type (
+	WiFiData       = templates.WiFiData
+	WiFiEncryption = templates.WiFiEncryption
+	VCardData      = templates.VCardData
+	VCardVersion   = templates.VCardVersion
+	VCardPhone     = templates.VCardPhone
+	VCardEmail     = templates.VCardEmail
+	VCardAddress   = templates.VCardAddress
+	EmailData      = templates.EmailData
+	SMSData        = templates.SMSData
+	EventData      = templates.EventData
+	GeoData        = templates.GeoData
+	GeoProvider    = templates.GeoProvider
+	SEPAData       = templates.SEPAData
+	SwissQRData    = templates.SwissQRData
+	SwissAddress   = templates.SwissAddress
+	SwissReference = templates.SwissReferenceType
+	MerchantData   = templates.MerchantData
+	PaymentScheme  = templates.PaymentScheme
+	EMVField       = templates.EMVField
+	EMVFields      = templates.EMVFields
+	CryptoData     = templates.CryptoData
+	CryptoNetwork  = templates.CryptoNetwork
+	OTPData        = templates.OTPData
+	OTPType        = templates.OTPType
+)
+
+// WiFi encryption types.
+const (
+	WiFiWPA  = templates.WiFiWPA
+	WiFiSAE  = templates.WiFiSAE
+	WiFiEAP  = templates.WiFiEAP
+	WiFiWEP  = templates.WiFiWEP
+	WiFiNone = templates.WiFiNone
+)
+
+// vCard versions.
+const (
+	VCard30 = templates.VCard30
+	VCard40 = templates.VCard40
+)
+
+// Location encodings.
+const (
+	GeoURI    = templates.GeoURI
+	GeoGoogle = templates.GeoGoogle
+	GeoApple  = templates.GeoApple
+	GeoOSM    = templates.GeoOSM
+)
+
+// Swiss QR-bill reference types.
+const (
+	SwissRefQRR  = templates.SwissRefQRR
+	SwissRefSCOR = templates.SwissRefSCOR
+	SwissRefNone = templates.SwissRefNone
+)
+
+// Merchant payment schemes.
+const (
+	SchemePIX    = templates.SchemePIX
+	SchemeUPI    = templates.SchemeUPI
+	SchemePayNow = templates.SchemePayNow
+)
+
+// Crypto payment networks.
+const (
+	CryptoBitcoin   = templates.CryptoBitcoin
+	CryptoEthereum  = templates.CryptoEthereum
+	CryptoLightning = templates.CryptoLightning
+)
+
+// Authenticator (one-time password) types.
+const (
+	OTPTime    = templates.OTPTime
+	OTPCounter = templates.OTPCounter
+)
+
+// EncodeWiFi validates d and returns its WIFI: payload.
+func EncodeWiFi(d WiFiData) (string, error) {
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	return d.Encode(), nil
+}
+
+// EncodeVCard validates d and returns its vCard payload.
+func EncodeVCard(d VCardData) (string, error) {
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	return d.Encode(), nil
+}
+
+// EncodeMeCard validates d and returns its compact MECARD: payload. Title
+// and photo are left out, as MeCard has no such properties.
+func EncodeMeCard(d VCardData) (string, error) {
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	return d.EncodeMeCard(), nil
+}
+
+// EncodeEmail returns the mailto: URI for d.
+func EncodeEmail(d EmailData) string { return d.Encode() }
+
+// EncodeSMS returns the smsto: URI for d.
+func EncodeSMS(d SMSData) string { return d.Encode() }
+
+// EncodeEvent returns the iCalendar VCALENDAR payload for d.
+func EncodeEvent(d EventData) string { return d.Encode() }
+
+// ParseEvent reads the first VEVENT of an iCalendar payload, such as one
+// produced by EncodeEvent.
+func ParseEvent(s string) (EventData, error) { return templates.ParseEvent(s) }
+
+// EncodeGeo validates d and returns its geo: URI or map link.
+func EncodeGeo(d GeoData) (string, error) {
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	return d.Encode(), nil
+}
+
+// ParseCoordinate parses a latitude (lat true) or longitude given in decimal
+// degrees or degrees, minutes and seconds, e.g. "48°51'29.6\"N".
+func ParseCoordinate(s string, lat bool) (float64, error) { return templates.ParseCoordinate(s, lat) }
+
+// EncodeSEPA validates d and returns its EPC069-12 payment payload. EPC
+// QR codes must use error correction level M (see WithErrorCorrection).
+func EncodeSEPA(d SEPAData) (string, error) {
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	return d.Encode(), nil
+}
+
+// ValidateIBAN checks the country, length and check digits of a SEPA IBAN.
+func ValidateIBAN(iban string) error { return templates.ValidateIBAN(iban) }
+
+// ValidateCreditorReference checks an ISO 11649 RF creditor reference.
+func ValidateCreditorReference(ref string) error { return templates.ValidateCreditorReference(ref) }
+
+// EncodeSwissQR validates d and returns its Swiss QR-bill payload. Render
+// it with WithSwissCross and the default error correction level (M).
+func EncodeSwissQR(d SwissQRData) (string, error) {
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	return d.Encode(), nil
+}
+
+// ValidateQRReference checks a 27-digit Swiss QR reference.
+func ValidateQRReference(ref string) error { return templates.ValidateQRReference(ref) }
+
+// EncodeMerchant validates d and returns its payment payload: an EMVCo
+// string for PIX and PayNow, or a upi://pay URI for UPI.
+func EncodeMerchant(d MerchantData) (string, error) {
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	return d.Encode(), nil
+}
+
+// BuildEMV encodes data objects as an EMVCo merchant-presented payload,
+// adding the payload format indicator and the CRC.
+func BuildEMV(fields EMVFields) (string, error) { return templates.BuildEMV(fields) }
+
+// ParseEMV reads an EMVCo payload into its data objects and checks its CRC.
+func ParseEMV(s string) (EMVFields, error) { return templates.ParseEMV(s) }
+
+// CRC16CCITT computes the CRC-16/CCITT-FALSE checksum used by EMVCo payloads.
+func CRC16CCITT(data []byte) uint16 { return templates.CRC16CCITT(data) }
+
+// NormalizePIXKey validates a PIX key (CPF, CNPJ, phone, email or random
+// key) and returns it in its canonical form.
+func NormalizePIXKey(key string) (string, error) { return templates.NormalizePIXKey(key) }
+
+// EncodeCrypto validates d and returns its payment URI: BIP21 bitcoin:,
+// EIP-681 ethereum: or an upper-case LIGHTNING: invoice.
+func EncodeCrypto(d CryptoData) (string, error) {
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	return d.Encode(), nil
+}
+
+// ValidateBitcoinAddress checks the checksum of a base58check or
+// bech32/bech32m Bitcoin address.
+func ValidateBitcoinAddress(address string) error { return templates.ValidateBitcoinAddress(address) }
+
+// EthereumChecksumAddress validates an Ethereum address and returns its
+// EIP-55 checksummed form.
+func EthereumChecksumAddress(address string) (string, error) {
+	return templates.EthereumChecksumAddress(address)
+}
+
+// EncodeOTP validates d and returns its otpauth:// Key URI.
+func EncodeOTP(d OTPData) (string, error) {
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	return d.Encode(), nil
+}
+
+// GenerateOTPSecret returns a random 160-bit authenticator secret in
+// base32.
+func GenerateOTPSecret() (string, error) { return templates.GenerateOTPSecret() }